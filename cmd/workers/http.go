@@ -4,12 +4,14 @@ import (
 	"context"
 
 	"TwClone/internal/config"
+	"TwClone/internal/provider"
 	"TwClone/internal/server"
 )
 
 func runHttpWorker(cfg *config.Config, ctx context.Context) {
 	srv := server.NewHttpServer(cfg)
 	go srv.Start()
+	go provider.RunJobs(ctx)
 
 	<-ctx.Done()
 	srv.Shutdown()
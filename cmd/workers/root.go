@@ -30,6 +30,33 @@ func Start() {
 				runHttpWorker(cfg, ctx)
 			},
 		},
+		{
+			Use:   "media-gc",
+			Short: "Run one media garbage collection pass",
+			Run: func(cmd *cobra.Command, _ []string) {
+				if err := provider.RunMediaGC(ctx); err != nil {
+					log.Fatal(err)
+				}
+			},
+		},
+		{
+			Use:   "reconcile-counts",
+			Short: "Recompute follower, following and tweet counts to repair drift",
+			Run: func(cmd *cobra.Command, _ []string) {
+				fixed, err := provider.RunReconcileCounts(ctx)
+				if err != nil {
+					log.Fatal(err)
+				}
+				logger.Log.Infof("repaired the counts of %d users", fixed)
+			},
+		},
+		{
+			Use:   "scheduler",
+			Short: "Publish scheduled tweets as they come due",
+			Run: func(cmd *cobra.Command, _ []string) {
+				provider.RunScheduler(ctx)
+			},
+		},
 	}
 
 	rootCmd.AddCommand(cmd...)
@@ -9,11 +9,22 @@ import (
 )
 
 type Config struct {
-	App        *AppConfig
-	HttpServer *HttpServerConfig
-	Database   *DatabaseConfig
-	Jwt        *JwtConfig
-	Logger     *LoggerConfig
+	App         *AppConfig
+	HttpServer  *HttpServerConfig
+	Database    *DatabaseConfig
+	Jwt         *JwtConfig
+	Logger      *LoggerConfig
+	Storage     *StorageConfig
+	Media       *MediaConfig
+	Tweet       *TweetConfig
+	Draft       *DraftConfig
+	Poll        *PollConfig
+	Trends      *TrendsConfig
+	SavedSearch *SavedSearchConfig
+	Profile     *ProfileConfig
+	Suggestions *SuggestionsConfig
+	Timeline    *TimelineConfig
+	Moderation  *ModerationConfig
 }
 
 func InitConfig() *Config {
@@ -27,11 +38,22 @@ func InitConfig() *Config {
 	}
 
 	return &Config{
-		App:        initAppConfig(),
-		Database:   initDbConfig(),
-		HttpServer: initHttpServerConfig(),
-		Jwt:        initJwtConfig(),
-		Logger:     initLoggerConfig(),
+		App:         initAppConfig(),
+		Database:    initDbConfig(),
+		HttpServer:  initHttpServerConfig(),
+		Jwt:         initJwtConfig(),
+		Logger:      initLoggerConfig(),
+		Storage:     initStorageConfig(),
+		Media:       initMediaConfig(),
+		Tweet:       initTweetConfig(),
+		Draft:       initDraftConfig(),
+		Poll:        initPollConfig(),
+		Trends:      initTrendsConfig(),
+		SavedSearch: initSavedSearchConfig(),
+		Profile:     initProfileConfig(),
+		Suggestions: initSuggestionsConfig(),
+		Timeline:    initTimelineConfig(),
+		Moderation:  initModerationConfig(),
 	}
 }
 
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"log"
+
+	"github.com/spf13/viper"
+)
+
+type DraftConfig struct {
+	MaxPerUser int `mapstructure:"DRAFT_MAX_PER_USER"`
+	// MaxScheduleDays is how far ahead a tweet can be scheduled.
+	MaxScheduleDays int `mapstructure:"DRAFT_MAX_SCHEDULE_DAYS"`
+	// PublishIntervalSeconds is how often the scheduler looks for due
+	// tweets.
+	PublishIntervalSeconds int `mapstructure:"DRAFT_PUBLISH_INTERVAL_SECONDS"`
+}
+
+func initDraftConfig() *DraftConfig {
+	draftConfig := &DraftConfig{}
+
+	if err := viper.Unmarshal(&draftConfig); err != nil {
+		log.Fatalf("error mapping draft config: %v", err)
+	}
+	if draftConfig.MaxPerUser <= 0 {
+		draftConfig.MaxPerUser = 100
+	}
+	if draftConfig.MaxScheduleDays <= 0 {
+		draftConfig.MaxScheduleDays = 540
+	}
+	if draftConfig.PublishIntervalSeconds <= 0 {
+		draftConfig.PublishIntervalSeconds = 30
+	}
+
+	return draftConfig
+}
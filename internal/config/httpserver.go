@@ -11,6 +11,11 @@ type HttpServerConfig struct {
 	Port                 int    `mapstructure:"HTTP_SERVER_PORT"`
 	GracePeriod          int    `mapstructure:"HTTP_SERVER_GRACE_PERIOD"`
 	RequestTimeoutPeriod int    `mapstructure:"HTTP_SERVER_REQUEST_TIMEOUT_PERIOD"`
+
+	// DebugAddr is the address of the internal listener serving runtime and
+	// job counters (/debug/vars). It is meant to be reachable by operators
+	// only; leave it empty to turn the listener off.
+	DebugAddr string `mapstructure:"HTTP_SERVER_DEBUG_ADDR"`
 }
 
 func initHttpServerConfig() *HttpServerConfig {
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"log"
+
+	"github.com/spf13/viper"
+)
+
+type MediaConfig struct {
+	MaxImageSizeMB int64  `mapstructure:"MEDIA_MAX_IMAGE_SIZE_MB"`
+	MaxGifSizeMB   int64  `mapstructure:"MEDIA_MAX_GIF_SIZE_MB"`
+	MaxVideoSizeMB int64  `mapstructure:"MEDIA_MAX_VIDEO_SIZE_MB"`
+	UserQuotaMB    int64  `mapstructure:"MEDIA_USER_QUOTA_MB"`
+	Workers        int    `mapstructure:"MEDIA_PROCESSING_WORKERS"`
+	URLSigningKey  string `mapstructure:"MEDIA_URL_SIGNING_KEY"`
+	URLTTLMinutes  int    `mapstructure:"MEDIA_URL_TTL_MINUTES"`
+	GCGraceHours   int    `mapstructure:"MEDIA_GC_GRACE_HOURS"`
+	GCIntervalMin  int    `mapstructure:"MEDIA_GC_INTERVAL_MINUTES"`
+
+	// MaxImageMegapixels caps width×height of uploaded images, so that a
+	// small file cannot decode into an image too large to hold in memory.
+	MaxImageMegapixels int `mapstructure:"MEDIA_MAX_IMAGE_MEGAPIXELS"`
+}
+
+func initMediaConfig() *MediaConfig {
+	mediaConfig := &MediaConfig{}
+
+	if err := viper.Unmarshal(&mediaConfig); err != nil {
+		log.Fatalf("error mapping media config: %v", err)
+	}
+	if mediaConfig.MaxImageSizeMB <= 0 {
+		mediaConfig.MaxImageSizeMB = 5
+	}
+	if mediaConfig.MaxGifSizeMB <= 0 {
+		mediaConfig.MaxGifSizeMB = 15
+	}
+	if mediaConfig.MaxVideoSizeMB <= 0 {
+		mediaConfig.MaxVideoSizeMB = 512
+	}
+	if mediaConfig.MaxImageMegapixels <= 0 {
+		mediaConfig.MaxImageMegapixels = 40
+	}
+	if mediaConfig.UserQuotaMB <= 0 {
+		mediaConfig.UserQuotaMB = 1024
+	}
+	if mediaConfig.Workers <= 0 {
+		mediaConfig.Workers = 2
+	}
+	if mediaConfig.URLTTLMinutes <= 0 {
+		mediaConfig.URLTTLMinutes = 60
+	}
+	if mediaConfig.GCGraceHours <= 0 {
+		mediaConfig.GCGraceHours = 24
+	}
+	if mediaConfig.GCIntervalMin <= 0 {
+		mediaConfig.GCIntervalMin = 60
+	}
+
+	return mediaConfig
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"log"
+
+	"github.com/spf13/viper"
+)
+
+type ModerationConfig struct {
+	// ModeratorIDs are the users who may review and resolve reports, as a
+	// comma-separated list of user ids.
+	ModeratorIDs []int64 `mapstructure:"MODERATION_MODERATOR_IDS"`
+}
+
+func initModerationConfig() *ModerationConfig {
+	moderationConfig := &ModerationConfig{}
+
+	if err := viper.Unmarshal(&moderationConfig); err != nil {
+		log.Fatalf("error mapping moderation config: %v", err)
+	}
+
+	return moderationConfig
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"log"
+
+	"github.com/spf13/viper"
+)
+
+type PollConfig struct {
+	MinDurationMinutes int `mapstructure:"POLL_MIN_DURATION_MINUTES"`
+	MaxDurationMinutes int `mapstructure:"POLL_MAX_DURATION_MINUTES"`
+	// CloseIntervalSeconds is how often ended polls are looked for to
+	// notify their author and voters.
+	CloseIntervalSeconds int `mapstructure:"POLL_CLOSE_INTERVAL_SECONDS"`
+}
+
+func initPollConfig() *PollConfig {
+	pollConfig := &PollConfig{}
+
+	if err := viper.Unmarshal(&pollConfig); err != nil {
+		log.Fatalf("error mapping poll config: %v", err)
+	}
+	if pollConfig.MinDurationMinutes <= 0 {
+		pollConfig.MinDurationMinutes = 5
+	}
+	if pollConfig.MaxDurationMinutes <= 0 {
+		pollConfig.MaxDurationMinutes = 7 * 24 * 60
+	}
+	if pollConfig.CloseIntervalSeconds <= 0 {
+		pollConfig.CloseIntervalSeconds = 60
+	}
+
+	return pollConfig
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"log"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+type ProfileConfig struct {
+	// BaseURL is where profiles are served; a profile is at BaseURL/username.
+	// Pages listed as profile links must link back there with rel="me".
+	BaseURL string `mapstructure:"PROFILE_BASE_URL"`
+	// VerifyIntervalMinutes is how often new profile links are looked for
+	// to verify.
+	VerifyIntervalMinutes int `mapstructure:"PROFILE_LINK_VERIFY_INTERVAL_MINUTES"`
+	// RecheckHours is how long a link stays verified, or unverified, before
+	// its page is fetched again.
+	RecheckHours        int `mapstructure:"PROFILE_LINK_RECHECK_HOURS"`
+	FetchTimeoutSeconds int `mapstructure:"PROFILE_LINK_FETCH_TIMEOUT_SECONDS"`
+}
+
+func initProfileConfig() *ProfileConfig {
+	profileConfig := &ProfileConfig{}
+
+	if err := viper.Unmarshal(&profileConfig); err != nil {
+		log.Fatalf("error mapping profile config: %v", err)
+	}
+	if profileConfig.BaseURL == "" {
+		profileConfig.BaseURL = "http://localhost:8000"
+	}
+	profileConfig.BaseURL = strings.TrimRight(profileConfig.BaseURL, "/")
+	if profileConfig.VerifyIntervalMinutes <= 0 {
+		profileConfig.VerifyIntervalMinutes = 5
+	}
+	if profileConfig.RecheckHours <= 0 {
+		profileConfig.RecheckHours = 24
+	}
+	if profileConfig.FetchTimeoutSeconds <= 0 {
+		profileConfig.FetchTimeoutSeconds = 10
+	}
+
+	return profileConfig
+}
@@ -0,0 +1,34 @@
+package config
+
+import (
+	"log"
+
+	"github.com/spf13/viper"
+)
+
+type SavedSearchConfig struct {
+	MaxPerUser      int `mapstructure:"SAVED_SEARCH_MAX_PER_USER"`
+	IntervalMinutes int `mapstructure:"SAVED_SEARCH_INTERVAL_MINUTES"`
+	// CooldownMinutes is the least time between two alerts of one search.
+	// Matches found in between are gathered into the next alert.
+	CooldownMinutes int `mapstructure:"SAVED_SEARCH_ALERT_COOLDOWN_MINUTES"`
+}
+
+func initSavedSearchConfig() *SavedSearchConfig {
+	savedSearchConfig := &SavedSearchConfig{}
+
+	if err := viper.Unmarshal(&savedSearchConfig); err != nil {
+		log.Fatalf("error mapping saved search config: %v", err)
+	}
+	if savedSearchConfig.MaxPerUser <= 0 {
+		savedSearchConfig.MaxPerUser = 25
+	}
+	if savedSearchConfig.IntervalMinutes <= 0 {
+		savedSearchConfig.IntervalMinutes = 5
+	}
+	if savedSearchConfig.CooldownMinutes <= 0 {
+		savedSearchConfig.CooldownMinutes = 60
+	}
+
+	return savedSearchConfig
+}
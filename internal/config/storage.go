@@ -0,0 +1,35 @@
+package config
+
+import (
+	"log"
+
+	"github.com/spf13/viper"
+)
+
+type StorageConfig struct {
+	Driver         string `mapstructure:"STORAGE_DRIVER"`
+	LocalPath      string `mapstructure:"STORAGE_LOCAL_PATH"`
+	PublicBaseURL  string `mapstructure:"STORAGE_PUBLIC_BASE_URL"`
+	S3Endpoint     string `mapstructure:"STORAGE_S3_ENDPOINT"`
+	S3Region       string `mapstructure:"STORAGE_S3_REGION"`
+	S3Bucket       string `mapstructure:"STORAGE_S3_BUCKET"`
+	S3AccessKey    string `mapstructure:"STORAGE_S3_ACCESS_KEY"`
+	S3SecretKey    string `mapstructure:"STORAGE_S3_SECRET_KEY"`
+	S3UsePathStyle bool   `mapstructure:"STORAGE_S3_USE_PATH_STYLE"`
+}
+
+func initStorageConfig() *StorageConfig {
+	storageConfig := &StorageConfig{}
+
+	if err := viper.Unmarshal(&storageConfig); err != nil {
+		log.Fatalf("error mapping storage config: %v", err)
+	}
+	if storageConfig.Driver == "" {
+		storageConfig.Driver = "local"
+	}
+	if storageConfig.LocalPath == "" {
+		storageConfig.LocalPath = "uploads"
+	}
+
+	return storageConfig
+}
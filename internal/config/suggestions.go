@@ -0,0 +1,42 @@
+package config
+
+import (
+	"log"
+
+	"github.com/spf13/viper"
+)
+
+type SuggestionsConfig struct {
+	// IntervalMinutes is how often the suggestions of every user are
+	// recomputed.
+	IntervalMinutes int `mapstructure:"SUGGESTIONS_INTERVAL_MINUTES"`
+	PerUser         int `mapstructure:"SUGGESTIONS_PER_USER"`
+	// InterestDays is how far back the hashtags users tweeted count as
+	// their interests.
+	InterestDays int `mapstructure:"SUGGESTIONS_INTEREST_DAYS"`
+	// ActivityDays is how long after their last tweet accounts stop getting
+	// a boost for being active.
+	ActivityDays int `mapstructure:"SUGGESTIONS_ACTIVITY_DAYS"`
+}
+
+func initSuggestionsConfig() *SuggestionsConfig {
+	suggestionsConfig := &SuggestionsConfig{}
+
+	if err := viper.Unmarshal(&suggestionsConfig); err != nil {
+		log.Fatalf("error mapping suggestions config: %v", err)
+	}
+	if suggestionsConfig.IntervalMinutes <= 0 {
+		suggestionsConfig.IntervalMinutes = 60
+	}
+	if suggestionsConfig.PerUser <= 0 {
+		suggestionsConfig.PerUser = 30
+	}
+	if suggestionsConfig.InterestDays <= 0 {
+		suggestionsConfig.InterestDays = 30
+	}
+	if suggestionsConfig.ActivityDays <= 0 {
+		suggestionsConfig.ActivityDays = 14
+	}
+
+	return suggestionsConfig
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"log"
+
+	"github.com/spf13/viper"
+)
+
+type TimelineConfig struct {
+	// CandidateHours is how old tweets can be to make it into the ranked
+	// timeline.
+	CandidateHours int `mapstructure:"TIMELINE_CANDIDATE_HOURS"`
+	// Candidates is how many of the newest candidate tweets are scored.
+	Candidates int `mapstructure:"TIMELINE_CANDIDATES"`
+	// HalfLifeMinutes is how long it takes a tweet's score to halve with
+	// age.
+	HalfLifeMinutes int `mapstructure:"TIMELINE_HALF_LIFE_MINUTES"`
+	// AffinityDays is how far back interactions with an author and "not
+	// interested" feedback on their tweets count.
+	AffinityDays int `mapstructure:"TIMELINE_AFFINITY_DAYS"`
+}
+
+func initTimelineConfig() *TimelineConfig {
+	timelineConfig := &TimelineConfig{}
+
+	if err := viper.Unmarshal(&timelineConfig); err != nil {
+		log.Fatalf("error mapping timeline config: %v", err)
+	}
+	if timelineConfig.CandidateHours <= 0 {
+		timelineConfig.CandidateHours = 48
+	}
+	if timelineConfig.Candidates <= 0 {
+		timelineConfig.Candidates = 500
+	}
+	if timelineConfig.HalfLifeMinutes <= 0 {
+		timelineConfig.HalfLifeMinutes = 360
+	}
+	if timelineConfig.AffinityDays <= 0 {
+		timelineConfig.AffinityDays = 30
+	}
+
+	return timelineConfig
+}
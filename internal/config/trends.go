@@ -0,0 +1,63 @@
+package config
+
+import (
+	"log"
+	"strings"
+
+	"TwClone/internal/pkg/utils/textutils"
+
+	"github.com/spf13/viper"
+)
+
+type TrendsConfig struct {
+	IntervalMinutes int    `mapstructure:"TRENDS_INTERVAL_MINUTES"`
+	WindowHours     int    `mapstructure:"TRENDS_WINDOW_HOURS"`
+	BaselineDays    int    `mapstructure:"TRENDS_BASELINE_DAYS"`
+	HalfLifeMinutes int    `mapstructure:"TRENDS_HALF_LIFE_MINUTES"`
+	MinAuthors      int    `mapstructure:"TRENDS_MIN_AUTHORS"`
+	Limit           int    `mapstructure:"TRENDS_LIMIT"`
+	SampleTweets    int    `mapstructure:"TRENDS_SAMPLE_TWEETS"`
+	RawBlocklist    string `mapstructure:"TRENDS_BLOCKLIST"`
+
+	// Blocklist holds the folded hashtags of RawBlocklist, a comma-separated
+	// list, which never trend.
+	Blocklist map[string]struct{} `mapstructure:"-"`
+}
+
+func initTrendsConfig() *TrendsConfig {
+	trendsConfig := &TrendsConfig{}
+
+	if err := viper.Unmarshal(&trendsConfig); err != nil {
+		log.Fatalf("error mapping trends config: %v", err)
+	}
+	if trendsConfig.IntervalMinutes <= 0 {
+		trendsConfig.IntervalMinutes = 5
+	}
+	if trendsConfig.WindowHours <= 0 {
+		trendsConfig.WindowHours = 6
+	}
+	if trendsConfig.BaselineDays <= 0 {
+		trendsConfig.BaselineDays = 7
+	}
+	if trendsConfig.HalfLifeMinutes <= 0 {
+		trendsConfig.HalfLifeMinutes = 60
+	}
+	if trendsConfig.MinAuthors <= 0 {
+		trendsConfig.MinAuthors = 3
+	}
+	if trendsConfig.Limit <= 0 {
+		trendsConfig.Limit = 10
+	}
+	if trendsConfig.SampleTweets <= 0 {
+		trendsConfig.SampleTweets = 3
+	}
+
+	trendsConfig.Blocklist = make(map[string]struct{})
+	for _, tag := range strings.Split(trendsConfig.RawBlocklist, ",") {
+		if tag = textutils.FoldHashtag(strings.TrimSpace(tag)); tag != "" {
+			trendsConfig.Blocklist[tag] = struct{}{}
+		}
+	}
+
+	return trendsConfig
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"log"
+
+	"github.com/spf13/viper"
+)
+
+// TweetConfig limits tweet editing: a tweet can be edited MaxEdits times
+// within EditWindowMinutes of being posted.
+type TweetConfig struct {
+	EditWindowMinutes int `mapstructure:"TWEET_EDIT_WINDOW_MINUTES"`
+	MaxEdits          int `mapstructure:"TWEET_MAX_EDITS"`
+}
+
+func initTweetConfig() *TweetConfig {
+	tweetConfig := &TweetConfig{}
+
+	if err := viper.Unmarshal(&tweetConfig); err != nil {
+		log.Fatalf("error mapping tweet config: %v", err)
+	}
+	if tweetConfig.EditWindowMinutes <= 0 {
+		tweetConfig.EditWindowMinutes = 60
+	}
+	if tweetConfig.MaxEdits <= 0 {
+		tweetConfig.MaxEdits = 5
+	}
+
+	return tweetConfig
+}
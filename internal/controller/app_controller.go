@@ -3,6 +3,7 @@ package controller
 import (
 	"net/http"
 
+	"TwClone/internal/constant"
 	"TwClone/internal/dto"
 
 	"github.com/go-playground/validator/v10"
@@ -35,6 +36,18 @@ func extractFieldErrors(err error, structPrefix string) []dto.FieldError {
 	return fieldErrors
 }
 
+// currentUserID returns the authenticated user id set by the auth middleware.
+func currentUserID(ctx echo.Context) (int64, bool) {
+	switch v := ctx.Get(constant.CTX_USER_ID).(type) {
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	default:
+		return 0, false
+	}
+}
+
 func NewAppController() *AppController {
 	return &AppController{}
 }
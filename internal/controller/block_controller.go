@@ -0,0 +1,205 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"TwClone/internal/dto"
+	"TwClone/internal/entity"
+	"TwClone/internal/middleware"
+	"TwClone/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// BlockController manages the users the current user blocks or mutes.
+type BlockController struct {
+	blockRepo repository.BlockRepositoryImpl
+	muteRepo  repository.MuteRepositoryImpl
+	userRepo  repository.UserRepositoryImpl
+}
+
+func NewBlockController() *BlockController {
+	return &BlockController{
+		blockRepo: repository.BlockRepositoryImpl{},
+		muteRepo:  repository.MuteRepositoryImpl{},
+		userRepo:  repository.UserRepositoryImpl{},
+	}
+}
+
+func (c *BlockController) Route(g *echo.Group) {
+	bg := g.Group("/blocks", middleware.AuthMiddleware())
+	bg.GET("", c.Blocks)
+	bg.POST("/:user_id", c.Block)
+	bg.DELETE("/:user_id", c.Unblock)
+
+	mg := g.Group("/mutes", middleware.AuthMiddleware())
+	mg.GET("", c.Mutes)
+	mg.POST("/:user_id", c.Mute)
+	mg.DELETE("/:user_id", c.Unmute)
+}
+
+// ListBlocks godoc
+// @Summary List blocked users
+// @Description List the users you block, most recent first
+// @Tags blocks
+// @Produce json
+// @Success 200 {object} dto.WebResponse
+// @Router /api/v1/blocks [get]
+func (c *BlockController) Blocks(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	blocks, err := c.blockRepo.FindByBlocker(ctx.Request().Context(), userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch blocks"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: blocks})
+}
+
+// BlockUser godoc
+// @Summary Block user
+// @Description Block a user. You stop following each other, leave and unsubscribe from each other's lists, and neither sees the other's tweets in search.
+// @Tags blocks
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/blocks/{user_id} [post]
+func (c *BlockController) Block(ctx echo.Context) error {
+	userID, targetID, ok := c.target(ctx)
+	if !ok {
+		return nil
+	}
+
+	if err := c.blockRepo.Create(ctx.Request().Context(), &entity.Block{BlockerID: userID, BlockedID: targetID}); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to block user"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "user blocked"})
+}
+
+// UnblockUser godoc
+// @Summary Unblock user
+// @Description Unblock a user
+// @Tags blocks
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/blocks/{user_id} [delete]
+func (c *BlockController) Unblock(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	targetID, err := strconv.ParseInt(ctx.Param("user_id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid user id"})
+	}
+
+	if err := c.blockRepo.Delete(ctx.Request().Context(), userID, targetID); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to unblock user"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "user unblocked"})
+}
+
+// ListMutes godoc
+// @Summary List muted users
+// @Description List the users you mute, most recent first
+// @Tags blocks
+// @Produce json
+// @Success 200 {object} dto.WebResponse
+// @Router /api/v1/mutes [get]
+func (c *BlockController) Mutes(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	mutes, err := c.muteRepo.FindByMuter(ctx.Request().Context(), userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch mutes"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: mutes})
+}
+
+// MuteUser godoc
+// @Summary Mute user
+// @Description Mute a user. Their tweets are hidden from your search results; they are not told.
+// @Tags blocks
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/mutes/{user_id} [post]
+func (c *BlockController) Mute(ctx echo.Context) error {
+	userID, targetID, ok := c.target(ctx)
+	if !ok {
+		return nil
+	}
+
+	if err := c.muteRepo.Create(ctx.Request().Context(), &entity.Mute{MuterID: userID, MutedID: targetID}); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to mute user"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "user muted"})
+}
+
+// UnmuteUser godoc
+// @Summary Unmute user
+// @Description Unmute a user
+// @Tags blocks
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/mutes/{user_id} [delete]
+func (c *BlockController) Unmute(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	targetID, err := strconv.ParseInt(ctx.Param("user_id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid user id"})
+	}
+
+	if err := c.muteRepo.Delete(ctx.Request().Context(), userID, targetID); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to unmute user"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "user unmuted"})
+}
+
+// target resolves the current user and the existing user from the :user_id
+// path param, who must be someone else. When ok is false the error response
+// has already been written.
+func (c *BlockController) target(ctx echo.Context) (userID, targetID int64, ok bool) {
+	userID, ok = currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+		return 0, 0, false
+	}
+	targetID, err := strconv.ParseInt(ctx.Param("user_id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid user id"})
+		return 0, 0, false
+	}
+	if targetID == userID {
+		ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "you cannot do this to yourself"})
+		return 0, 0, false
+	}
+
+	if _, err := c.userRepo.FindByID(ctx.Request().Context(), targetID); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "user not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch user"})
+		}
+		return 0, 0, false
+	}
+	return userID, targetID, true
+}
@@ -0,0 +1,402 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"TwClone/internal/dto"
+	"TwClone/internal/entity"
+	"TwClone/internal/middleware"
+	"TwClone/internal/pkg/utils/pageutils"
+	"TwClone/internal/pkg/utils/signutils"
+	"TwClone/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+const maxBookmarkFolders = 100
+
+// BookmarkController manages the current user's bookmarks and bookmark
+// folders. Bookmarks are private: nobody else can list them.
+type BookmarkController struct {
+	presenter  *tweetPresenter
+	repo       repository.BookmarkRepositoryImpl
+	folderRepo repository.BookmarkFolderRepositoryImpl
+	tweetRepo  repository.TweetRepositoryImpl
+}
+
+func NewBookmarkController(signer signutils.MediaURLSigner) *BookmarkController {
+	return &BookmarkController{
+		presenter:  newTweetPresenter(signer),
+		repo:       repository.BookmarkRepositoryImpl{},
+		folderRepo: repository.BookmarkFolderRepositoryImpl{},
+		tweetRepo:  repository.TweetRepositoryImpl{},
+	}
+}
+
+func (c *BookmarkController) Route(g *echo.Group) {
+	bg := g.Group("/bookmarks", middleware.AuthMiddleware())
+	bg.GET("", c.FindAll)
+	bg.GET("/folders", c.Folders)
+	bg.POST("/folders", c.CreateFolder)
+	bg.PATCH("/folders/:id", c.RenameFolder)
+	bg.DELETE("/folders/:id", c.DeleteFolder)
+	bg.POST("/:tweet_id", c.Create)
+	bg.PATCH("/:tweet_id", c.Move)
+	bg.DELETE("/:tweet_id", c.Delete)
+}
+
+type bookmarkReq struct {
+	FolderID *int64 `json:"folder_id" validate:"omitempty,gt=0"`
+}
+
+type bookmarkFolderReq struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
+
+// ListBookmarks godoc
+// @Summary List bookmarks
+// @Description List the tweets you bookmarked, most recently bookmarked first, optionally from one folder
+// @Tags bookmarks
+// @Produce json
+// @Param folder_id query int false "Folder ID"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/bookmarks [get]
+func (c *BookmarkController) FindAll(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	before, beforeTweetID, err := pageutils.DecodeTimeCursor(ctx.QueryParam("cursor"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid cursor"})
+	}
+	limit := pageutils.ParseLimit(ctx.QueryParam("limit"), defaultTweetsLimit, maxTweetsLimit)
+
+	reqCtx := ctx.Request().Context()
+	var folderID *int64
+	if raw := ctx.QueryParam("folder_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid folder id"})
+		}
+		if _, err := c.folderRepo.FindByID(reqCtx, id, userID); err != nil {
+			if errors.Is(err, repository.ErrRecordNotFound) {
+				return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "folder not found"})
+			}
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch folder"})
+		}
+		folderID = &id
+	}
+
+	page, err := c.repo.FindPage(reqCtx, userID, folderID, before, beforeTweetID, limit)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch bookmarks"})
+	}
+	tweets := make([]*entity.Tweet, 0, len(page))
+	for _, b := range page {
+		tweets = append(tweets, &b.Tweet)
+	}
+
+	resp, err := c.presenter.build(ctx, tweets)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet media"})
+	}
+
+	cursor := &dto.CursorMetaData{Limit: limit}
+	if len(page) == limit {
+		last := page[len(page)-1]
+		cursor.NextCursor = pageutils.EncodeTimeCursor(last.BookmarkedAt, last.ID)
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp, Cursor: cursor})
+}
+
+// BookmarkTweet godoc
+// @Summary Bookmark tweet
+// @Description Privately bookmark a tweet, optionally in one of your folders. Bookmarking a retweet bookmarks the original.
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param tweet_id path int true "Tweet ID"
+// @Param bookmark body bookmarkReq false "Bookmark payload"
+// @Success 201 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Failure 409 {object} dto.WebResponse
+// @Router /api/v1/bookmarks/{tweet_id} [post]
+func (c *BookmarkController) Create(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	tweetID, err := strconv.ParseInt(ctx.Param("tweet_id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid tweet id"})
+	}
+	folderID, ok := c.bindFolder(ctx, userID)
+	if !ok {
+		return nil
+	}
+
+	reqCtx := ctx.Request().Context()
+	tweet, err := c.tweetRepo.FindVisibleOriginal(reqCtx, tweetID, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "tweet not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet"})
+	}
+
+	bookmark := entity.Bookmark{UserID: userID, TweetID: tweet.ID, FolderID: folderID}
+	if err := c.repo.Create(reqCtx, &bookmark); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return ctx.JSON(http.StatusConflict, dto.WebResponse[any]{Message: "tweet already bookmarked"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to bookmark tweet"})
+	}
+	return ctx.JSON(http.StatusCreated, dto.WebResponse[any]{Data: bookmark})
+}
+
+// MoveBookmark godoc
+// @Summary Move bookmark
+// @Description File a bookmark in a folder, or take it out of its folder by leaving folder_id out
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param tweet_id path int true "Tweet ID"
+// @Param bookmark body bookmarkReq true "Bookmark payload"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/bookmarks/{tweet_id} [patch]
+func (c *BookmarkController) Move(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	tweetID, err := strconv.ParseInt(ctx.Param("tweet_id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid tweet id"})
+	}
+	folderID, ok := c.bindFolder(ctx, userID)
+	if !ok {
+		return nil
+	}
+
+	if err := c.repo.Move(ctx.Request().Context(), userID, tweetID, folderID); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "bookmark not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to move bookmark"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "bookmark moved"})
+}
+
+// DeleteBookmark godoc
+// @Summary Remove bookmark
+// @Description Remove a tweet from your bookmarks
+// @Tags bookmarks
+// @Produce json
+// @Param tweet_id path int true "Tweet ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/bookmarks/{tweet_id} [delete]
+func (c *BookmarkController) Delete(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	tweetID, err := strconv.ParseInt(ctx.Param("tweet_id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid tweet id"})
+	}
+
+	if err := c.repo.Delete(ctx.Request().Context(), userID, tweetID); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "bookmark not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to remove bookmark"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "bookmark removed"})
+}
+
+// ListBookmarkFolders godoc
+// @Summary List bookmark folders
+// @Description List your bookmark folders by name
+// @Tags bookmarks
+// @Produce json
+// @Success 200 {object} dto.WebResponse
+// @Router /api/v1/bookmarks/folders [get]
+func (c *BookmarkController) Folders(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	folders, err := c.folderRepo.FindByUser(ctx.Request().Context(), userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch folders"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: folders})
+}
+
+// CreateBookmarkFolder godoc
+// @Summary Create bookmark folder
+// @Description Create a bookmark folder; names are unique among your folders
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param folder body bookmarkFolderReq true "Folder payload"
+// @Success 201 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 409 {object} dto.WebResponse
+// @Router /api/v1/bookmarks/folders [post]
+func (c *BookmarkController) CreateFolder(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	name, ok := c.bindFolderName(ctx)
+	if !ok {
+		return nil
+	}
+
+	reqCtx := ctx.Request().Context()
+	count, err := c.folderRepo.CountByUser(reqCtx, userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to count folders"})
+	}
+	if count >= maxBookmarkFolders {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "folder limit reached"})
+	}
+
+	folder := entity.BookmarkFolder{UserID: userID, Name: name}
+	if err := c.folderRepo.Create(reqCtx, &folder); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return ctx.JSON(http.StatusConflict, dto.WebResponse[any]{Message: "a folder with this name already exists"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to create folder"})
+	}
+	return ctx.JSON(http.StatusCreated, dto.WebResponse[any]{Data: folder})
+}
+
+// RenameBookmarkFolder godoc
+// @Summary Rename bookmark folder
+// @Description Rename one of your bookmark folders
+// @Tags bookmarks
+// @Accept json
+// @Produce json
+// @Param id path int true "Folder ID"
+// @Param folder body bookmarkFolderReq true "Folder payload"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Failure 409 {object} dto.WebResponse
+// @Router /api/v1/bookmarks/folders/{id} [patch]
+func (c *BookmarkController) RenameFolder(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+	name, ok := c.bindFolderName(ctx)
+	if !ok {
+		return nil
+	}
+
+	reqCtx := ctx.Request().Context()
+	folder, err := c.folderRepo.FindByID(reqCtx, id, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "folder not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch folder"})
+	}
+
+	folder.Name = name
+	if err := c.folderRepo.Rename(reqCtx, folder); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return ctx.JSON(http.StatusConflict, dto.WebResponse[any]{Message: "a folder with this name already exists"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to rename folder"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: folder})
+}
+
+// DeleteBookmarkFolder godoc
+// @Summary Delete bookmark folder
+// @Description Delete one of your bookmark folders. Its bookmarks are kept, outside any folder.
+// @Tags bookmarks
+// @Produce json
+// @Param id path int true "Folder ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/bookmarks/folders/{id} [delete]
+func (c *BookmarkController) DeleteFolder(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	if err := c.folderRepo.Delete(ctx.Request().Context(), id, userID); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "folder not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to delete folder"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "folder deleted"})
+}
+
+// bindFolder reads the optional folder of a bookmark from the request body
+// and checks that it is one of the user's folders. When ok is false the
+// error response has already been written.
+func (c *BookmarkController) bindFolder(ctx echo.Context, userID int64) (*int64, bool) {
+	var req bookmarkReq
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "bookmarkReq")})
+		return nil, false
+	}
+	if err := ctx.Validate(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "bookmarkReq")})
+		return nil, false
+	}
+	if req.FolderID == nil {
+		return nil, true
+	}
+
+	if _, err := c.folderRepo.FindByID(ctx.Request().Context(), *req.FolderID, userID); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "folder not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch folder"})
+		}
+		return nil, false
+	}
+	return req.FolderID, true
+}
+
+// bindFolderName reads a folder name from the request body. When ok is
+// false the error response has already been written.
+func (c *BookmarkController) bindFolderName(ctx echo.Context) (string, bool) {
+	var req bookmarkFolderReq
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "bookmarkFolderReq")})
+		return "", false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if err := ctx.Validate(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "bookmarkFolderReq")})
+		return "", false
+	}
+	return req.Name, true
+}
@@ -0,0 +1,889 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"TwClone/internal/dto"
+	"TwClone/internal/entity"
+	"TwClone/internal/middleware"
+	"TwClone/internal/pkg/utils/pageutils"
+	"TwClone/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	maxGroupMembers      = 50
+	defaultMessagesLimit = 30
+	maxMessagesLimit     = 100
+)
+
+const (
+	inboxPrimary  = "primary"
+	inboxRequests = "requests"
+)
+
+var errInvalidEnvelope = errors.New("invalid message envelope")
+
+// ConversationController handles direct message conversations and their messages.
+type ConversationController struct {
+	repo       repository.ConversationRepositoryImpl
+	msgRepo    repository.MessageRepositoryImpl
+	userRepo   repository.UserRepositoryImpl
+	followRepo repository.FollowRepositoryImpl
+	blockRepo  repository.BlockRepositoryImpl
+	mediaRepo  repository.MediaRepositoryImpl
+	reportRepo repository.ReportRepositoryImpl
+	keyRepo    repository.DeviceKeyRepositoryImpl
+}
+
+func NewConversationController() *ConversationController {
+	return &ConversationController{
+		repo:       repository.ConversationRepositoryImpl{},
+		msgRepo:    repository.MessageRepositoryImpl{},
+		userRepo:   repository.UserRepositoryImpl{},
+		followRepo: repository.FollowRepositoryImpl{},
+		blockRepo:  repository.BlockRepositoryImpl{},
+		mediaRepo:  repository.MediaRepositoryImpl{},
+		reportRepo: repository.ReportRepositoryImpl{},
+		keyRepo:    repository.DeviceKeyRepositoryImpl{},
+	}
+}
+
+func (c *ConversationController) Route(g *echo.Group) {
+	cg := g.Group("/conversations", middleware.AuthMiddleware())
+	cg.POST("", c.Create)
+	cg.GET("", c.FindAll)
+	cg.GET("/unread", c.Unread)
+	cg.GET("/:id", c.FindByID)
+	cg.PATCH("/:id", c.Rename)
+	cg.POST("/:id/leave", c.Leave)
+	cg.POST("/:id/accept", c.Accept)
+	cg.POST("/:id/decline", c.Decline)
+	cg.POST("/:id/read", c.MarkRead)
+	cg.POST("/:id/report", c.Report)
+	cg.POST("/:id/messages", c.SendMessage)
+	cg.GET("/:id/messages", c.Messages)
+}
+
+type createConversationReq struct {
+	ParticipantIDs []int64 `json:"participant_ids" validate:"required,min=1"`
+	Name           string  `json:"name" validate:"max=100"`
+	Encrypted      bool    `json:"encrypted"`
+}
+
+type renameConversationReq struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type envelopeReq struct {
+	RecipientUserID   int64  `json:"recipient_user_id" validate:"required,gt=0"`
+	RecipientDeviceID string `json:"recipient_device_id" validate:"required,max=64"`
+	Type              string `json:"type" validate:"required,oneof=prekey message"`
+	Ciphertext        string `json:"ciphertext" validate:"required,base64,max=65536"`
+}
+
+// sendMessageReq carries either a plaintext message (content and media) or,
+// for encrypted conversations, one ciphertext envelope per recipient device.
+type sendMessageReq struct {
+	Content        string        `json:"content" validate:"max=10000"`
+	MediaIDs       []int64       `json:"media_ids" validate:"max=4"`
+	SenderDeviceID string        `json:"sender_device_id" validate:"max=64"`
+	Envelopes      []envelopeReq `json:"envelopes" validate:"max=500,dive"`
+}
+
+type markReadReq struct {
+	MessageID int64 `json:"message_id" validate:"required,gt=0"`
+}
+
+type reportConversationReq struct {
+	Reason  string `json:"reason" validate:"required,oneof=spam abuse harassment other"`
+	Comment string `json:"comment" validate:"max=1000"`
+}
+
+// CreateConversation godoc
+// @Summary Start conversation
+// @Description Start a one-to-one conversation (one participant) or a group conversation (several participants). An existing one-to-one conversation is returned instead of creating a duplicate. Participants who do not follow the creator receive it as a message request. Encrypted conversations require every member to have registered device keys.
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Param conversation body createConversationReq true "Conversation payload"
+// @Success 201 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Router /api/v1/conversations [post]
+func (c *ConversationController) Create(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	var req createConversationReq
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "createConversationReq")})
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "createConversationReq")})
+	}
+
+	participants := uniqueIDs(req.ParticipantIDs, userID)
+	if len(participants) == 0 {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "at least one other participant is required"})
+	}
+	if len(participants)+1 > maxGroupMembers {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: fmt.Sprintf("a conversation can have at most %d members", maxGroupMembers)})
+	}
+
+	reqCtx := ctx.Request().Context()
+	members := []*entity.ConversationMember{{UserID: userID, Status: entity.ConversationMemberAccepted}}
+	for _, id := range participants {
+		recipient, err := c.userRepo.FindByID(reqCtx, id)
+		if err != nil {
+			if err == repository.ErrRecordNotFound {
+				return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: fmt.Sprintf("user %d not found", id)})
+			}
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch user"})
+		}
+		allowed, err := canMessage(reqCtx, c.followRepo, c.blockRepo, userID, recipient)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to check message permissions"})
+		}
+		if !allowed {
+			return ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: fmt.Sprintf("%s does not accept direct messages from you", recipient.Username)})
+		}
+
+		// conversations from people the recipient does not follow land in
+		// their requests inbox
+		follows, err := c.followRepo.Exists(reqCtx, recipient.ID, userID)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to check follow state"})
+		}
+		status := entity.ConversationMemberPending
+		if follows {
+			status = entity.ConversationMemberAccepted
+		}
+		members = append(members, &entity.ConversationMember{UserID: recipient.ID, Status: status})
+	}
+
+	if req.Encrypted {
+		memberIDs := append([]int64{userID}, participants...)
+		keys, err := c.keyRepo.FindActiveByUsers(reqCtx, memberIDs)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch device keys"})
+		}
+		hasKeys := make(map[int64]bool, len(keys))
+		for _, k := range keys {
+			hasKeys[k.UserID] = true
+		}
+		for _, id := range memberIDs {
+			if !hasKeys[id] {
+				return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: fmt.Sprintf("user %d has no registered encryption keys", id)})
+			}
+		}
+	}
+
+	conv := &entity.Conversation{CreatorID: userID, Encrypted: req.Encrypted}
+	if len(participants) == 1 {
+		key := directKey(userID, participants[0], req.Encrypted)
+		existing, err := c.repo.FindByDirectKey(reqCtx, key)
+		if err == nil {
+			return c.reopenDirect(ctx, existing, userID)
+		}
+		if err != repository.ErrRecordNotFound {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch conversation"})
+		}
+		conv.Type = entity.ConversationTypeDirect
+		conv.DirectKey = &key
+	} else {
+		conv.Type = entity.ConversationTypeGroup
+		conv.Name = req.Name
+	}
+
+	if err := c.repo.CreateWithMembers(reqCtx, conv, members); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to create conversation"})
+	}
+	return c.respondConversation(ctx, http.StatusCreated, conv)
+}
+
+// ListConversations godoc
+// @Summary List conversations
+// @Description List the current user's conversations sorted by latest activity. The requests inbox holds conversations started by people the user does not follow.
+// @Tags conversations
+// @Produce json
+// @Param inbox query string false "primary (default) or requests"
+// @Success 200 {object} dto.WebResponse
+// @Router /api/v1/conversations [get]
+func (c *ConversationController) FindAll(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	status := entity.ConversationMemberAccepted
+	switch ctx.QueryParam("inbox") {
+	case "", inboxPrimary:
+	case inboxRequests:
+		status = entity.ConversationMemberPending
+	default:
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "inbox must be primary or requests"})
+	}
+
+	reqCtx := ctx.Request().Context()
+	convs, err := c.repo.FindByMember(reqCtx, userID, status)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch conversations"})
+	}
+	unread, err := c.repo.UnreadCounts(reqCtx, userID, status)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch unread counts"})
+	}
+
+	resp := make([]dto.ConversationResponse, 0, len(convs))
+	for _, conv := range convs {
+		item, err := c.buildConversation(reqCtx, conv, userID)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch conversation members"})
+		}
+		item.UnreadCount = unread[conv.ID]
+		resp = append(resp, item)
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp})
+}
+
+// UnreadConversations godoc
+// @Summary Unread badge counts
+// @Description Count unread conversations and messages in the primary inbox and unread message requests
+// @Tags conversations
+// @Produce json
+// @Success 200 {object} dto.WebResponse
+// @Router /api/v1/conversations/unread [get]
+func (c *ConversationController) Unread(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	reqCtx := ctx.Request().Context()
+	primary, err := c.repo.UnreadCounts(reqCtx, userID, entity.ConversationMemberAccepted)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch unread counts"})
+	}
+	requests, err := c.repo.UnreadCounts(reqCtx, userID, entity.ConversationMemberPending)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch unread counts"})
+	}
+
+	var messages int64
+	for _, n := range primary {
+		messages += n
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: echo.Map{
+		"conversations": len(primary),
+		"messages":      messages,
+		"requests":      len(requests),
+	}})
+}
+
+// GetConversation godoc
+// @Summary Get conversation
+// @Description Get a conversation the current user is a member of
+// @Tags conversations
+// @Produce json
+// @Param id path int true "Conversation ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/conversations/{id} [get]
+func (c *ConversationController) FindByID(ctx echo.Context) error {
+	conv, _, err := c.memberConversation(ctx)
+	if err != nil || conv == nil {
+		return err
+	}
+	return c.respondConversation(ctx, http.StatusOK, conv)
+}
+
+// RenameConversation godoc
+// @Summary Rename group conversation
+// @Description Rename a group conversation the current user is a member of
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Param id path int true "Conversation ID"
+// @Param conversation body renameConversationReq true "Rename payload"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/conversations/{id} [patch]
+func (c *ConversationController) Rename(ctx echo.Context) error {
+	conv, _, err := c.memberConversation(ctx)
+	if err != nil || conv == nil {
+		return err
+	}
+	if conv.Type != entity.ConversationTypeGroup {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "only group conversations can be renamed"})
+	}
+
+	var req renameConversationReq
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "renameConversationReq")})
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "renameConversationReq")})
+	}
+
+	if err := c.repo.Rename(ctx.Request().Context(), conv.ID, req.Name); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to rename conversation"})
+	}
+	conv.Name = req.Name
+	return c.respondConversation(ctx, http.StatusOK, conv)
+}
+
+// LeaveConversation godoc
+// @Summary Leave group conversation
+// @Description Leave a group conversation
+// @Tags conversations
+// @Produce json
+// @Param id path int true "Conversation ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/conversations/{id}/leave [post]
+func (c *ConversationController) Leave(ctx echo.Context) error {
+	conv, member, err := c.memberConversation(ctx)
+	if err != nil || conv == nil {
+		return err
+	}
+	if conv.Type != entity.ConversationTypeGroup {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "only group conversations can be left"})
+	}
+
+	if err := c.repo.Leave(ctx.Request().Context(), conv.ID, member.UserID); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to leave conversation"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "left conversation"})
+}
+
+// AcceptConversation godoc
+// @Summary Accept message request
+// @Description Move a conversation from the requests inbox to the primary inbox
+// @Tags conversations
+// @Produce json
+// @Param id path int true "Conversation ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/conversations/{id}/accept [post]
+func (c *ConversationController) Accept(ctx echo.Context) error {
+	conv, member, err := c.memberConversation(ctx)
+	if err != nil || conv == nil {
+		return err
+	}
+	if member.Status != entity.ConversationMemberPending {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "conversation is not a message request"})
+	}
+
+	if err := c.repo.Accept(ctx.Request().Context(), conv.ID, member.UserID); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to accept conversation"})
+	}
+	return c.respondConversation(ctx, http.StatusOK, conv)
+}
+
+// DeclineConversation godoc
+// @Summary Delete message request
+// @Description Remove a conversation from the requests inbox
+// @Tags conversations
+// @Produce json
+// @Param id path int true "Conversation ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/conversations/{id}/decline [post]
+func (c *ConversationController) Decline(ctx echo.Context) error {
+	conv, member, err := c.memberConversation(ctx)
+	if err != nil || conv == nil {
+		return err
+	}
+	if member.Status != entity.ConversationMemberPending {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "conversation is not a message request"})
+	}
+
+	if err := c.repo.Leave(ctx.Request().Context(), conv.ID, member.UserID); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to delete message request"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "message request deleted"})
+}
+
+// MarkConversationRead godoc
+// @Summary Mark conversation as read
+// @Description Advance the current user's read position in a conversation
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Param id path int true "Conversation ID"
+// @Param read body markReadReq true "Last read message"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/conversations/{id}/read [post]
+func (c *ConversationController) MarkRead(ctx echo.Context) error {
+	conv, member, err := c.memberConversation(ctx)
+	if err != nil || conv == nil {
+		return err
+	}
+
+	var req markReadReq
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "markReadReq")})
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "markReadReq")})
+	}
+
+	reqCtx := ctx.Request().Context()
+	msg, err := c.msgRepo.FindByID(reqCtx, req.MessageID)
+	if err != nil || msg.ConversationID != conv.ID {
+		if err == nil || err == repository.ErrRecordNotFound {
+			return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "message not found in conversation"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch message"})
+	}
+
+	if err := c.repo.MarkRead(reqCtx, conv.ID, member.UserID, msg.ID); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to mark conversation as read"})
+	}
+	return c.respondConversation(ctx, http.StatusOK, conv)
+}
+
+// ReportConversation godoc
+// @Summary Report conversation
+// @Description Report a conversation to moderators. Reporting a message request also deletes it.
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Param id path int true "Conversation ID"
+// @Param report body reportConversationReq true "Report payload"
+// @Success 201 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/conversations/{id}/report [post]
+func (c *ConversationController) Report(ctx echo.Context) error {
+	conv, member, err := c.memberConversation(ctx)
+	if err != nil || conv == nil {
+		return err
+	}
+
+	var req reportConversationReq
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "reportConversationReq")})
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "reportConversationReq")})
+	}
+
+	reqCtx := ctx.Request().Context()
+	report := &entity.Report{
+		ReporterID: member.UserID,
+		TargetType: entity.ReportTargetConversation,
+		TargetID:   conv.ID,
+		Reason:     req.Reason,
+		Comment:    req.Comment,
+	}
+	if err := c.reportRepo.Create(reqCtx, report); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to report conversation"})
+	}
+
+	if member.Status == entity.ConversationMemberPending {
+		if err := c.repo.Leave(reqCtx, conv.ID, member.UserID); err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to delete message request"})
+		}
+	}
+	return ctx.JSON(http.StatusCreated, dto.WebResponse[*entity.Report]{Message: "created", Data: report})
+}
+
+// SendMessage godoc
+// @Summary Send message
+// @Description Send a text message, optionally with media references, to a conversation. Encrypted conversations take one ciphertext envelope per recipient device instead; the server never sees their plaintext. Replying to a message request accepts it.
+// @Tags conversations
+// @Accept json
+// @Produce json
+// @Param id path int true "Conversation ID"
+// @Param message body sendMessageReq true "Message payload"
+// @Success 201 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Router /api/v1/conversations/{id}/messages [post]
+func (c *ConversationController) SendMessage(ctx echo.Context) error {
+	conv, member, err := c.memberConversation(ctx)
+	if err != nil || conv == nil {
+		return err
+	}
+	userID := member.UserID
+
+	var req sendMessageReq
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "sendMessageReq")})
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "sendMessageReq")})
+	}
+	req.Content = strings.TrimSpace(req.Content)
+	mediaIDs := uniqueIDs(req.MediaIDs, 0)
+
+	reqCtx := ctx.Request().Context()
+	var envelopes []*entity.MessageEnvelope
+	if conv.Encrypted {
+		if req.Content != "" || len(mediaIDs) > 0 {
+			return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "encrypted conversations only accept message envelopes"})
+		}
+		envelopes, err = c.buildEnvelopes(reqCtx, conv, userID, req)
+		if err != nil {
+			if err == errInvalidEnvelope {
+				return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "envelopes must come from an active device and address active devices of conversation members"})
+			}
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to check device keys"})
+		}
+	} else {
+		if len(req.Envelopes) > 0 {
+			return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "message envelopes are only accepted in encrypted conversations"})
+		}
+		if req.Content == "" && len(mediaIDs) == 0 {
+			return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "message must have content or media"})
+		}
+	}
+
+	if len(mediaIDs) > 0 {
+		media, err := c.mediaRepo.FindByIDs(reqCtx, mediaIDs)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch media"})
+		}
+		if len(media) != len(mediaIDs) {
+			return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "unknown media id"})
+		}
+		for _, m := range media {
+			if m.UserID != userID {
+				return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "media can only be sent by its uploader"})
+			}
+		}
+	}
+
+	// the recipient of a one-to-one conversation may have tightened their
+	// privacy setting or deleted the request since the conversation started
+	if conv.Type == entity.ConversationTypeDirect {
+		other, err := c.otherDirectMember(reqCtx, conv, userID)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch conversation members"})
+		}
+		recipient, err := c.userRepo.FindByID(reqCtx, other.UserID)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch user"})
+		}
+		allowed, err := canMessage(reqCtx, c.followRepo, c.blockRepo, userID, recipient)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to check message permissions"})
+		}
+		if !allowed {
+			return ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: fmt.Sprintf("%s does not accept direct messages from you", recipient.Username)})
+		}
+		if other.LeftAt != nil {
+			if err := c.repo.Reopen(reqCtx, conv.ID, other.UserID); err != nil {
+				return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to reopen conversation"})
+			}
+		}
+	}
+
+	if member.Status == entity.ConversationMemberPending {
+		if err := c.repo.Accept(reqCtx, conv.ID, userID); err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to accept conversation"})
+		}
+	}
+
+	msg := &entity.Message{ConversationID: conv.ID, SenderID: userID, Content: req.Content}
+	if conv.Encrypted {
+		err = c.msgRepo.CreateEncrypted(reqCtx, msg, envelopes)
+	} else {
+		err = c.msgRepo.Create(reqCtx, msg, mediaIDs)
+	}
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to send message"})
+	}
+	return ctx.JSON(http.StatusCreated, dto.WebResponse[dto.MessageResponse]{Message: "created", Data: dto.FromMessageEntity(msg, mediaIDs)})
+}
+
+// ListMessages godoc
+// @Summary List messages
+// @Description Page through a conversation's message history, newest first. Fetching messages marks them as delivered to the current user. Encrypted conversations return the envelope addressed to the given device.
+// @Tags conversations
+// @Produce json
+// @Param id path int true "Conversation ID"
+// @Param device_id query string false "Device to fetch envelopes for (encrypted conversations only)"
+// @Param cursor query string false "Cursor returned by the previous page"
+// @Param limit query int false "Page size"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/conversations/{id}/messages [get]
+func (c *ConversationController) Messages(ctx echo.Context) error {
+	conv, member, err := c.memberConversation(ctx)
+	if err != nil || conv == nil {
+		return err
+	}
+
+	beforeID, err := pageutils.DecodeCursor(ctx.QueryParam("cursor"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid cursor"})
+	}
+	limit := pageutils.ParseLimit(ctx.QueryParam("limit"), defaultMessagesLimit, maxMessagesLimit)
+	deviceID := ctx.QueryParam("device_id")
+	if conv.Encrypted && deviceID == "" {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "device_id is required for encrypted conversations"})
+	}
+
+	reqCtx := ctx.Request().Context()
+	msgs, err := c.msgRepo.FindByConversation(reqCtx, conv.ID, beforeID, limit)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch messages"})
+	}
+
+	ids := make([]int64, 0, len(msgs))
+	for _, m := range msgs {
+		ids = append(ids, m.ID)
+	}
+	media := map[int64][]int64{}
+	envelopes := map[int64]*entity.MessageEnvelope{}
+	if len(ids) > 0 {
+		media, err = c.msgRepo.FindMediaIDs(reqCtx, ids)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch message media"})
+		}
+		if conv.Encrypted {
+			envelopes, err = c.msgRepo.FindEnvelopes(reqCtx, ids, member.UserID, deviceID)
+			if err != nil {
+				return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch message envelopes"})
+			}
+		}
+		if err := c.repo.MarkDelivered(reqCtx, conv.ID, member.UserID, msgs[0].ID); err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to mark messages as delivered"})
+		}
+	}
+
+	resp := make([]dto.MessageResponse, 0, len(msgs))
+	for _, m := range msgs {
+		item := dto.FromMessageEntity(m, media[m.ID])
+		item.Envelope = envelopes[m.ID]
+		resp = append(resp, item)
+	}
+
+	cursor := &dto.CursorMetaData{Limit: limit}
+	if len(msgs) == limit {
+		cursor.NextCursor = pageutils.EncodeCursor(msgs[len(msgs)-1].ID)
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp, Cursor: cursor})
+}
+
+// memberConversation loads the conversation from the :id path param and makes
+// sure the current user is an active member. When it returns a nil
+// conversation the error response has already been written.
+func (c *ConversationController) memberConversation(ctx echo.Context) (*entity.Conversation, *entity.ConversationMember, error) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return nil, nil, ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return nil, nil, ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	reqCtx := ctx.Request().Context()
+	member, err := c.repo.FindMember(reqCtx, id, userID)
+	if err != nil || member.LeftAt != nil {
+		if err == nil || err == repository.ErrRecordNotFound {
+			return nil, nil, ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "conversation not found"})
+		}
+		return nil, nil, ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch conversation"})
+	}
+
+	conv, err := c.repo.FindByID(reqCtx, id)
+	if err != nil {
+		if err == repository.ErrRecordNotFound {
+			return nil, nil, ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "conversation not found"})
+		}
+		return nil, nil, ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch conversation"})
+	}
+	return conv, member, nil
+}
+
+// reopenDirect returns an existing one-to-one conversation to the user who
+// asked to start it, bringing it back if they had deleted it as a request.
+func (c *ConversationController) reopenDirect(ctx echo.Context, conv *entity.Conversation, userID int64) error {
+	reqCtx := ctx.Request().Context()
+	member, err := c.repo.FindMember(reqCtx, conv.ID, userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch conversation"})
+	}
+	if member.LeftAt != nil {
+		if err := c.repo.Reopen(reqCtx, conv.ID, userID); err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to reopen conversation"})
+		}
+	}
+	if member.LeftAt != nil || member.Status == entity.ConversationMemberPending {
+		if err := c.repo.Accept(reqCtx, conv.ID, userID); err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to accept conversation"})
+		}
+	}
+	return c.respondConversation(ctx, http.StatusOK, conv)
+}
+
+// otherDirectMember returns the membership of the other user in a one-to-one
+// conversation, whether or not they deleted it.
+func (c *ConversationController) otherDirectMember(ctx context.Context, conv *entity.Conversation, userID int64) (*entity.ConversationMember, error) {
+	var ids []string
+	if conv.DirectKey != nil {
+		ids = strings.Split(*conv.DirectKey, ":")
+	}
+	for _, raw := range ids {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil || id == userID {
+			continue
+		}
+		return c.repo.FindMember(ctx, conv.ID, id)
+	}
+	return nil, repository.ErrRecordNotFound
+}
+
+func (c *ConversationController) respondConversation(ctx echo.Context, status int, conv *entity.Conversation) error {
+	userID, _ := currentUserID(ctx)
+	reqCtx := ctx.Request().Context()
+
+	resp, err := c.buildConversation(reqCtx, conv, userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch conversation members"})
+	}
+	unread, err := c.repo.UnreadCounts(reqCtx, userID, resp.Status)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch unread counts"})
+	}
+	resp.UnreadCount = unread[conv.ID]
+	return ctx.JSON(status, dto.WebResponse[dto.ConversationResponse]{Data: resp})
+}
+
+// buildConversation assembles the viewer's view of a conversation. Read
+// positions are hidden when either side has turned read receipts off or the
+// other member has not accepted the conversation yet.
+func (c *ConversationController) buildConversation(ctx context.Context, conv *entity.Conversation, viewerID int64) (dto.ConversationResponse, error) {
+	members, err := c.repo.FindMembers(ctx, conv.ID)
+	if err != nil {
+		return dto.ConversationResponse{}, err
+	}
+
+	ids := make([]int64, 0, len(members))
+	for _, m := range members {
+		ids = append(ids, m.UserID)
+	}
+	users, err := c.userRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return dto.ConversationResponse{}, err
+	}
+	hideReceipts := make(map[int64]bool, len(users))
+	for _, u := range users {
+		hideReceipts[u.ID] = u.HideReadReceipts
+	}
+
+	hidden := make(map[int64]bool, len(members))
+	for _, m := range members {
+		hidden[m.UserID] = hideReceipts[viewerID] || hideReceipts[m.UserID] || m.Status == entity.ConversationMemberPending
+	}
+	return dto.FromConversationEntity(conv, members, viewerID, hidden), nil
+}
+
+// canMessage reports whether sender may send direct messages to recipient
+// according to the recipient's DM privacy setting. Users blocking one
+// another cannot message each other.
+func canMessage(ctx context.Context, followRepo repository.FollowRepositoryImpl, blockRepo repository.BlockRepositoryImpl, senderID int64, recipient *entity.User) (bool, error) {
+	blocked, err := blockRepo.ExistsEither(ctx, senderID, recipient.ID)
+	if err != nil || blocked {
+		return false, err
+	}
+	switch recipient.DMPrivacy {
+	case entity.DMPrivacyNobody:
+		return false, nil
+	case entity.DMPrivacyFollowers:
+		return followRepo.Exists(ctx, senderID, recipient.ID)
+	default:
+		return true, nil
+	}
+}
+
+// buildEnvelopes checks that an encrypted message is sent from one of the
+// sender's active devices and only addresses active devices of conversation
+// members.
+func (c *ConversationController) buildEnvelopes(ctx context.Context, conv *entity.Conversation, senderID int64, req sendMessageReq) ([]*entity.MessageEnvelope, error) {
+	if len(req.Envelopes) == 0 || req.SenderDeviceID == "" {
+		return nil, errInvalidEnvelope
+	}
+	if _, err := c.keyRepo.FindActive(ctx, senderID, req.SenderDeviceID); err != nil {
+		if err == repository.ErrRecordNotFound {
+			return nil, errInvalidEnvelope
+		}
+		return nil, err
+	}
+
+	members, err := c.repo.FindMembers(ctx, conv.ID)
+	if err != nil {
+		return nil, err
+	}
+	memberIDs := make([]int64, 0, len(members))
+	for _, m := range members {
+		memberIDs = append(memberIDs, m.UserID)
+	}
+	keys, err := c.keyRepo.FindActiveByUsers(ctx, memberIDs)
+	if err != nil {
+		return nil, err
+	}
+	active := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		active[fmt.Sprintf("%d/%s", k.UserID, k.DeviceID)] = true
+	}
+
+	envelopes := make([]*entity.MessageEnvelope, 0, len(req.Envelopes))
+	for _, e := range req.Envelopes {
+		if !active[fmt.Sprintf("%d/%s", e.RecipientUserID, e.RecipientDeviceID)] {
+			return nil, errInvalidEnvelope
+		}
+		envelopes = append(envelopes, &entity.MessageEnvelope{
+			SenderDeviceID:    req.SenderDeviceID,
+			RecipientUserID:   e.RecipientUserID,
+			RecipientDeviceID: e.RecipientDeviceID,
+			Type:              e.Type,
+			Ciphertext:        e.Ciphertext,
+		})
+	}
+	return envelopes, nil
+}
+
+// directKey builds the order-independent key identifying the one-to-one
+// conversation between two users. Encrypted and plaintext conversations
+// between the same pair are kept apart.
+func directKey(a, b int64, encrypted bool) string {
+	if a > b {
+		a, b = b, a
+	}
+	if encrypted {
+		return fmt.Sprintf("e2ee:%d:%d", a, b)
+	}
+	return fmt.Sprintf("%d:%d", a, b)
+}
+
+// uniqueIDs removes duplicates and the excluded id from ids, keeping order.
+func uniqueIDs(ids []int64, exclude int64) []int64 {
+	seen := make(map[int64]struct{}, len(ids))
+	out := make([]int64, 0, len(ids))
+	for _, id := range ids {
+		if id == exclude || id <= 0 {
+			continue
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		out = append(out, id)
+	}
+	return out
+}
@@ -0,0 +1,284 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"TwClone/internal/config"
+	"TwClone/internal/dto"
+	"TwClone/internal/entity"
+	"TwClone/internal/middleware"
+	"TwClone/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// DraftController manages the current user's drafts and scheduled tweets.
+type DraftController struct {
+	cfg       *config.DraftConfig
+	repo      repository.DraftRepositoryImpl
+	tweetRepo repository.TweetRepositoryImpl
+	mediaRepo repository.MediaRepositoryImpl
+	userRepo  repository.UserRepositoryImpl
+}
+
+func NewDraftController(cfg *config.DraftConfig) *DraftController {
+	return &DraftController{
+		cfg:       cfg,
+		repo:      repository.DraftRepositoryImpl{},
+		tweetRepo: repository.TweetRepositoryImpl{},
+		mediaRepo: repository.MediaRepositoryImpl{},
+		userRepo:  repository.UserRepositoryImpl{},
+	}
+}
+
+func (c *DraftController) Route(g *echo.Group) {
+	dg := g.Group("/drafts", middleware.AuthMiddleware())
+	dg.GET("", c.FindAll)
+	dg.POST("", c.Create)
+	dg.GET("/:id", c.FindByID)
+	dg.PUT("/:id", c.Update)
+	dg.DELETE("/:id", c.Delete)
+}
+
+type saveDraftReq struct {
+	Content        string     `json:"content" validate:"max=280"`
+	ReplyToTweetID *int64     `json:"reply_to_tweet_id" validate:"omitempty,gt=0"`
+	QuotedTweetID  *int64     `json:"quoted_tweet_id" validate:"omitempty,gt=0"`
+	MediaIDs       []int64    `json:"media_ids" validate:"max=4"`
+	PublishAt      *time.Time `json:"publish_at"`
+}
+
+// ListDrafts godoc
+// @Summary List drafts
+// @Description List your drafts, most recently saved first, or with scheduled=true your scheduled tweets, soonest first
+// @Tags drafts
+// @Produce json
+// @Param scheduled query bool false "List scheduled tweets instead of drafts"
+// @Success 200 {object} dto.WebResponse
+// @Router /api/v1/drafts [get]
+func (c *DraftController) FindAll(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	scheduled, _ := strconv.ParseBool(ctx.QueryParam("scheduled"))
+
+	drafts, err := c.repo.FindByUser(ctx.Request().Context(), userID, scheduled)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch drafts"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: drafts})
+}
+
+// GetDraft godoc
+// @Summary Get draft
+// @Description Get one of your drafts or scheduled tweets. A scheduled tweet that could not be published is back among the drafts with an error.
+// @Tags drafts
+// @Produce json
+// @Param id path int true "Draft ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/drafts/{id} [get]
+func (c *DraftController) FindByID(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	draft, err := c.repo.FindByID(ctx.Request().Context(), id, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "draft not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch draft"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: draft})
+}
+
+// CreateDraft godoc
+// @Summary Save draft
+// @Description Save a tweet for later. Attach uploads from POST /media by id. With publish_at the draft is scheduled and published at that time; scheduled tweets must be valid tweets and are checked again when published. If publishing fails the tweet returns to your drafts with an error and you get a scheduled_tweet_failed notification.
+// @Tags drafts
+// @Accept json
+// @Produce json
+// @Param draft body saveDraftReq true "Draft payload"
+// @Success 201 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/drafts [post]
+func (c *DraftController) Create(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	draft, ok := c.bind(ctx, userID)
+	if !ok {
+		return nil
+	}
+
+	reqCtx := ctx.Request().Context()
+	count, err := c.repo.CountByUser(reqCtx, userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to count drafts"})
+	}
+	if count >= int64(c.cfg.MaxPerUser) {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "draft limit reached"})
+	}
+
+	if err := c.repo.Create(reqCtx, draft); err != nil {
+		if errors.Is(err, repository.ErrMediaUnavailable) {
+			return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "media not found or already attached"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to save draft"})
+	}
+	return ctx.JSON(http.StatusCreated, dto.WebResponse[any]{Data: draft})
+}
+
+// UpdateDraft godoc
+// @Summary Update draft
+// @Description Replace a draft, for instance to autosave it or to schedule or unschedule it. A scheduled tweet that is being published can no longer be changed.
+// @Tags drafts
+// @Accept json
+// @Produce json
+// @Param id path int true "Draft ID"
+// @Param draft body saveDraftReq true "Draft payload"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/drafts/{id} [put]
+func (c *DraftController) Update(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	draft, ok := c.bind(ctx, userID)
+	if !ok {
+		return nil
+	}
+	draft.ID = id
+
+	if err := c.repo.Update(ctx.Request().Context(), draft); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "draft not found"})
+		}
+		if errors.Is(err, repository.ErrMediaUnavailable) {
+			return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "media not found or already attached"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to save draft"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: draft})
+}
+
+// DeleteDraft godoc
+// @Summary Delete draft
+// @Description Delete one of your drafts or cancel a scheduled tweet
+// @Tags drafts
+// @Produce json
+// @Param id path int true "Draft ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/drafts/{id} [delete]
+func (c *DraftController) Delete(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	if err := c.repo.Delete(ctx.Request().Context(), id, userID); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "draft not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to delete draft"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "draft deleted"})
+}
+
+// bind reads a draft from the request body. A draft with a publish time is
+// checked the way a new tweet would be. When ok is false the error response
+// has already been written.
+func (c *DraftController) bind(ctx echo.Context, userID int64) (*entity.Draft, bool) {
+	var req saveDraftReq
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "saveDraftReq")})
+		return nil, false
+	}
+	if err := ctx.Validate(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "saveDraftReq")})
+		return nil, false
+	}
+
+	draft := &entity.Draft{
+		UserID:         userID,
+		Content:        req.Content,
+		ReplyToTweetID: req.ReplyToTweetID,
+		QuotedTweetID:  req.QuotedTweetID,
+		MediaIDs:       uniqueIDs(req.MediaIDs, 0),
+		PublishAt:      req.PublishAt,
+	}
+	if draft.PublishAt == nil {
+		return draft, true
+	}
+
+	now := time.Now()
+	switch {
+	case !draft.PublishAt.After(now):
+		ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "publish_at must be in the future"})
+		return nil, false
+	case draft.PublishAt.After(now.AddDate(0, 0, c.cfg.MaxScheduleDays)):
+		ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "publish_at is too far in the future"})
+		return nil, false
+	case draft.Content == "" && len(draft.MediaIDs) == 0:
+		ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "tweet must have content or media"})
+		return nil, false
+	}
+
+	reqCtx := ctx.Request().Context()
+	if draft.ReplyToTweetID != nil {
+		if _, err := c.tweetRepo.FindVisibleOriginal(reqCtx, *draft.ReplyToTweetID, userID); err != nil {
+			if errors.Is(err, repository.ErrRecordNotFound) {
+				ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "replied tweet not found"})
+			} else {
+				ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch replied tweet"})
+			}
+			return nil, false
+		}
+	}
+	if draft.QuotedTweetID != nil {
+		if _, err := c.tweetRepo.FindVisibleOriginal(reqCtx, *draft.QuotedTweetID, userID); err != nil {
+			if errors.Is(err, repository.ErrRecordNotFound) {
+				ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "quoted tweet not found"})
+			} else {
+				ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch quoted tweet"})
+			}
+			return nil, false
+		}
+	}
+	if len(draft.MediaIDs) > 0 {
+		missing, err := missingAltText(reqCtx, c.userRepo, c.mediaRepo, userID, draft.MediaIDs)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to check media alt text"})
+			return nil, false
+		}
+		if missing {
+			ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "alt text is required on all media"})
+			return nil, false
+		}
+	}
+	return draft, true
+}
@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"TwClone/internal/database"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// fakeResult is what the fake database answers to the queries containing
+// match.
+type fakeResult struct {
+	match   string
+	columns []string
+	rows    [][]driver.Value
+}
+
+// fakeDB stands in for Postgres in handler tests. Queries are answered with
+// the first result whose match they contain, and with no rows otherwise;
+// statements affect no rows.
+type fakeDB struct {
+	results []fakeResult
+
+	mu      sync.Mutex
+	queries []string
+}
+
+// useFakeDB points the repositories at a fake database for the duration of
+// the test.
+func useFakeDB(t *testing.T, results ...fakeResult) *fakeDB {
+	t.Helper()
+	fake := &fakeDB{results: results}
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: sql.OpenDB(fake)}), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open() error = %v", err)
+	}
+	prev := database.DB
+	database.DB = gdb
+	t.Cleanup(func() { database.DB = prev })
+	return fake
+}
+
+// ran reports whether a query containing match was run.
+func (f *fakeDB) ran(match string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, q := range f.queries {
+		if strings.Contains(q, match) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return fakeConn{f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return fakeDriver{f} }
+
+func (f *fakeDB) query(query string) *fakeRows {
+	f.mu.Lock()
+	f.queries = append(f.queries, query)
+	f.mu.Unlock()
+	for _, r := range f.results {
+		if strings.Contains(query, r.match) {
+			return &fakeRows{columns: r.columns, rows: r.rows}
+		}
+	}
+	return &fakeRows{}
+}
+
+type fakeDriver struct{ db *fakeDB }
+
+func (d fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{d.db}, nil }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) { return fakeStmt{c.db, query}, nil }
+func (c fakeConn) Close() error                              { return nil }
+func (c fakeConn) Begin() (driver.Tx, error)                 { return fakeTx{}, nil }
+
+func (c fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	return c.db.query(query), nil
+}
+
+func (c fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	c.db.query(query)
+	return driver.RowsAffected(0), nil
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s fakeStmt) Close() error  { return nil }
+func (s fakeStmt) NumInput() int { return -1 }
+
+func (s fakeStmt) Exec([]driver.Value) (driver.Result, error) {
+	s.db.query(s.query)
+	return driver.RowsAffected(0), nil
+}
+
+func (s fakeStmt) Query([]driver.Value) (driver.Rows, error) { return s.db.query(s.query), nil }
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+// testValidator validates requests like the server does.
+type testValidator struct{ v *validator.Validate }
+
+func (tv testValidator) Validate(i any) error { return tv.v.Struct(i) }
+
+// newTestEcho returns an Echo instance with a request validator.
+func newTestEcho() *echo.Echo {
+	e := echo.New()
+	e.Validator = testValidator{validator.New()}
+	return e
+}
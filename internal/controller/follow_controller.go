@@ -2,110 +2,384 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strconv"
 
 	"TwClone/internal/dto"
 	"TwClone/internal/entity"
 	"TwClone/internal/middleware"
+	"TwClone/internal/pkg/utils/pageutils"
 	"TwClone/internal/repository"
 
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	defaultUsersLimit = 20
+	maxUsersLimit     = 100
+)
+
 type FollowController struct {
-	repo repository.FollowRepositoryImpl
+	presenter *userPresenter
+	repo      repository.FollowRepositoryImpl
+	userRepo  repository.UserRepositoryImpl
+	blockRepo repository.BlockRepositoryImpl
 }
 
 func NewFollowController() *FollowController {
-	return &FollowController{repo: repository.FollowRepositoryImpl{}}
+	return &FollowController{
+		presenter: newUserPresenter(),
+		repo:      repository.FollowRepositoryImpl{},
+		userRepo:  repository.UserRepositoryImpl{},
+		blockRepo: repository.BlockRepositoryImpl{},
+	}
 }
 
 func (c *FollowController) Route(g *echo.Group) {
 	fg := g.Group("/follows", middleware.AuthMiddleware())
 	fg.POST("", c.Create)
 	fg.DELETE("", c.Delete)
+	fg.GET("/requests", c.Requests)
+	fg.POST("/requests/:user_id", c.ApproveRequest)
+	fg.DELETE("/requests/:user_id", c.DeclineRequest)
 	fg.GET("/followers/:id", c.Followers)
 	fg.GET("/following/:id", c.Following)
+	g.GET("/users/:id/followers/you-know", c.FollowersYouKnow, middleware.AuthMiddleware())
 }
 
-func (c *FollowController) Create(ctx echo.Context) error {
-	var follow entity.Follow
-	if err := ctx.Bind(&follow); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "Follow")})
-	}
-	if err := c.repo.Create(context.Background(), &follow); err != nil {
-		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: err.Error()})
-	}
-	return ctx.JSON(http.StatusCreated, follow)
+type followReq struct {
+	FollowingID int64 `json:"following_id" validate:"required,gt=0"`
 }
 
 // CreateFollow godoc
 // @Summary Create follow
-// @Description Follow a user
+// @Description Follow a user. Following a protected user sends them a follow request instead, which they can approve or decline.
 // @Tags follows
 // @Accept json
 // @Produce json
-// @Param follow body entity.Follow true "Follow payload"
+// @Param follow body followReq true "Follow payload"
 // @Success 201 {object} entity.Follow
+// @Success 202 {object} dto.WebResponse
 // @Failure 400 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Failure 409 {object} dto.WebResponse
 // @Router /api/v1/follows [post]
+func (c *FollowController) Create(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	var req followReq
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "followReq")})
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "followReq")})
+	}
+	if req.FollowingID == userID {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "you cannot follow yourself"})
+	}
+
+	reqCtx := ctx.Request().Context()
+	target, err := c.userRepo.FindByID(reqCtx, req.FollowingID)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "user not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch user"})
+	}
+	blocked, err := c.blockRepo.ExistsEither(reqCtx, userID, target.ID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to check blocks"})
+	}
+	if blocked {
+		return ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "you cannot follow this user"})
+	}
+
+	if target.Protected {
+		following, err := c.repo.Exists(reqCtx, userID, target.ID)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to check follow"})
+		}
+		if following {
+			return ctx.JSON(http.StatusConflict, dto.WebResponse[any]{Message: "already following"})
+		}
+		if err := c.repo.CreateRequest(reqCtx, &entity.FollowRequest{RequesterID: userID, TargetID: target.ID}); err != nil {
+			if errors.Is(err, repository.ErrDuplicate) {
+				return ctx.JSON(http.StatusConflict, dto.WebResponse[any]{Message: "follow request already sent"})
+			}
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to send follow request"})
+		}
+		return ctx.JSON(http.StatusAccepted, dto.WebResponse[any]{Message: "follow request sent"})
+	}
+
+	follow := entity.Follow{FollowerID: userID, FollowingID: target.ID}
+	if err := c.repo.Create(reqCtx, &follow); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return ctx.JSON(http.StatusConflict, dto.WebResponse[any]{Message: "already following"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to follow user"})
+	}
+	return ctx.JSON(http.StatusCreated, follow)
+}
 
 // DeleteFollow godoc
 // @Summary Unfollow
-// @Description Unfollow a user
+// @Description Unfollow a user, or withdraw your request to follow them
 // @Tags follows
 // @Accept json
 // @Produce json
-// @Param follower_id query int true "Follower ID"
 // @Param following_id query int true "Following ID"
 // @Success 200 {object} dto.WebResponse
 // @Failure 400 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
 // @Router /api/v1/follows [delete]
+func (c *FollowController) Delete(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	followingID, err := strconv.ParseInt(ctx.QueryParam("following_id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid following id"})
+	}
+
+	if err := c.repo.Delete(ctx.Request().Context(), userID, followingID); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "not following"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to unfollow user"})
+	}
+	return ctx.JSON(http.StatusOK, echo.Map{"message": "unfollowed"})
+}
+
+// ListFollowRequests godoc
+// @Summary List follow requests
+// @Description List the pending requests to follow you, oldest first
+// @Tags follows
+// @Produce json
+// @Success 200 {object} dto.WebResponse
+// @Router /api/v1/follows/requests [get]
+func (c *FollowController) Requests(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	requests, err := c.repo.FindRequests(ctx.Request().Context(), userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch follow requests"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: requests})
+}
+
+// ApproveFollowRequest godoc
+// @Summary Approve follow request
+// @Description Let a user who asked to follow you follow you
+// @Tags follows
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/follows/requests/{user_id} [post]
+func (c *FollowController) ApproveRequest(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	requesterID, err := strconv.ParseInt(ctx.Param("user_id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid user id"})
+	}
+
+	if err := c.repo.ApproveRequest(ctx.Request().Context(), requesterID, userID); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "follow request not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to approve follow request"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "follow request approved"})
+}
+
+// DeclineFollowRequest godoc
+// @Summary Decline follow request
+// @Description Decline a user's request to follow you; they are not told
+// @Tags follows
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/follows/requests/{user_id} [delete]
+func (c *FollowController) DeclineRequest(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	requesterID, err := strconv.ParseInt(ctx.Param("user_id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid user id"})
+	}
+
+	if err := c.repo.DeleteRequest(ctx.Request().Context(), requesterID, userID); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "follow request not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to decline follow request"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "follow request declined"})
+}
 
 // GetFollowers godoc
 // @Summary Get followers
-// @Description Get followers of a user
+// @Description List the users following a user, most recent follow first unless sort is oldest. The followers of a protected user are only listed to the user and their followers.
 // @Tags follows
-// @Accept json
 // @Produce json
 // @Param id path int true "User ID"
-// @Success 200 {array} entity.Follow
+// @Param sort query string false "newest (default) or oldest"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
 // @Router /api/v1/follows/followers/{id} [get]
+func (c *FollowController) Followers(ctx echo.Context) error {
+	return c.list(ctx, func(reqCtx context.Context, viewerID, userID int64, q repository.FollowPageQuery) ([]*repository.FollowedUser, error) {
+		return c.repo.FindFollowerPage(reqCtx, userID, q)
+	})
+}
 
 // GetFollowing godoc
 // @Summary Get following
-// @Description Get users followed by a user
+// @Description List the users a user follows, most recent follow first unless sort is oldest. The follows of a protected user are only listed to the user and their followers.
 // @Tags follows
-// @Accept json
 // @Produce json
 // @Param id path int true "User ID"
-// @Success 200 {array} entity.Follow
+// @Param sort query string false "newest (default) or oldest"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
 // @Router /api/v1/follows/following/{id} [get]
+func (c *FollowController) Following(ctx echo.Context) error {
+	return c.list(ctx, func(reqCtx context.Context, viewerID, userID int64, q repository.FollowPageQuery) ([]*repository.FollowedUser, error) {
+		return c.repo.FindFollowingPage(reqCtx, userID, q)
+	})
+}
 
-func (c *FollowController) Delete(ctx echo.Context) error {
-	followerID, _ := strconv.ParseInt(ctx.QueryParam("follower_id"), 10, 64)
-	followingID, _ := strconv.ParseInt(ctx.QueryParam("following_id"), 10, 64)
-	if err := c.repo.Delete(context.Background(), followerID, followingID); err != nil {
-		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: err.Error()})
-	}
-	return ctx.JSON(http.StatusOK, echo.Map{"message": "unfollowed"})
+// GetFollowersYouKnow godoc
+// @Summary Get followers you know
+// @Description List the users following a user that you follow too, most recent follow first unless sort is oldest
+// @Tags follows
+// @Produce json
+// @Param id path int true "User ID"
+// @Param sort query string false "newest (default) or oldest"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/users/{id}/followers/you-know [get]
+func (c *FollowController) FollowersYouKnow(ctx echo.Context) error {
+	return c.list(ctx, func(reqCtx context.Context, viewerID, userID int64, q repository.FollowPageQuery) ([]*repository.FollowedUser, error) {
+		return c.repo.FindFollowersYouKnowPage(reqCtx, userID, viewerID, q)
+	})
 }
 
-func (c *FollowController) Followers(ctx echo.Context) error {
-	userID, _ := strconv.ParseInt(ctx.Param("id"), 10, 64)
-	follows, err := c.repo.FindFollowers(context.Background(), userID)
+// list serves a page of users related to the user in the id path parameter
+// by follows, as found by find.
+func (c *FollowController) list(ctx echo.Context, find func(reqCtx context.Context, viewerID, userID int64, q repository.FollowPageQuery) ([]*repository.FollowedUser, error)) error {
+	viewerID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	userID, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+	var q repository.FollowPageQuery
+	switch ctx.QueryParam("sort") {
+	case "", "newest":
+	case "oldest":
+		q.OldestFirst = true
+	default:
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "sort must be newest or oldest"})
+	}
+	q.CursorTime, q.CursorUserID, err = pageutils.DecodeTimeCursor(ctx.QueryParam("cursor"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid cursor"})
+	}
+	q.Limit = pageutils.ParseLimit(ctx.QueryParam("limit"), defaultUsersLimit, maxUsersLimit)
+
+	if !c.listable(ctx, viewerID, userID) {
+		return nil
+	}
+
+	reqCtx := ctx.Request().Context()
+	page, err := find(reqCtx, viewerID, userID, q)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: err.Error()})
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch users"})
 	}
-	return ctx.JSON(http.StatusOK, follows)
+	users := make([]*entity.User, 0, len(page))
+	for _, f := range page {
+		users = append(users, &f.User)
+	}
+	resp, err := c.presenter.build(reqCtx, users, viewerID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch users"})
+	}
+
+	cursor := &dto.CursorMetaData{Limit: q.Limit}
+	if len(page) == q.Limit {
+		last := page[len(page)-1]
+		cursor.NextCursor = pageutils.EncodeTimeCursor(last.FollowedAt, last.ID)
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp, Cursor: cursor})
 }
 
-func (c *FollowController) Following(ctx echo.Context) error {
-	userID, _ := strconv.ParseInt(ctx.Param("id"), 10, 64)
-	follows, err := c.repo.FindFollowing(context.Background(), userID)
+// listable checks that the viewer may see who a user follows and is
+// followed by: anyone can for public users, only the user and their
+// followers can for protected users, and nobody on either side of a block.
+// When ok is false the error response has already been written.
+func (c *FollowController) listable(ctx echo.Context, viewerID, userID int64) bool {
+	reqCtx := ctx.Request().Context()
+	user, err := c.userRepo.FindByID(reqCtx, userID)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: err.Error()})
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "user not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch user"})
+		}
+		return false
+	}
+	if user.ID == viewerID {
+		return true
+	}
+
+	blocked, err := c.blockRepo.ExistsEither(reqCtx, viewerID, user.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to check blocks"})
+		return false
+	}
+	if blocked {
+		ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "you cannot see this user's follows"})
+		return false
+	}
+	if user.Protected {
+		following, err := c.repo.Exists(reqCtx, viewerID, user.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to check follow"})
+			return false
+		}
+		if !following {
+			ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "this user's follows are protected"})
+			return false
+		}
 	}
-	return ctx.JSON(http.StatusOK, follows)
+	return true
 }
@@ -5,7 +5,6 @@ import (
 	"net/http"
 
 	"TwClone/internal/dto"
-	"TwClone/internal/entity"
 	"TwClone/internal/repository"
 
 	"github.com/labstack/echo/v4"
@@ -21,33 +20,10 @@ func NewHashtagController() *HashtagController {
 
 func (c *HashtagController) Route(g *echo.Group) {
 	hg := g.Group("/hashtags")
-	hg.POST("", c.Create)
 	hg.GET("", c.FindAll)
 	hg.GET("/:tag", c.FindByTag)
 }
 
-func (c *HashtagController) Create(ctx echo.Context) error {
-	var hashtag entity.Hashtag
-	if err := ctx.Bind(&hashtag); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "Hashtag")})
-	}
-	if err := c.repo.Create(context.Background(), &hashtag); err != nil {
-		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: err.Error()})
-	}
-	return ctx.JSON(http.StatusCreated, hashtag)
-}
-
-// CreateHashtag godoc
-// @Summary Create hashtag
-// @Description Create a new hashtag
-// @Tags hashtags
-// @Accept json
-// @Produce json
-// @Param hashtag body entity.Hashtag true "Hashtag payload"
-// @Success 201 {object} entity.Hashtag
-// @Failure 400 {object} dto.WebResponse
-// @Router /api/v1/hashtags [post]
-
 // ListHashtags godoc
 // @Summary List hashtags
 // @Description Get all hashtags
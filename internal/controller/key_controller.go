@@ -0,0 +1,422 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"TwClone/internal/dto"
+	"TwClone/internal/entity"
+	"TwClone/internal/middleware"
+	"TwClone/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// preKeyClaimsPerWindow caps the one-time prekeys a user can claim from
+	// one device per preKeyClaimWindow, so that nobody can drain another
+	// user's prekeys. Past the cap bundles come without one, and sessions
+	// fall back to the signed prekey.
+	preKeyClaimsPerWindow = 3
+	preKeyClaimWindow     = 24 * time.Hour
+)
+
+// KeyController is the public key directory backing end-to-end encrypted
+// conversations. Clients publish the public half of their device keys here
+// and fetch their peers' keys to set up sessions.
+type KeyController struct {
+	repo       repository.DeviceKeyRepositoryImpl
+	convRepo   repository.ConversationRepositoryImpl
+	notifRepo  repository.NotificationRepositoryImpl
+	userRepo   repository.UserRepositoryImpl
+	followRepo repository.FollowRepositoryImpl
+	blockRepo  repository.BlockRepositoryImpl
+}
+
+func NewKeyController() *KeyController {
+	return &KeyController{
+		repo:       repository.DeviceKeyRepositoryImpl{},
+		convRepo:   repository.ConversationRepositoryImpl{},
+		notifRepo:  repository.NotificationRepositoryImpl{},
+		userRepo:   repository.UserRepositoryImpl{},
+		followRepo: repository.FollowRepositoryImpl{},
+		blockRepo:  repository.BlockRepositoryImpl{},
+	}
+}
+
+func (c *KeyController) Route(g *echo.Group) {
+	kg := g.Group("/keys", middleware.AuthMiddleware())
+	kg.POST("/devices", c.RegisterDevice)
+	kg.GET("/devices", c.MyDevices)
+	kg.DELETE("/devices/:device_id", c.RevokeDevice)
+	kg.POST("/devices/:device_id/prekeys", c.UploadPreKeys)
+	kg.DELETE("/devices/:device_id/prekeys", c.RevokePreKeys)
+	kg.GET("/users/:id", c.UserDevices)
+	kg.POST("/users/:id/claim", c.ClaimBundles)
+}
+
+type preKeyReq struct {
+	KeyID     int64  `json:"key_id" validate:"gte=0"`
+	PublicKey string `json:"public_key" validate:"required,base64,max=512"`
+}
+
+type registerDeviceReq struct {
+	DeviceID              string      `json:"device_id" validate:"required,max=64"`
+	IdentityKey           string      `json:"identity_key" validate:"required,base64,max=512"`
+	SignedPreKeyID        int64       `json:"signed_prekey_id" validate:"gte=0"`
+	SignedPreKey          string      `json:"signed_prekey" validate:"required,base64,max=512"`
+	SignedPreKeySignature string      `json:"signed_prekey_signature" validate:"required,base64,max=512"`
+	OneTimePreKeys        []preKeyReq `json:"one_time_prekeys" validate:"max=100,dive"`
+}
+
+type uploadPreKeysReq struct {
+	OneTimePreKeys []preKeyReq `json:"one_time_prekeys" validate:"required,min=1,max=100,dive"`
+}
+
+// RegisterDevice godoc
+// @Summary Register device keys
+// @Description Publish or rotate the public keys of one of the current user's devices. Changing a device's identity key notifies the members of the user's encrypted conversations.
+// @Tags keys
+// @Accept json
+// @Produce json
+// @Param device body registerDeviceReq true "Device keys"
+// @Success 201 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/keys/devices [post]
+func (c *KeyController) RegisterDevice(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	var req registerDeviceReq
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "registerDeviceReq")})
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "registerDeviceReq")})
+	}
+
+	reqCtx := ctx.Request().Context()
+	previous, err := c.repo.FindActive(reqCtx, userID, req.DeviceID)
+	if err != nil && err != repository.ErrRecordNotFound {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch device"})
+	}
+
+	key := &entity.DeviceKey{
+		UserID:                userID,
+		DeviceID:              req.DeviceID,
+		IdentityKey:           req.IdentityKey,
+		SignedPreKeyID:        req.SignedPreKeyID,
+		SignedPreKey:          req.SignedPreKey,
+		SignedPreKeySignature: req.SignedPreKeySignature,
+	}
+	if err := c.repo.Upsert(reqCtx, key, toPreKeys(req.OneTimePreKeys)); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to register device"})
+	}
+
+	if previous == nil || previous.IdentityKey != key.IdentityKey {
+		if err := c.notifyKeyChange(reqCtx, userID); err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to notify conversation members"})
+		}
+	}
+	return c.respondOwnDevice(ctx, http.StatusCreated, key)
+}
+
+// ListMyDevices godoc
+// @Summary List own devices
+// @Description List the current user's registered devices with their remaining one-time prekeys
+// @Tags keys
+// @Produce json
+// @Success 200 {object} dto.WebResponse
+// @Router /api/v1/keys/devices [get]
+func (c *KeyController) MyDevices(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	reqCtx := ctx.Request().Context()
+	keys, err := c.repo.FindActiveByUser(reqCtx, userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch devices"})
+	}
+
+	ids := make([]int64, 0, len(keys))
+	for _, k := range keys {
+		ids = append(ids, k.ID)
+	}
+	remaining := map[int64]int64{}
+	if len(ids) > 0 {
+		remaining, err = c.repo.CountPreKeys(reqCtx, ids)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to count prekeys"})
+		}
+	}
+
+	resp := make([]dto.DeviceKeyResponse, 0, len(keys))
+	for _, k := range keys {
+		item := dto.FromDeviceKeyEntity(k)
+		count := remaining[k.ID]
+		item.RemainingPreKeys = &count
+		resp = append(resp, item)
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp})
+}
+
+// RevokeDevice godoc
+// @Summary Revoke device
+// @Description Revoke one of the current user's devices and its prekeys. Members of the user's encrypted conversations are notified.
+// @Tags keys
+// @Produce json
+// @Param device_id path string true "Device ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/keys/devices/{device_id} [delete]
+func (c *KeyController) RevokeDevice(ctx echo.Context) error {
+	key, err := c.ownDevice(ctx)
+	if err != nil || key == nil {
+		return err
+	}
+
+	reqCtx := ctx.Request().Context()
+	if err := c.repo.Revoke(reqCtx, key.ID); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to revoke device"})
+	}
+	if err := c.notifyKeyChange(reqCtx, key.UserID); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to notify conversation members"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "device revoked"})
+}
+
+// UploadPreKeys godoc
+// @Summary Upload prekeys
+// @Description Add one-time prekeys to one of the current user's devices
+// @Tags keys
+// @Accept json
+// @Produce json
+// @Param device_id path string true "Device ID"
+// @Param prekeys body uploadPreKeysReq true "One-time prekeys"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/keys/devices/{device_id}/prekeys [post]
+func (c *KeyController) UploadPreKeys(ctx echo.Context) error {
+	key, err := c.ownDevice(ctx)
+	if err != nil || key == nil {
+		return err
+	}
+
+	var req uploadPreKeysReq
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "uploadPreKeysReq")})
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "uploadPreKeysReq")})
+	}
+
+	if err := c.repo.AddPreKeys(ctx.Request().Context(), key.ID, toPreKeys(req.OneTimePreKeys)); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to upload prekeys"})
+	}
+	return c.respondOwnDevice(ctx, http.StatusOK, key)
+}
+
+// RevokePreKeys godoc
+// @Summary Revoke prekeys
+// @Description Drop all unclaimed one-time prekeys of one of the current user's devices
+// @Tags keys
+// @Produce json
+// @Param device_id path string true "Device ID"
+// @Success 200 {object} dto.WebResponse
+// @Router /api/v1/keys/devices/{device_id}/prekeys [delete]
+func (c *KeyController) RevokePreKeys(ctx echo.Context) error {
+	key, err := c.ownDevice(ctx)
+	if err != nil || key == nil {
+		return err
+	}
+
+	if err := c.repo.RevokePreKeys(ctx.Request().Context(), key.ID); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to revoke prekeys"})
+	}
+	return c.respondOwnDevice(ctx, http.StatusOK, key)
+}
+
+// ListUserDevices godoc
+// @Summary List user devices
+// @Description List the public identity and signed prekeys of a user's devices
+// @Tags keys
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/keys/users/{id} [get]
+func (c *KeyController) UserDevices(ctx echo.Context) error {
+	_, keys, err := c.userKeys(ctx)
+	if err != nil || keys == nil {
+		return err
+	}
+
+	resp := make([]dto.DeviceKeyResponse, 0, len(keys))
+	for _, k := range keys {
+		resp = append(resp, dto.FromDeviceKeyEntity(k))
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp})
+}
+
+// ClaimKeyBundles godoc
+// @Summary Claim key bundles
+// @Description Fetch a session bundle for every device of a user you may message, or who is in an encrypted conversation with you, consuming one one-time prekey per device when available. You can claim up to 3 one-time prekeys of a device a day; past that bundles come without one.
+// @Tags keys
+// @Produce json
+// @Param id path int true "User ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/keys/users/{id}/claim [post]
+func (c *KeyController) ClaimBundles(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	user, keys, err := c.userKeys(ctx)
+	if err != nil || keys == nil {
+		return err
+	}
+
+	reqCtx := ctx.Request().Context()
+	allowed, err := c.canClaim(reqCtx, userID, user)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to check message permissions"})
+	}
+	if !allowed {
+		return ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: user.Username + " does not accept direct messages from you"})
+	}
+
+	since := time.Now().Add(-preKeyClaimWindow)
+	resp := make([]dto.DeviceKeyResponse, 0, len(keys))
+	for _, k := range keys {
+		item := dto.FromDeviceKeyEntity(k)
+		item.OneTimePreKey, err = c.repo.ClaimPreKey(reqCtx, k.ID, userID, since, preKeyClaimsPerWindow)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to claim prekey"})
+		}
+		resp = append(resp, item)
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp})
+}
+
+// canClaim reports whether claimer may start sessions with the devices of
+// user: their own other devices, users they may message, and the members
+// of their encrypted conversations unless either blocks the other.
+func (c *KeyController) canClaim(ctx context.Context, claimerID int64, user *entity.User) (bool, error) {
+	if claimerID == user.ID {
+		return true, nil
+	}
+	allowed, err := canMessage(ctx, c.followRepo, c.blockRepo, claimerID, user)
+	if err != nil || allowed {
+		return allowed, err
+	}
+	blocked, err := c.blockRepo.ExistsEither(ctx, claimerID, user.ID)
+	if err != nil || blocked {
+		return false, err
+	}
+	peers, err := c.convRepo.FindEncryptedPeers(ctx, claimerID)
+	if err != nil {
+		return false, err
+	}
+	for _, p := range peers {
+		if p.UserID == user.ID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// ownDevice loads the current user's device from the :device_id path param.
+// When it returns a nil device the error response has already been written.
+func (c *KeyController) ownDevice(ctx echo.Context) (*entity.DeviceKey, error) {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return nil, ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	key, err := c.repo.FindActive(ctx.Request().Context(), userID, ctx.Param("device_id"))
+	if err != nil {
+		if err == repository.ErrRecordNotFound {
+			return nil, ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "device not found"})
+		}
+		return nil, ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch device"})
+	}
+	return key, nil
+}
+
+// userKeys loads the user from the :id path param and their active
+// devices. When it returns nil devices the error response has already been
+// written.
+func (c *KeyController) userKeys(ctx echo.Context) (*entity.User, []*entity.DeviceKey, error) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return nil, nil, ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	reqCtx := ctx.Request().Context()
+	user, err := c.userRepo.FindByID(reqCtx, id)
+	if err != nil {
+		if err == repository.ErrRecordNotFound {
+			return nil, nil, ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "user not found"})
+		}
+		return nil, nil, ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch user"})
+	}
+
+	keys, err := c.repo.FindActiveByUser(reqCtx, id)
+	if err != nil {
+		return nil, nil, ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch devices"})
+	}
+	if keys == nil {
+		keys = []*entity.DeviceKey{}
+	}
+	return user, keys, nil
+}
+
+func (c *KeyController) respondOwnDevice(ctx echo.Context, status int, key *entity.DeviceKey) error {
+	remaining, err := c.repo.CountPreKeys(ctx.Request().Context(), []int64{key.ID})
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to count prekeys"})
+	}
+
+	resp := dto.FromDeviceKeyEntity(key)
+	count := remaining[key.ID]
+	resp.RemainingPreKeys = &count
+	return ctx.JSON(status, dto.WebResponse[dto.DeviceKeyResponse]{Data: resp})
+}
+
+// notifyKeyChange tells every member of the user's encrypted conversations
+// that the user's keys changed so their clients can re-verify them.
+func (c *KeyController) notifyKeyChange(ctx context.Context, userID int64) error {
+	peers, err := c.convRepo.FindEncryptedPeers(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	notifs := make([]*entity.Notification, 0, len(peers))
+	for _, p := range peers {
+		conversationID := p.ConversationID
+		sender := userID
+		notifs = append(notifs, &entity.Notification{
+			RecipientID:    p.UserID,
+			SenderID:       &sender,
+			Type:           entity.NotificationTypeKeyChange,
+			ConversationID: &conversationID,
+		})
+	}
+	return c.notifRepo.CreateMany(ctx, notifs)
+}
+
+func toPreKeys(reqs []preKeyReq) []*entity.PreKey {
+	keys := make([]*entity.PreKey, 0, len(reqs))
+	for _, r := range reqs {
+		keys = append(keys, &entity.PreKey{KeyID: r.KeyID, PublicKey: r.PublicKey})
+	}
+	return keys
+}
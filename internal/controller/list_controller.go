@@ -0,0 +1,641 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"TwClone/internal/dto"
+	"TwClone/internal/entity"
+	"TwClone/internal/middleware"
+	"TwClone/internal/pkg/utils/pageutils"
+	"TwClone/internal/pkg/utils/signutils"
+	"TwClone/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultListsLimit = 20
+	maxListsLimit     = 100
+	maxListsPerUser   = 1000
+	maxListMembers    = 5000
+)
+
+// ListController manages lists: curated groups of accounts with their own
+// timeline. Private lists are only visible to their owner; public lists can
+// be seen and subscribed to by anyone not blocked by or blocking the owner.
+type ListController struct {
+	tweetPresenter *tweetPresenter
+	userPresenter  *userPresenter
+	repo           repository.ListRepositoryImpl
+	userRepo       repository.UserRepositoryImpl
+	blockRepo      repository.BlockRepositoryImpl
+}
+
+func NewListController(signer signutils.MediaURLSigner) *ListController {
+	return &ListController{
+		tweetPresenter: newTweetPresenter(signer),
+		userPresenter:  newUserPresenter(),
+		repo:           repository.ListRepositoryImpl{},
+		userRepo:       repository.UserRepositoryImpl{},
+		blockRepo:      repository.BlockRepositoryImpl{},
+	}
+}
+
+func (c *ListController) Route(g *echo.Group) {
+	lg := g.Group("/lists", middleware.AuthMiddleware())
+	lg.POST("", c.Create)
+	lg.GET("", c.FindAll)
+	lg.GET("/subscriptions", c.Subscriptions)
+	lg.GET("/memberships", c.Memberships)
+	lg.GET("/:id", c.FindByID)
+	lg.PATCH("/:id", c.Update)
+	lg.DELETE("/:id", c.Delete)
+	lg.GET("/:id/timeline", c.Timeline)
+	lg.GET("/:id/members", c.Members)
+	lg.POST("/:id/members/:user_id", c.AddMember)
+	lg.DELETE("/:id/members/:user_id", c.RemoveMember)
+	lg.GET("/:id/subscribers", c.Subscribers)
+	lg.POST("/:id/subscribers", c.Subscribe)
+	lg.DELETE("/:id/subscribers", c.Unsubscribe)
+}
+
+type listReq struct {
+	Name        string `json:"name" validate:"required,max=25"`
+	Description string `json:"description" validate:"max=100"`
+	Private     bool   `json:"private"`
+}
+
+// CreateList godoc
+// @Summary Create list
+// @Description Create a public or private list
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Param list body listReq true "List payload"
+// @Success 201 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/lists [post]
+func (c *ListController) Create(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	req, ok := c.bindList(ctx)
+	if !ok {
+		return nil
+	}
+
+	reqCtx := ctx.Request().Context()
+	count, err := c.repo.CountByOwner(reqCtx, userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to count lists"})
+	}
+	if count >= maxListsPerUser {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "list limit reached"})
+	}
+
+	list := entity.List{OwnerID: userID, Name: req.Name, Description: req.Description, Private: req.Private}
+	if err := c.repo.Create(reqCtx, &list); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to create list"})
+	}
+	return ctx.JSON(http.StatusCreated, dto.WebResponse[any]{Data: list})
+}
+
+// ListLists godoc
+// @Summary List a user's lists
+// @Description List the lists a user owns, newest first. Private lists are only included for your own.
+// @Tags lists
+// @Produce json
+// @Param user_id query int false "User ID, defaults to you"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Router /api/v1/lists [get]
+func (c *ListController) FindAll(ctx echo.Context) error {
+	return c.lists(ctx, func(reqCtx context.Context, viewerID, userID, beforeID int64, limit int) ([]*entity.List, error) {
+		return c.repo.FindByOwner(reqCtx, userID, userID == viewerID, beforeID, limit)
+	})
+}
+
+// ListListMemberships godoc
+// @Summary List a user's memberships
+// @Description List the public lists a user is a member of, plus any of your own lists they are on, newest first
+// @Tags lists
+// @Produce json
+// @Param user_id query int false "User ID, defaults to you"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Router /api/v1/lists/memberships [get]
+func (c *ListController) Memberships(ctx echo.Context) error {
+	return c.lists(ctx, func(reqCtx context.Context, viewerID, userID, beforeID int64, limit int) ([]*entity.List, error) {
+		return c.repo.FindMemberships(reqCtx, userID, viewerID, beforeID, limit)
+	})
+}
+
+// ListListSubscriptions godoc
+// @Summary List subscribed lists
+// @Description List the lists you subscribe to, newest first
+// @Tags lists
+// @Produce json
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/lists/subscriptions [get]
+func (c *ListController) Subscriptions(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	beforeID, err := pageutils.DecodeCursor(ctx.QueryParam("cursor"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid cursor"})
+	}
+	limit := pageutils.ParseLimit(ctx.QueryParam("limit"), defaultListsLimit, maxListsLimit)
+
+	lists, err := c.repo.FindSubscribed(ctx.Request().Context(), userID, beforeID, limit)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch lists"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: lists, Cursor: listsCursor(lists, limit)})
+}
+
+// GetList godoc
+// @Summary Get list
+// @Description Get a list
+// @Tags lists
+// @Produce json
+// @Param id path int true "List ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/lists/{id} [get]
+func (c *ListController) FindByID(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	list, ok := c.visibleList(ctx, userID)
+	if !ok {
+		return nil
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: list})
+}
+
+// UpdateList godoc
+// @Summary Update list
+// @Description Change the name, description or privacy of one of your lists. Making a list private removes its subscribers.
+// @Tags lists
+// @Accept json
+// @Produce json
+// @Param id path int true "List ID"
+// @Param list body listReq true "List payload"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/lists/{id} [patch]
+func (c *ListController) Update(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	list, ok := c.ownList(ctx, userID)
+	if !ok {
+		return nil
+	}
+	req, ok := c.bindList(ctx)
+	if !ok {
+		return nil
+	}
+
+	list.Name = req.Name
+	list.Description = req.Description
+	list.Private = req.Private
+	if err := c.repo.Update(ctx.Request().Context(), list); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to update list"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: list})
+}
+
+// DeleteList godoc
+// @Summary Delete list
+// @Description Delete one of your lists
+// @Tags lists
+// @Produce json
+// @Param id path int true "List ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/lists/{id} [delete]
+func (c *ListController) Delete(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	if err := c.repo.Delete(ctx.Request().Context(), id, userID); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "list not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to delete list"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "list deleted"})
+}
+
+// GetListTimeline godoc
+// @Summary Get list timeline
+// @Description List the latest tweets and retweets of a list's members, newest first
+// @Tags lists
+// @Produce json
+// @Param id path int true "List ID"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/lists/{id}/timeline [get]
+func (c *ListController) Timeline(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	beforeID, err := pageutils.DecodeCursor(ctx.QueryParam("cursor"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid cursor"})
+	}
+	limit := pageutils.ParseLimit(ctx.QueryParam("limit"), defaultTweetsLimit, maxTweetsLimit)
+	list, ok := c.visibleList(ctx, userID)
+	if !ok {
+		return nil
+	}
+
+	tweets, err := c.repo.FindTimeline(ctx.Request().Context(), list.ID, userID, beforeID, limit)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweets"})
+	}
+	resp, err := c.tweetPresenter.build(ctx, tweets)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet media"})
+	}
+
+	cursor := &dto.CursorMetaData{Limit: limit}
+	if len(tweets) == limit {
+		cursor.NextCursor = pageutils.EncodeCursor(tweets[len(tweets)-1].ID)
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp, Cursor: cursor})
+}
+
+// GetListMembers godoc
+// @Summary Get list members
+// @Description List the members of a list, most recently added first
+// @Tags lists
+// @Produce json
+// @Param id path int true "List ID"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/lists/{id}/members [get]
+func (c *ListController) Members(ctx echo.Context) error {
+	return c.users(ctx, c.repo.FindMemberPage)
+}
+
+// GetListSubscribers godoc
+// @Summary Get list subscribers
+// @Description List the subscribers of a list, most recently subscribed first
+// @Tags lists
+// @Produce json
+// @Param id path int true "List ID"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/lists/{id}/subscribers [get]
+func (c *ListController) Subscribers(ctx echo.Context) error {
+	return c.users(ctx, c.repo.FindSubscriberPage)
+}
+
+// AddListMember godoc
+// @Summary Add list member
+// @Description Add a user to one of your lists. Users are notified when added to a public list. You cannot add users you block or who block you.
+// @Tags lists
+// @Produce json
+// @Param id path int true "List ID"
+// @Param user_id path int true "User ID"
+// @Success 201 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Failure 409 {object} dto.WebResponse
+// @Router /api/v1/lists/{id}/members/{user_id} [post]
+func (c *ListController) AddMember(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	memberID, err := strconv.ParseInt(ctx.Param("user_id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid user id"})
+	}
+	list, ok := c.ownList(ctx, userID)
+	if !ok {
+		return nil
+	}
+
+	reqCtx := ctx.Request().Context()
+	member, err := c.userRepo.FindByID(reqCtx, memberID)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "user not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch user"})
+	}
+	blocked, err := c.blockRepo.ExistsEither(reqCtx, userID, member.ID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to check blocks"})
+	}
+	if blocked {
+		return ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "you cannot add this user to a list"})
+	}
+	if list.MemberCount >= maxListMembers {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "list member limit reached"})
+	}
+
+	if err := c.repo.AddMember(reqCtx, list, member.ID); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return ctx.JSON(http.StatusConflict, dto.WebResponse[any]{Message: "user is already a member"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to add member"})
+	}
+	return ctx.JSON(http.StatusCreated, dto.WebResponse[any]{Message: "member added"})
+}
+
+// RemoveListMember godoc
+// @Summary Remove list member
+// @Description Remove a user from one of your lists
+// @Tags lists
+// @Produce json
+// @Param id path int true "List ID"
+// @Param user_id path int true "User ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/lists/{id}/members/{user_id} [delete]
+func (c *ListController) RemoveMember(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	memberID, err := strconv.ParseInt(ctx.Param("user_id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid user id"})
+	}
+	list, ok := c.ownList(ctx, userID)
+	if !ok {
+		return nil
+	}
+
+	if err := c.repo.RemoveMember(ctx.Request().Context(), list.ID, memberID); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "user is not a member"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to remove member"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "member removed"})
+}
+
+// SubscribeList godoc
+// @Summary Subscribe to list
+// @Description Subscribe to someone else's public list
+// @Tags lists
+// @Produce json
+// @Param id path int true "List ID"
+// @Success 201 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Failure 409 {object} dto.WebResponse
+// @Router /api/v1/lists/{id}/subscribers [post]
+func (c *ListController) Subscribe(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	list, ok := c.visibleList(ctx, userID)
+	if !ok {
+		return nil
+	}
+	if list.OwnerID == userID {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "you cannot subscribe to your own list"})
+	}
+
+	if err := c.repo.Subscribe(ctx.Request().Context(), list.ID, userID); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return ctx.JSON(http.StatusConflict, dto.WebResponse[any]{Message: "already subscribed"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to subscribe to list"})
+	}
+	return ctx.JSON(http.StatusCreated, dto.WebResponse[any]{Message: "subscribed"})
+}
+
+// UnsubscribeList godoc
+// @Summary Unsubscribe from list
+// @Description Unsubscribe from a list
+// @Tags lists
+// @Produce json
+// @Param id path int true "List ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/lists/{id}/subscribers [delete]
+func (c *ListController) Unsubscribe(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	if err := c.repo.Unsubscribe(ctx.Request().Context(), id, userID); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "not subscribed"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to unsubscribe from list"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "unsubscribed"})
+}
+
+// lists serves a page of the lists find returns for the user in the
+// user_id query parameter, or the viewer when it is missing.
+func (c *ListController) lists(ctx echo.Context, find func(reqCtx context.Context, viewerID, userID, beforeID int64, limit int) ([]*entity.List, error)) error {
+	viewerID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	userID := viewerID
+	if raw := ctx.QueryParam("user_id"); raw != "" {
+		id, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid user id"})
+		}
+		userID = id
+	}
+	beforeID, err := pageutils.DecodeCursor(ctx.QueryParam("cursor"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid cursor"})
+	}
+	limit := pageutils.ParseLimit(ctx.QueryParam("limit"), defaultListsLimit, maxListsLimit)
+
+	reqCtx := ctx.Request().Context()
+	if userID != viewerID {
+		blocked, err := c.blockRepo.ExistsEither(reqCtx, viewerID, userID)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to check blocks"})
+		}
+		if blocked {
+			return ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "you cannot see this user's lists"})
+		}
+	}
+
+	lists, err := find(reqCtx, viewerID, userID, beforeID, limit)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch lists"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: lists, Cursor: listsCursor(lists, limit)})
+}
+
+// users serves a page of the members or subscribers of the list in the id
+// path parameter, as found by find.
+func (c *ListController) users(ctx echo.Context, find func(ctx context.Context, listID int64, cursorTime time.Time, cursorUserID int64, limit int) ([]*repository.ListedUser, error)) error {
+	viewerID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	cursorTime, cursorUserID, err := pageutils.DecodeTimeCursor(ctx.QueryParam("cursor"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid cursor"})
+	}
+	limit := pageutils.ParseLimit(ctx.QueryParam("limit"), defaultUsersLimit, maxUsersLimit)
+	list, ok := c.visibleList(ctx, viewerID)
+	if !ok {
+		return nil
+	}
+
+	reqCtx := ctx.Request().Context()
+	page, err := find(reqCtx, list.ID, cursorTime, cursorUserID, limit)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch users"})
+	}
+	users := make([]*entity.User, 0, len(page))
+	for _, u := range page {
+		users = append(users, &u.User)
+	}
+	resp, err := c.userPresenter.build(reqCtx, users, viewerID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch users"})
+	}
+
+	cursor := &dto.CursorMetaData{Limit: limit}
+	if len(page) == limit {
+		last := page[len(page)-1]
+		cursor.NextCursor = pageutils.EncodeTimeCursor(last.JoinedAt, last.ID)
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp, Cursor: cursor})
+}
+
+// visibleList fetches the list in the id path parameter if the viewer may
+// see it: private lists are only found by their owner, and public lists are
+// hidden from anyone on either side of a block with the owner. When ok is
+// false the error response has already been written.
+func (c *ListController) visibleList(ctx echo.Context, viewerID int64) (*entity.List, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+		return nil, false
+	}
+
+	reqCtx := ctx.Request().Context()
+	list, err := c.repo.FindByID(reqCtx, id)
+	if err == nil && list.Private && list.OwnerID != viewerID {
+		err = repository.ErrRecordNotFound
+	}
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "list not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch list"})
+		}
+		return nil, false
+	}
+	if list.OwnerID == viewerID {
+		return list, true
+	}
+
+	blocked, err := c.blockRepo.ExistsEither(reqCtx, viewerID, list.OwnerID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to check blocks"})
+		return nil, false
+	}
+	if blocked {
+		ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "you cannot see this list"})
+		return nil, false
+	}
+	return list, true
+}
+
+// ownList fetches the list in the id path parameter and checks that the
+// viewer owns it. When ok is false the error response has already been
+// written.
+func (c *ListController) ownList(ctx echo.Context, viewerID int64) (*entity.List, bool) {
+	list, ok := c.visibleList(ctx, viewerID)
+	if !ok {
+		return nil, false
+	}
+	if list.OwnerID != viewerID {
+		ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "you do not own this list"})
+		return nil, false
+	}
+	return list, true
+}
+
+// bindList reads a list's name, description and privacy from the request
+// body. When ok is false the error response has already been written.
+func (c *ListController) bindList(ctx echo.Context) (*listReq, bool) {
+	var req listReq
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "listReq")})
+		return nil, false
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.Description = strings.TrimSpace(req.Description)
+	if err := ctx.Validate(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "listReq")})
+		return nil, false
+	}
+	return &req, true
+}
+
+func listsCursor(lists []*entity.List, limit int) *dto.CursorMetaData {
+	cursor := &dto.CursorMetaData{Limit: limit}
+	if len(lists) == limit {
+		cursor.NextCursor = pageutils.EncodeCursor(lists[len(lists)-1].ID)
+	}
+	return cursor
+}
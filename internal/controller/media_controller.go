@@ -1,87 +1,581 @@
 package controller
 
 import (
-	"TwClone/internal/dto"
-	"TwClone/internal/entity"
-	"TwClone/internal/repository"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
+
+	"TwClone/internal/config"
+	"TwClone/internal/dto"
+	"TwClone/internal/entity"
+	"TwClone/internal/middleware"
+	"TwClone/internal/pkg/storage"
+	"TwClone/internal/pkg/utils/signutils"
+	"TwClone/internal/repository"
+	"TwClone/internal/usecase"
 
+	"github.com/gabriel-vasile/mimetype"
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	megabyte = 1 << 20
+	// multipartOverhead is allowed on top of the largest upload for the
+	// multipart boundaries, headers and metadata fields.
+	multipartOverhead = 1 * megabyte
+)
+
+// allowedMediaTypes maps the sniffed MIME type of an upload to its media type.
+// WebP is not accepted since images must be decoded to strip their metadata
+// and the standard library has no WebP decoder.
+var allowedMediaTypes = map[string]string{
+	"image/jpeg":      entity.MediaTypeImage,
+	"image/png":       entity.MediaTypeImage,
+	"image/gif":       entity.MediaTypeGif,
+	"video/mp4":       entity.MediaTypeVideo,
+	"video/quicktime": entity.MediaTypeVideo,
+}
+
 type MediaController struct {
-	repo repository.MediaRepositoryImpl
+	cfg        *config.MediaConfig
+	store      storage.Storage
+	blobs      *usecase.MediaBlobStore
+	processor  *usecase.MediaProcessor
+	signer     signutils.MediaURLSigner
+	repo       repository.MediaRepositoryImpl
+	userRepo   repository.UserRepositoryImpl
+	tweetRepo  repository.TweetRepositoryImpl
+	followRepo repository.FollowRepositoryImpl
+	blockRepo  repository.BlockRepositoryImpl
+	msgRepo    repository.MessageRepositoryImpl
 }
 
-func NewMediaController() *MediaController {
-	return &MediaController{repo: repository.MediaRepositoryImpl{}}
+func NewMediaController(cfg *config.MediaConfig, store storage.Storage, blobs *usecase.MediaBlobStore, processor *usecase.MediaProcessor, signer signutils.MediaURLSigner) *MediaController {
+	return &MediaController{
+		cfg:        cfg,
+		store:      store,
+		blobs:      blobs,
+		processor:  processor,
+		signer:     signer,
+		repo:       repository.MediaRepositoryImpl{},
+		userRepo:   repository.UserRepositoryImpl{},
+		tweetRepo:  repository.TweetRepositoryImpl{},
+		followRepo: repository.FollowRepositoryImpl{},
+		blockRepo:  repository.BlockRepositoryImpl{},
+		msgRepo:    repository.MessageRepositoryImpl{},
+	}
 }
 
 func (c *MediaController) Route(g *echo.Group) {
 	mg := g.Group("/media")
-	mg.POST("", c.Create)
-	mg.GET("/tweet/:tweet_id", c.ByTweet)
-	mg.GET("/:id", c.ByID)
+	mg.POST("", c.Create, middleware.AuthMiddleware())
+	mg.PATCH("/:id", c.Update, middleware.AuthMiddleware())
+	mg.GET("/tweet/:tweet_id", c.ByTweet, middleware.OptionalAuthMiddleware())
+	mg.GET("/raw/:id", c.Raw, middleware.OptionalAuthMiddleware())
+	mg.GET("/:id", c.ByID, middleware.OptionalAuthMiddleware())
 }
 
-func (c *MediaController) Create(ctx echo.Context) error {
-	var media entity.Media
-	if err := ctx.Bind(&media); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "Media")})
+// mediaMetadataReq carries the accessibility and sensitivity metadata of an
+// upload. It is sent as form fields on upload and as JSON on update.
+type mediaMetadataReq struct {
+	AltText        *string `json:"alt_text" form:"alt_text" validate:"omitempty,max=1000"`
+	Sensitive      *bool   `json:"sensitive" form:"sensitive"`
+	ContentWarning *string `json:"content_warning" form:"content_warning" validate:"omitempty,oneof=nudity violence sensitive"`
+}
+
+// apply copies the metadata onto media. A content warning always marks the
+// media as sensitive; clearing the sensitive flag drops the warning.
+func (r *mediaMetadataReq) apply(m *entity.Media) {
+	if r.AltText != nil {
+		m.AltText = strings.TrimSpace(*r.AltText)
 	}
-	if err := c.repo.Create(context.Background(), &media); err != nil {
-		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: err.Error()})
+	if r.Sensitive != nil {
+		m.Sensitive = *r.Sensitive
+		if !m.Sensitive {
+			m.ContentWarning = ""
+		}
+	}
+	if r.ContentWarning != nil {
+		m.ContentWarning = *r.ContentWarning
+		if m.ContentWarning != "" {
+			m.Sensitive = true
+		}
 	}
-	return ctx.JSON(http.StatusCreated, media)
 }
 
 // CreateMedia godoc
-// @Summary Create media
-// @Description Upload or register media for a tweet
+// @Summary Upload media
+// @Description Upload an image (jpeg, png), gif or video (mp4, mov) as multipart/form-data. The file type is detected from its content. The returned media id can be attached to a tweet or a direct message right away. Images and gifs are then processed in the background (metadata stripping, resized variants, blurhash); poll GET /media/{id} until status is ready.
 // @Tags media
-// @Accept json
+// @Accept multipart/form-data
 // @Produce json
-// @Param media body entity.Media true "Media payload"
-// @Success 201 {object} entity.Media
+// @Param file formData file true "Media file"
+// @Param alt_text formData string false "Alt text (max 1000 characters)"
+// @Param sensitive formData bool false "Mark the media as sensitive"
+// @Param content_warning formData string false "Content warning: nudity, violence or sensitive"
+// @Success 201 {object} dto.WebResponse
 // @Failure 400 {object} dto.WebResponse
+// @Failure 413 {object} dto.WebResponse
+// @Failure 415 {object} dto.WebResponse
 // @Router /api/v1/media [post]
+func (c *MediaController) Create(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	// cut off oversized bodies while they are read, before the multipart
+	// form is spooled to memory or disk
+	maxBody := max(c.cfg.MaxImageSizeMB, c.cfg.MaxGifSizeMB, c.cfg.MaxVideoSizeMB)*megabyte + multipartOverhead
+	ctx.Request().Body = http.MaxBytesReader(ctx.Response(), ctx.Request().Body, maxBody)
+
+	var req mediaMetadataReq
+	if err := ctx.Bind(&req); err != nil {
+		if isBodyTooLarge(err) {
+			return ctx.JSON(http.StatusRequestEntityTooLarge, dto.WebResponse[any]{Message: fmt.Sprintf("uploads are limited to %d MB", maxBody/megabyte)})
+		}
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "mediaMetadataReq")})
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "mediaMetadataReq")})
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		if isBodyTooLarge(err) {
+			return ctx.JSON(http.StatusRequestEntityTooLarge, dto.WebResponse[any]{Message: fmt.Sprintf("uploads are limited to %d MB", maxBody/megabyte)})
+		}
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "file is required"})
+	}
+	file, err := header.Open()
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "failed to read file"})
+	}
+	defer file.Close()
+
+	mime, err := mimetype.DetectReader(file)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "failed to read file"})
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to read file"})
+	}
+
+	mediaType, ok := allowedMediaTypes[mime.String()]
+	if !ok {
+		return ctx.JSON(http.StatusUnsupportedMediaType, dto.WebResponse[any]{Message: fmt.Sprintf("unsupported media type %s", mime.String())})
+	}
+	if limit := c.maxSize(mediaType); header.Size > limit {
+		return ctx.JSON(http.StatusRequestEntityTooLarge, dto.WebResponse[any]{Message: fmt.Sprintf("%s uploads are limited to %d MB", mediaType, limit/megabyte)})
+	}
+
+	reqCtx := ctx.Request().Context()
+	used, err := c.repo.SumSizeByUser(reqCtx, userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to check storage quota"})
+	}
+	if used+header.Size > c.cfg.UserQuotaMB*megabyte {
+		return ctx.JSON(http.StatusRequestEntityTooLarge, dto.WebResponse[any]{Message: "media storage quota exceeded"})
+	}
+
+	// identical files share one blob; the media row takes a reference on it
+	blob, err := c.blobs.Save(reqCtx, file, header.Size, mime.String())
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to store file"})
+	}
+
+	media := entity.Media{
+		UserID:     userID,
+		MediaType:  mediaType,
+		MimeType:   mime.String(),
+		Size:       blob.Size,
+		BlobHash:   blob.Hash,
+		StorageKey: blob.StorageKey,
+		URL:        c.blobs.URL(blob),
+		Status:     entity.MediaStatusReady,
+	}
+	if mediaType != entity.MediaTypeVideo {
+		media.Status = entity.MediaStatusProcessing
+	}
+	req.apply(&media)
+	if err := c.repo.Create(reqCtx, &media); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to save media"})
+	}
+	if media.Status == entity.MediaStatusProcessing {
+		c.processor.Enqueue(media.ID)
+	}
+
+	return ctx.JSON(http.StatusCreated, dto.WebResponse[any]{Data: dto.FromMediaEntity(&media, userID, "", c.signer)})
+}
+
+// UpdateMedia godoc
+// @Summary Update media metadata
+// @Description Set the alt text, sensitive flag or content warning of one of your uploads
+// @Tags media
+// @Accept json
+// @Produce json
+// @Param id path int true "Media ID"
+// @Param media body mediaMetadataReq true "Metadata payload"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/media/{id} [patch]
+func (c *MediaController) Update(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	var req mediaMetadataReq
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "mediaMetadataReq")})
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "mediaMetadataReq")})
+	}
+
+	reqCtx := ctx.Request().Context()
+	media, err := c.repo.FindByID(reqCtx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "media not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch media"})
+	}
+	if media.UserID != userID {
+		return ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "you can only edit your own media"})
+	}
+
+	req.apply(media)
+	if err := c.repo.UpdateMetadata(reqCtx, media); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to update media"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: dto.FromMediaEntity(media, userID, "", c.signer)})
+}
+
+// isBodyTooLarge reports whether reading the request body failed because it
+// went over the limit set with http.MaxBytesReader.
+func isBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
+}
+
+func (c *MediaController) maxSize(mediaType string) int64 {
+	switch mediaType {
+	case entity.MediaTypeGif:
+		return c.cfg.MaxGifSizeMB * megabyte
+	case entity.MediaTypeVideo:
+		return c.cfg.MaxVideoSizeMB * megabyte
+	default:
+		return c.cfg.MaxImageSizeMB * megabyte
+	}
+}
 
 // GetMediaByTweet godoc
 // @Summary Media by tweet
-// @Description Get media for a tweet
+// @Description Get media for a tweet. Sensitive media is shown, blurred or hidden according to the viewer's preference. Media of protected accounts is only listed for their followers.
 // @Tags media
 // @Accept json
 // @Produce json
 // @Param tweet_id path int true "Tweet ID"
-// @Success 200 {array} entity.Media
+// @Success 200 {array} dto.MediaResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
 // @Router /api/v1/media/tweet/{tweet_id} [get]
+func (c *MediaController) ByTweet(ctx echo.Context) error {
+	tweetID, err := strconv.ParseInt(ctx.Param("tweet_id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid tweet id"})
+	}
+	reqCtx := ctx.Request().Context()
+	medias, err := c.repo.FindByTweetID(reqCtx, tweetID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch media"})
+	}
+	viewerID, preference, err := viewerMediaPreference(ctx, c.userRepo)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch viewer"})
+	}
+
+	visible := make([]*entity.Media, 0, len(medias))
+	for _, m := range medias {
+		allowed, err := c.canView(reqCtx, m, viewerID)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to check media access"})
+		}
+		if allowed {
+			visible = append(visible, m)
+		}
+	}
+	if len(medias) > 0 && len(visible) == 0 {
+		return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "media not found"})
+	}
+	return ctx.JSON(http.StatusOK, dto.FromMediaEntities(visible, viewerID, preference, c.signer))
+}
 
 // GetMediaByID godoc
 // @Summary Get media
-// @Description Get media by id
+// @Description Get media by id, including its processing status (processing, ready or failed), dimensions, blurhash and resized variants. Media is only returned to those who may view it, as for the raw file.
 // @Tags media
 // @Accept json
 // @Produce json
 // @Param id path int true "Media ID"
-// @Success 200 {object} entity.Media
+// @Success 200 {object} dto.MediaResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
 // @Router /api/v1/media/{id} [get]
+func (c *MediaController) ByID(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+	reqCtx := ctx.Request().Context()
+	media, err := c.repo.FindByID(reqCtx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "media not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch media"})
+	}
+	viewerID, preference, err := viewerMediaPreference(ctx, c.userRepo)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch viewer"})
+	}
 
-func (c *MediaController) ByTweet(ctx echo.Context) error {
-	tweetID, _ := strconv.ParseInt(ctx.Param("tweet_id"), 10, 64)
-	media, err := c.repo.FindByTweetID(context.Background(), tweetID)
+	// media the viewer may not see is reported as missing, not forbidden,
+	// so that its existence does not leak
+	allowed, err := c.canView(reqCtx, media, viewerID)
 	if err != nil {
-		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: err.Error()})
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to check media access"})
+	}
+	if !allowed {
+		return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "media not found"})
 	}
-	return ctx.JSON(http.StatusOK, media)
+	return ctx.JSON(http.StatusOK, dto.FromMediaEntity(media, viewerID, preference, c.signer))
 }
 
-func (c *MediaController) ByID(ctx echo.Context) error {
-	id, _ := strconv.ParseInt(ctx.Param("id"), 10, 64)
-	media, err := c.repo.FindByID(context.Background(), id)
+// GetRawMedia godoc
+// @Summary Stream media file
+// @Description Stream a media file or one of its variants. Only signed, unexpired URLs handed out in media and tweet responses are accepted. Media of protected accounts is only served to their followers and media sent in direct messages only to conversation members, identified by the access token header or cookie. Supports Range and If-None-Match requests.
+// @Tags media
+// @Produce octet-stream
+// @Param id path int true "Media ID"
+// @Param variant query string false "Variant name: thumbnail, small or large"
+// @Param expires query int true "Expiry time (unix seconds)"
+// @Param sig query string true "URL signature"
+// @Success 200 {file} file
+// @Success 206 {file} file
+// @Success 304
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Failure 416 {object} dto.WebResponse
+// @Router /api/v1/media/raw/{id} [get]
+func (c *MediaController) Raw(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+	variant := ctx.QueryParam("variant")
+	if err := c.signer.Verify(id, variant, ctx.QueryParam("expires"), ctx.QueryParam("sig")); err != nil {
+		if errors.Is(err, signutils.ErrExpiredSignature) {
+			return ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "media link expired"})
+		}
+		return ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "invalid media link"})
+	}
+
+	reqCtx := ctx.Request().Context()
+	media, err := c.repo.FindByID(reqCtx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "media not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch media"})
+	}
+
+	viewerID, _ := currentUserID(ctx)
+	allowed, err := c.canView(reqCtx, media, viewerID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to check media access"})
+	}
+	if !allowed {
+		return ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "you cannot view this media"})
+	}
+
+	key, mimeType, size := media.StorageKey, media.MimeType, media.Size
+	if variant != "" {
+		found := false
+		for _, v := range media.Variants {
+			if v.Name == variant {
+				key, mimeType, size, found = v.StorageKey, v.MimeType, v.Size, true
+				break
+			}
+		}
+		if !found {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "media variant not found"})
+		}
+	}
+
+	sum := sha256.Sum256([]byte(key))
+	etag := fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(sum[:8]), size)
+	header := ctx.Response().Header()
+	header.Set("ETag", etag)
+	header.Set("Accept-Ranges", "bytes")
+	header.Set("Cache-Control", "private, max-age=3600")
+	if etagMatches(ctx.Request().Header.Get("If-None-Match"), etag) {
+		return ctx.NoContent(http.StatusNotModified)
+	}
+
+	status := http.StatusOK
+	offset, length := int64(0), size
+	rangeHeader := ctx.Request().Header.Get("Range")
+	ifRange := ctx.Request().Header.Get("If-Range")
+	if rangeHeader != "" && (ifRange == "" || ifRange == etag) {
+		start, n, ok := parseByteRange(rangeHeader, size)
+		if !ok {
+			header.Set("Content-Range", fmt.Sprintf("bytes */%d", size))
+			return ctx.JSON(http.StatusRequestedRangeNotSatisfiable, dto.WebResponse[any]{Message: "invalid range"})
+		}
+		if n < size {
+			status = http.StatusPartialContent
+			offset, length = start, n
+			header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, start+n-1, size))
+		}
+	}
+
+	body, err := c.store.GetRange(reqCtx, key, offset, length)
+	if err != nil {
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "media file not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to read media file"})
+	}
+	defer body.Close()
+
+	header.Set("Content-Length", strconv.FormatInt(length, 10))
+	return ctx.Stream(status, mimeType, body)
+}
+
+// canView decides whether a viewer, possibly anonymous (zero), may fetch a
+// media file. Uploaders always can. Anyone else only sees processed files:
+// media of public tweets, of protected accounts they follow, or sent in a
+// conversation they are a member of, and never media of users blocking or
+// blocked by them.
+func (c *MediaController) canView(ctx context.Context, media *entity.Media, viewerID int64) (bool, error) {
+	if viewerID != 0 && media.UserID == viewerID {
+		return true, nil
+	}
+	if media.Status != entity.MediaStatusReady {
+		return false, nil
+	}
+	if viewerID != 0 {
+		blocked, err := c.blockRepo.ExistsEither(ctx, viewerID, media.UserID)
+		if err != nil || blocked {
+			return false, err
+		}
+	}
+
+	if media.TweetID != nil {
+		author, err := c.userRepo.FindByID(ctx, media.UserID)
+		if err != nil {
+			return false, err
+		}
+		if !author.Protected {
+			return true, nil
+		}
+		if viewerID == 0 {
+			return false, nil
+		}
+		return c.followRepo.Exists(ctx, viewerID, author.ID)
+	}
+
+	if viewerID == 0 {
+		return false, nil
+	}
+	return c.msgRepo.MediaVisibleTo(ctx, media.ID, viewerID)
+}
+
+// etagMatches reports whether an If-None-Match header matches etag.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// parseByteRange parses a single-range Range header against a file of the
+// given size and returns the first byte and the number of bytes to send.
+// Multi-range requests are answered with the whole file.
+func parseByteRange(header string, size int64) (int64, int64, bool) {
+	spec, ok := strings.CutPrefix(header, "bytes=")
+	if !ok {
+		return 0, size, true
+	}
+	if strings.Contains(spec, ",") {
+		return 0, size, true
+	}
+
+	first, last, ok := strings.Cut(strings.TrimSpace(spec), "-")
+	if !ok {
+		return 0, 0, false
+	}
+
+	// suffix range: the last N bytes
+	if first == "" {
+		n, err := strconv.ParseInt(last, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, 0, false
+		}
+		n = min(n, size)
+		return size - n, n, true
+	}
+
+	start, err := strconv.ParseInt(first, 10, 64)
+	if err != nil || start < 0 || start >= size {
+		return 0, 0, false
+	}
+	end := size - 1
+	if last != "" {
+		end, err = strconv.ParseInt(last, 10, 64)
+		if err != nil || end < start {
+			return 0, 0, false
+		}
+		end = min(end, size-1)
+	}
+	return start, end - start + 1, true
+}
+
+// viewerMediaPreference returns the current user, if any, and their
+// sensitive media preference. Anonymous viewers get the default.
+func viewerMediaPreference(ctx echo.Context, userRepo repository.UserRepositoryImpl) (int64, string, error) {
+	viewerID, ok := currentUserID(ctx)
+	if !ok {
+		return 0, "", nil
+	}
+
+	viewer, err := userRepo.FindByID(ctx.Request().Context(), viewerID)
 	if err != nil {
-		return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "not found"})
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return 0, "", nil
+		}
+		return 0, "", err
 	}
-	return ctx.JSON(http.StatusOK, media)
+	return viewerID, viewer.SensitiveMedia, nil
 }
@@ -2,7 +2,6 @@ package controller
 
 import (
 	"TwClone/internal/dto"
-	"TwClone/internal/entity"
 	"TwClone/internal/repository"
 	"context"
 	"net/http"
@@ -21,33 +20,10 @@ func NewMentionController() *MentionController {
 
 func (c *MentionController) Route(g *echo.Group) {
 	mg := g.Group("/mentions")
-	mg.POST("", c.Create)
 	mg.GET("/tweet/:tweet_id", c.ByTweet)
 	mg.GET("/user/:user_id", c.ByUser)
 }
 
-func (c *MentionController) Create(ctx echo.Context) error {
-	var mention entity.Mention
-	if err := ctx.Bind(&mention); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "Mention")})
-	}
-	if err := c.repo.Create(context.Background(), &mention); err != nil {
-		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: err.Error()})
-	}
-	return ctx.JSON(http.StatusCreated, mention)
-}
-
-// CreateMention godoc
-// @Summary Create mention
-// @Description Create a mention for a tweet
-// @Tags mentions
-// @Accept json
-// @Produce json
-// @Param mention body entity.Mention true "Mention payload"
-// @Success 201 {object} entity.Mention
-// @Failure 400 {object} dto.WebResponse
-// @Router /api/v1/mentions [post]
-
 // GetMentionsByTweet godoc
 // @Summary Mentions by tweet
 // @Description Get mentions for a tweet
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"TwClone/internal/config"
+	"TwClone/internal/dto"
+	"TwClone/internal/entity"
+	"TwClone/internal/middleware"
+	"TwClone/internal/pkg/utils/pageutils"
+	"TwClone/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultReportsLimit = 20
+	maxReportsLimit     = 100
+)
+
+// ReportController is the moderation queue: moderators review the reports
+// users file and resolve them.
+type ReportController struct {
+	cfg  *config.ModerationConfig
+	repo repository.ReportRepositoryImpl
+}
+
+func NewReportController(cfg *config.ModerationConfig) *ReportController {
+	return &ReportController{
+		cfg:  cfg,
+		repo: repository.ReportRepositoryImpl{},
+	}
+}
+
+func (c *ReportController) Route(g *echo.Group) {
+	rg := g.Group("/reports", middleware.AuthMiddleware())
+	rg.GET("", c.FindAll)
+	rg.POST("/:id/resolve", c.Resolve)
+}
+
+// ListReports godoc
+// @Summary List reports
+// @Description List the reports filed by users, oldest first. Moderators only.
+// @Tags moderation
+// @Produce json
+// @Param status query string false "open (default) or resolved"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Router /api/v1/reports [get]
+func (c *ReportController) FindAll(ctx echo.Context) error {
+	if !c.moderator(ctx) {
+		return nil
+	}
+	status := ctx.QueryParam("status")
+	if status == "" {
+		status = entity.ReportStatusOpen
+	}
+	if status != entity.ReportStatusOpen && status != entity.ReportStatusResolved {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "status must be open or resolved"})
+	}
+	afterID, err := pageutils.DecodeCursor(ctx.QueryParam("cursor"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid cursor"})
+	}
+	limit := pageutils.ParseLimit(ctx.QueryParam("limit"), defaultReportsLimit, maxReportsLimit)
+
+	reports, err := c.repo.FindByStatus(ctx.Request().Context(), status, afterID, limit)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch reports"})
+	}
+
+	cursor := &dto.CursorMetaData{Limit: limit}
+	if len(reports) == limit {
+		cursor.NextCursor = pageutils.EncodeCursor(reports[len(reports)-1].ID)
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: reports, Cursor: cursor})
+}
+
+// ResolveReport godoc
+// @Summary Resolve report
+// @Description Mark an open report as handled. Moderators only.
+// @Tags moderation
+// @Produce json
+// @Param id path int true "Report ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/reports/{id}/resolve [post]
+func (c *ReportController) Resolve(ctx echo.Context) error {
+	if !c.moderator(ctx) {
+		return nil
+	}
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	if err := c.repo.Resolve(ctx.Request().Context(), id); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "open report not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to resolve report"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "resolved"})
+}
+
+// moderator reports whether the current user is a moderator. When it is
+// not, the error response has already been written.
+func (c *ReportController) moderator(ctx echo.Context) bool {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+		return false
+	}
+	if !slices.Contains(c.cfg.ModeratorIDs, userID) {
+		ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "moderators only"})
+		return false
+	}
+	return true
+}
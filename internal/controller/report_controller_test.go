@@ -0,0 +1,59 @@
+package controller
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"TwClone/internal/config"
+	"TwClone/internal/constant"
+	"TwClone/internal/dto"
+)
+
+func TestReportControllerFindAll(t *testing.T) {
+	useFakeDB(t, fakeResult{
+		match:   `FROM "reports"`,
+		columns: []string{"id", "reporter_id", "target_type", "target_id", "reason", "status"},
+		rows:    [][]driver.Value{{int64(7), int64(3), "conversation", int64(9), "spam", "open"}},
+	})
+	c := NewReportController(&config.ModerationConfig{ModeratorIDs: []int64{1}})
+
+	tests := []struct {
+		name   string
+		userID int64
+		want   int
+	}{
+		{"moderator", 1, http.StatusOK},
+		{"other user", 2, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			ctx := newTestEcho().NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/reports", nil), rec)
+			ctx.Set(constant.CTX_USER_ID, tt.userID)
+
+			if err := c.FindAll(ctx); err != nil {
+				t.Fatalf("FindAll() error = %v", err)
+			}
+			if rec.Code != tt.want {
+				t.Fatalf("FindAll() status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want != http.StatusOK {
+				return
+			}
+			var resp dto.WebResponse[[]struct {
+				ID       int64  `json:"id"`
+				TargetID int64  `json:"target_id"`
+				Reason   string `json:"reason"`
+			}]
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("invalid response %q: %v", rec.Body.String(), err)
+			}
+			if len(resp.Data) != 1 || resp.Data[0].ID != 7 || resp.Data[0].TargetID != 9 || resp.Data[0].Reason != "spam" {
+				t.Errorf("FindAll() data = %+v, want report 7", resp.Data)
+			}
+		})
+	}
+}
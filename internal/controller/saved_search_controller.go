@@ -0,0 +1,214 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"TwClone/internal/config"
+	"TwClone/internal/dto"
+	"TwClone/internal/entity"
+	"TwClone/internal/middleware"
+	"TwClone/internal/pkg/utils/searchutils"
+	"TwClone/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// SavedSearchController manages the current user's saved searches and their
+// alert subscriptions.
+type SavedSearchController struct {
+	cfg       *config.SavedSearchConfig
+	repo      repository.SavedSearchRepositoryImpl
+	tweetRepo repository.TweetRepositoryImpl
+}
+
+func NewSavedSearchController(cfg *config.SavedSearchConfig) *SavedSearchController {
+	return &SavedSearchController{
+		cfg:       cfg,
+		repo:      repository.SavedSearchRepositoryImpl{},
+		tweetRepo: repository.TweetRepositoryImpl{},
+	}
+}
+
+func (c *SavedSearchController) Route(g *echo.Group) {
+	sg := g.Group("/saved-searches", middleware.AuthMiddleware())
+	sg.GET("", c.FindAll)
+	sg.POST("", c.Create)
+	sg.PATCH("/:id", c.Update)
+	sg.DELETE("/:id", c.Delete)
+}
+
+type createSavedSearchReq struct {
+	Name       string `json:"name" validate:"max=100"`
+	Query      string `json:"query" validate:"required,max=500"`
+	Subscribed bool   `json:"subscribed"`
+}
+
+type updateSavedSearchReq struct {
+	Name       *string `json:"name" validate:"omitempty,max=100"`
+	Subscribed *bool   `json:"subscribed"`
+}
+
+// ListSavedSearches godoc
+// @Summary List saved searches
+// @Description List your saved searches, oldest first
+// @Tags saved-searches
+// @Produce json
+// @Success 200 {object} dto.WebResponse
+// @Router /api/v1/saved-searches [get]
+func (c *SavedSearchController) FindAll(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	searches, err := c.repo.FindByUser(ctx.Request().Context(), userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch saved searches"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: searches})
+}
+
+// CreateSavedSearch godoc
+// @Summary Save search
+// @Description Save a tweet search, written in the same syntax as GET /search/tweets. Subscribed searches send a search_alert notification when new tweets match, at most once per cooldown; each alert counts the matches since the previous one.
+// @Tags saved-searches
+// @Accept json
+// @Produce json
+// @Param search body createSavedSearchReq true "Saved search payload"
+// @Success 201 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 409 {object} dto.WebResponse
+// @Router /api/v1/saved-searches [post]
+func (c *SavedSearchController) Create(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	var req createSavedSearchReq
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "createSavedSearchReq")})
+	}
+	req.Query = strings.TrimSpace(req.Query)
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "createSavedSearchReq")})
+	}
+	if _, err := searchutils.Parse(req.Query); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid search query"})
+	}
+
+	reqCtx := ctx.Request().Context()
+	count, err := c.repo.CountByUser(reqCtx, userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to count saved searches"})
+	}
+	if count >= int64(c.cfg.MaxPerUser) {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "saved search limit reached"})
+	}
+
+	search := entity.SavedSearch{
+		UserID:     userID,
+		Name:       req.Name,
+		Query:      req.Query,
+		Subscribed: req.Subscribed,
+	}
+	if search.Subscribed {
+		if search.LastMatchedTweetID, err = c.tweetRepo.LatestID(reqCtx); err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to save search"})
+		}
+	}
+	if err := c.repo.Create(reqCtx, &search); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return ctx.JSON(http.StatusConflict, dto.WebResponse[any]{Message: "search already saved"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to save search"})
+	}
+	return ctx.JSON(http.StatusCreated, dto.WebResponse[any]{Data: search})
+}
+
+// UpdateSavedSearch godoc
+// @Summary Update saved search
+// @Description Rename a saved search or change its alert subscription. Subscribing only alerts about tweets posted from then on.
+// @Tags saved-searches
+// @Accept json
+// @Produce json
+// @Param id path int true "Saved search ID"
+// @Param search body updateSavedSearchReq true "Update payload"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/saved-searches/{id} [patch]
+func (c *SavedSearchController) Update(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	var req updateSavedSearchReq
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "updateSavedSearchReq")})
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "updateSavedSearchReq")})
+	}
+
+	reqCtx := ctx.Request().Context()
+	search, err := c.repo.FindByID(reqCtx, id, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "saved search not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch saved search"})
+	}
+
+	if req.Name != nil {
+		search.Name = *req.Name
+	}
+	if req.Subscribed != nil && *req.Subscribed != search.Subscribed {
+		search.Subscribed = *req.Subscribed
+		if search.Subscribed {
+			if search.LastMatchedTweetID, err = c.tweetRepo.LatestID(reqCtx); err != nil {
+				return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to update saved search"})
+			}
+		}
+	}
+	if err := c.repo.Update(reqCtx, search); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to update saved search"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: search})
+}
+
+// DeleteSavedSearch godoc
+// @Summary Delete saved search
+// @Description Delete one of your saved searches
+// @Tags saved-searches
+// @Produce json
+// @Param id path int true "Saved search ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/saved-searches/{id} [delete]
+func (c *SavedSearchController) Delete(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	if err := c.repo.Delete(ctx.Request().Context(), id, userID); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "saved search not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to delete saved search"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "saved search deleted"})
+}
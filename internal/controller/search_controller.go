@@ -0,0 +1,105 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"TwClone/internal/dto"
+	"TwClone/internal/middleware"
+	"TwClone/internal/pkg/utils/pageutils"
+	"TwClone/internal/pkg/utils/searchutils"
+	"TwClone/internal/pkg/utils/signutils"
+	"TwClone/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	searchSortRelevance = "relevance"
+	searchSortRecent    = "recent"
+)
+
+// SearchController serves search.
+type SearchController struct {
+	presenter *tweetPresenter
+	tweetRepo repository.TweetRepositoryImpl
+}
+
+func NewSearchController(signer signutils.MediaURLSigner) *SearchController {
+	return &SearchController{
+		presenter: newTweetPresenter(signer),
+		tweetRepo: repository.TweetRepositoryImpl{},
+	}
+}
+
+func (c *SearchController) Route(g *echo.Group) {
+	sg := g.Group("/search", middleware.OptionalAuthMiddleware())
+	sg.GET("/tweets", c.Tweets)
+}
+
+// SearchTweets godoc
+// @Summary Search tweets
+// @Description Full-text tweet search. Besides words, the query understands "exact phrases", -exclusions, #hashtags, from:username, to:username, since:YYYY-MM-DD, until:YYYY-MM-DD, has:media, filter:replies and min_likes:N; operators can be negated with a leading -. Tweets of users you block, mute or are blocked by are left out.
+// @Tags search
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query"
+// @Param sort query string false "relevance (default) or recent"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/search/tweets [get]
+func (c *SearchController) Tweets(ctx echo.Context) error {
+	q, err := searchutils.Parse(ctx.QueryParam("q"))
+	if err != nil {
+		if errors.Is(err, searchutils.ErrEmptyQuery) {
+			return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "search query must include something to look for"})
+		}
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid search query"})
+	}
+
+	sort := ctx.QueryParam("sort")
+	if sort == "" {
+		sort = searchSortRelevance
+	}
+	if sort != searchSortRelevance && sort != searchSortRecent {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "sort must be relevance or recent"})
+	}
+
+	// relevance pages are addressed by offset, recent ones by the last id
+	position, err := pageutils.DecodeCursor(ctx.QueryParam("cursor"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid cursor"})
+	}
+	limit := pageutils.ParseLimit(ctx.QueryParam("limit"), defaultTweetsLimit, maxTweetsLimit)
+
+	viewerID, _ := currentUserID(ctx)
+	opts := repository.TweetSearchOptions{ViewerID: viewerID, Limit: limit}
+	if sort == searchSortRelevance {
+		opts.ByRelevance = true
+		opts.Offset = int(position)
+	} else {
+		opts.BeforeID = position
+	}
+
+	tweets, err := c.tweetRepo.Search(ctx.Request().Context(), q, opts)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to search tweets"})
+	}
+
+	resp, err := c.presenter.build(ctx, tweets)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet media"})
+	}
+
+	cursor := &dto.CursorMetaData{Limit: limit}
+	if len(tweets) == limit {
+		if opts.ByRelevance {
+			cursor.NextCursor = pageutils.EncodeCursor(int64(opts.Offset + limit))
+		} else {
+			cursor.NextCursor = pageutils.EncodeCursor(tweets[len(tweets)-1].ID)
+		}
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp, Cursor: cursor})
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"TwClone/internal/dto"
+	"TwClone/internal/entity"
+	"TwClone/internal/middleware"
+	"TwClone/internal/pkg/utils/pageutils"
+	"TwClone/internal/repository"
+	"TwClone/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultSuggestionsLimit = 10
+	maxSuggestionsLimit     = 50
+)
+
+// SuggestionController serves who-to-follow suggestions.
+type SuggestionController struct {
+	suggester *usecase.FollowSuggester
+	presenter *userPresenter
+	repo      repository.SuggestionRepositoryImpl
+}
+
+func NewSuggestionController(suggester *usecase.FollowSuggester) *SuggestionController {
+	return &SuggestionController{
+		suggester: suggester,
+		presenter: newUserPresenter(),
+		repo:      repository.SuggestionRepositoryImpl{},
+	}
+}
+
+func (c *SuggestionController) Route(g *echo.Group) {
+	sg := g.Group("/suggestions", middleware.AuthMiddleware())
+	sg.GET("", c.FindAll)
+	sg.DELETE("/:user_id", c.Dismiss)
+}
+
+// ListSuggestions godoc
+// @Summary Who to follow
+// @Description Suggest accounts to follow, best first: accounts followed by many of the accounts you follow, that tweet about the same hashtags as you and tweeted recently. Suggestions are recomputed periodically in the background; a new user gets none until the first computation is done.
+// @Tags suggestions
+// @Produce json
+// @Param limit query int false "Number of suggestions (max 50)"
+// @Success 200 {object} dto.WebResponse
+// @Router /api/v1/suggestions [get]
+func (c *SuggestionController) FindAll(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	limit := pageutils.ParseLimit(ctx.QueryParam("limit"), defaultSuggestionsLimit, maxSuggestionsLimit)
+
+	reqCtx := ctx.Request().Context()
+	suggested, err := c.repo.FindByUser(reqCtx, userID, limit)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch suggestions"})
+	}
+	if len(suggested) == 0 {
+		// nothing computed yet, for instance because the user just followed
+		// their first accounts; they show up on a later request
+		c.suggester.Warm(userID)
+	}
+
+	users := make([]*entity.User, 0, len(suggested))
+	for _, s := range suggested {
+		users = append(users, &s.User)
+	}
+	rendered, err := c.presenter.build(reqCtx, users, userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch suggestions"})
+	}
+	resp := make([]dto.SuggestionResponse, 0, len(suggested))
+	for i, s := range suggested {
+		resp = append(resp, dto.SuggestionResponse{
+			User:           rendered[i],
+			MutualCount:    s.MutualCount,
+			SharedHashtags: s.SharedHashtags,
+		})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp})
+}
+
+// DismissSuggestion godoc
+// @Summary Dismiss suggestion
+// @Description Stop suggesting an account to you
+// @Tags suggestions
+// @Produce json
+// @Param user_id path int true "User ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/suggestions/{user_id} [delete]
+func (c *SuggestionController) Dismiss(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	dismissedID, err := strconv.ParseInt(ctx.Param("user_id"), 10, 64)
+	if err != nil || dismissedID == userID {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid user id"})
+	}
+
+	if err := c.repo.Dismiss(ctx.Request().Context(), userID, dismissedID); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to dismiss suggestion"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "suggestion dismissed"})
+}
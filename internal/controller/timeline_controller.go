@@ -0,0 +1,228 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"TwClone/internal/config"
+	"TwClone/internal/dto"
+	"TwClone/internal/entity"
+	"TwClone/internal/middleware"
+	"TwClone/internal/pkg/utils/pageutils"
+	"TwClone/internal/pkg/utils/signutils"
+	"TwClone/internal/repository"
+	"TwClone/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	timelineModeChronological = "chronological"
+	timelineModeRanked        = "ranked"
+)
+
+// TimelineController serves the home timeline, either chronological or
+// ranked "For You", and takes the "not interested" feedback that shapes
+// both.
+type TimelineController struct {
+	ranker    *usecase.TimelineRanker
+	presenter *tweetPresenter
+	repo      repository.TimelineRepositoryImpl
+	tweetRepo repository.TweetRepositoryImpl
+}
+
+func NewTimelineController(cfg *config.TimelineConfig, signer signutils.MediaURLSigner) *TimelineController {
+	return &TimelineController{
+		ranker:    usecase.NewTimelineRanker(cfg),
+		presenter: newTweetPresenter(signer),
+		repo:      repository.TimelineRepositoryImpl{},
+		tweetRepo: repository.TweetRepositoryImpl{},
+	}
+}
+
+func (c *TimelineController) Route(g *echo.Group) {
+	g.GET("/timeline", c.Home, middleware.AuthMiddleware())
+	g.POST("/tweets/:id/not-interested", c.NotInterested, middleware.AuthMiddleware())
+	g.DELETE("/tweets/:id/not-interested", c.Undo, middleware.AuthMiddleware())
+}
+
+// GetHomeTimeline godoc
+// @Summary Home timeline
+// @Description Your home timeline. The chronological mode (default) lists the latest tweets and retweets of you and the accounts you follow, newest first. The ranked "For You" mode also draws on tweets the accounts you follow liked or retweeted, ordered by your affinity with the author, engagement velocity, social proof, media and recency, and demoting authors you were not interested in; with debug=true each tweet explains its score, and later pages are scored as of the first one so that paging neither repeats nor skips tweets. Tweets you were not interested in are left out of both.
+// @Tags timeline
+// @Produce json
+// @Param mode query string false "chronological (default) or ranked"
+// @Param debug query bool false "Explain the scores of ranked tweets"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/timeline [get]
+func (c *TimelineController) Home(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	mode := ctx.QueryParam("mode")
+	if mode == "" {
+		mode = timelineModeChronological
+	}
+	if mode != timelineModeChronological && mode != timelineModeRanked {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "mode must be chronological or ranked"})
+	}
+	debug, _ := strconv.ParseBool(ctx.QueryParam("debug"))
+	limit := pageutils.ParseLimit(ctx.QueryParam("limit"), defaultTweetsLimit, maxTweetsLimit)
+
+	if mode == timelineModeRanked {
+		return c.ranked(ctx, userID, limit, debug)
+	}
+
+	beforeID, err := pageutils.DecodeCursor(ctx.QueryParam("cursor"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid cursor"})
+	}
+	tweets, err := c.repo.FindHome(ctx.Request().Context(), userID, beforeID, limit)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch timeline"})
+	}
+	resp, err := c.presenter.build(ctx, tweets)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet media"})
+	}
+
+	cursor := &dto.CursorMetaData{Limit: limit}
+	if len(tweets) == limit {
+		cursor.NextCursor = pageutils.EncodeCursor(tweets[len(tweets)-1].ID)
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp, Cursor: cursor})
+}
+
+// ranked serves a page of the ranked timeline, with score explanations in
+// debug mode. The cursor carries when the first page was ranked and the
+// score of the last tweet, so that later pages are ranked the same way and
+// neither repeat nor skip tweets.
+func (c *TimelineController) ranked(ctx echo.Context, userID int64, limit int, debug bool) error {
+	rankedAt, afterScore, afterID, err := pageutils.DecodeScoreCursor(ctx.QueryParam("cursor"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid cursor"})
+	}
+	if rankedAt.IsZero() {
+		// as precise as the cursor keeps it, so every page scores alike
+		rankedAt = time.Now().Truncate(time.Microsecond)
+	}
+
+	ranked, err := c.ranker.Rank(ctx.Request().Context(), userID, rankedAt, afterScore, afterID, limit)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch timeline"})
+	}
+	tweets := make([]*entity.Tweet, 0, len(ranked))
+	for _, r := range ranked {
+		tweets = append(tweets, r.Tweet)
+	}
+	resp, err := c.presenter.build(ctx, tweets)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet media"})
+	}
+
+	cursor := &dto.CursorMetaData{Limit: limit}
+	if len(ranked) == limit {
+		last := ranked[len(ranked)-1]
+		cursor.NextCursor = pageutils.EncodeScoreCursor(rankedAt, last.Score.Score, last.Tweet.ID)
+	}
+	if !debug {
+		return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp, Cursor: cursor})
+	}
+
+	explained := make([]dto.RankedTweetResponse, 0, len(resp))
+	for i, t := range resp {
+		s := ranked[i].Score
+		explained = append(explained, dto.RankedTweetResponse{
+			TweetResponse: t,
+			Ranking: &dto.TimelineScoreResponse{
+				Score:              s.Score,
+				InNetwork:          s.InNetwork,
+				Affinity:           s.Affinity,
+				Interactions:       s.Interactions,
+				Velocity:           s.Velocity,
+				Engagements:        s.Engagements,
+				SocialProof:        s.SocialProof,
+				SocialProofCount:   s.SocialProofCount,
+				Media:              s.Media,
+				NotInterested:      s.NotInterested,
+				NotInterestedCount: s.NotInterestedCount,
+				Recency:            s.Recency,
+				AgeMinutes:         s.AgeMinutes,
+			},
+		})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: explained, Cursor: cursor})
+}
+
+// MarkNotInterested godoc
+// @Summary Not interested
+// @Description Say you are not interested in a tweet. It is left out of your home timeline, and the author's other tweets rank lower in the ranked one. Marking a retweet marks the original.
+// @Tags timeline
+// @Produce json
+// @Param id path int true "Tweet ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/tweets/{id}/not-interested [post]
+func (c *TimelineController) NotInterested(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	reqCtx := ctx.Request().Context()
+	tweet, err := c.tweetRepo.FindVisibleOriginal(reqCtx, id, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "tweet not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet"})
+	}
+	if tweet.UserID == userID {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "you cannot mark your own tweet as not interesting"})
+	}
+
+	dismissal := entity.TweetDismissal{UserID: userID, TweetID: tweet.ID, AuthorID: tweet.UserID}
+	if err := c.repo.Dismiss(reqCtx, &dismissal); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to save feedback"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "marked as not interested"})
+}
+
+// UndoNotInterested godoc
+// @Summary Undo not interested
+// @Description Take back that you are not interested in a tweet
+// @Tags timeline
+// @Produce json
+// @Param id path int true "Tweet ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/tweets/{id}/not-interested [delete]
+func (c *TimelineController) Undo(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	if err := c.repo.Undismiss(ctx.Request().Context(), userID, id); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "not marked as not interested"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to undo feedback"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "feedback undone"})
+}
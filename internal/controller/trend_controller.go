@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"net/http"
+
+	"TwClone/internal/dto"
+	"TwClone/internal/middleware"
+	"TwClone/internal/pkg/utils/signutils"
+	"TwClone/internal/repository"
+	"TwClone/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TrendController serves the trending hashtags computed by the trend
+// service.
+type TrendController struct {
+	trends    *usecase.TrendService
+	presenter *tweetPresenter
+	tweetRepo repository.TweetRepositoryImpl
+}
+
+func NewTrendController(trends *usecase.TrendService, signer signutils.MediaURLSigner) *TrendController {
+	return &TrendController{
+		trends:    trends,
+		presenter: newTweetPresenter(signer),
+		tweetRepo: repository.TweetRepositoryImpl{},
+	}
+}
+
+func (c *TrendController) Route(g *echo.Group) {
+	g.GET("/trends", c.FindAll, middleware.OptionalAuthMiddleware())
+}
+
+// GetTrends godoc
+// @Summary Trending hashtags
+// @Description Hashtags whose use by distinct authors rose the most above their usual level, best first, with a few recent public tweets each. The ranking is recomputed periodically.
+// @Tags trends
+// @Accept json
+// @Produce json
+// @Success 200 {object} dto.WebResponse
+// @Router /api/v1/trends [get]
+func (c *TrendController) FindAll(ctx echo.Context) error {
+	trends, asOf := c.trends.Trends()
+
+	var ids []int64
+	for _, t := range trends {
+		ids = append(ids, t.SampleTweetIDs...)
+	}
+	tweets, err := c.tweetRepo.FindByIDs(ctx.Request().Context(), ids)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch sample tweets"})
+	}
+	built, err := c.presenter.build(ctx, tweets)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet media"})
+	}
+	byID := make(map[int64]dto.TweetResponse, len(built))
+	for _, t := range built {
+		byID[t.ID] = t
+	}
+
+	resp := dto.TrendsResponse{AsOf: asOf, Trends: make([]dto.TrendResponse, 0, len(trends))}
+	for _, t := range trends {
+		// tweets deleted since the trends were computed are left out
+		samples := make([]dto.TweetResponse, 0, len(t.SampleTweetIDs))
+		for _, id := range t.SampleTweetIDs {
+			if tweet, ok := byID[id]; ok {
+				samples = append(samples, tweet)
+			}
+		}
+		resp.Trends = append(resp.Trends, dto.TrendResponse{
+			HashtagID:    t.HashtagID,
+			Hashtag:      t.Tag,
+			TweetCount:   t.TweetCount,
+			AuthorCount:  t.AuthorCount,
+			SampleTweets: samples,
+		})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp})
+}
@@ -0,0 +1,304 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"TwClone/internal/config"
+	"TwClone/internal/dto"
+	"TwClone/internal/entity"
+	"TwClone/internal/middleware"
+	"TwClone/internal/pkg/utils/pageutils"
+	"TwClone/internal/pkg/utils/signutils"
+	"TwClone/internal/repository"
+	"TwClone/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	defaultTweetsLimit = 20
+	maxTweetsLimit     = 100
+)
+
+// TweetController handles creating, reading, editing and deleting tweets.
+type TweetController struct {
+	cfg       *config.TweetConfig
+	pollCfg   *config.PollConfig
+	store     repository.DataStore
+	presenter *tweetPresenter
+	repo      repository.TweetRepositoryImpl
+	mediaRepo repository.MediaRepositoryImpl
+	pollRepo  repository.PollRepositoryImpl
+	userRepo  repository.UserRepositoryImpl
+}
+
+func NewTweetController(cfg *config.TweetConfig, pollCfg *config.PollConfig, store repository.DataStore, signer signutils.MediaURLSigner) *TweetController {
+	return &TweetController{
+		cfg:       cfg,
+		pollCfg:   pollCfg,
+		store:     store,
+		presenter: newTweetPresenter(signer),
+		repo:      repository.TweetRepositoryImpl{},
+		mediaRepo: repository.MediaRepositoryImpl{},
+		pollRepo:  repository.PollRepositoryImpl{},
+		userRepo:  repository.UserRepositoryImpl{},
+	}
+}
+
+func (c *TweetController) Route(g *echo.Group) {
+	tg := g.Group("/tweets")
+	tg.POST("", c.Create, middleware.AuthMiddleware())
+	tg.GET("", c.FindAll, middleware.OptionalAuthMiddleware())
+	tg.GET("/:id", c.FindByID, middleware.OptionalAuthMiddleware())
+	tg.PATCH("/:id", c.Edit, middleware.AuthMiddleware())
+	tg.GET("/:id/history", c.History, middleware.OptionalAuthMiddleware())
+	tg.GET("/:id/thread", c.Thread, middleware.OptionalAuthMiddleware())
+	tg.GET("/:id/replies", c.Replies, middleware.OptionalAuthMiddleware())
+	tg.POST("/:id/poll/votes", c.Vote, middleware.AuthMiddleware())
+	tg.GET("/:id/quotes", c.Quotes, middleware.OptionalAuthMiddleware())
+	tg.GET("/:id/retweets", c.Retweets, middleware.OptionalAuthMiddleware())
+	tg.POST("/:id/retweet", c.Retweet, middleware.AuthMiddleware())
+	tg.DELETE("/:id/retweet", c.Unretweet, middleware.AuthMiddleware())
+	tg.POST("/:id/pin", c.Pin, middleware.AuthMiddleware())
+	tg.DELETE("/:id/pin", c.Unpin, middleware.AuthMiddleware())
+	tg.DELETE("/:id", c.Delete, middleware.AuthMiddleware())
+}
+
+type createTweetReq struct {
+	Content        string         `json:"content" validate:"max=280"`
+	ReplyToTweetID *int64         `json:"reply_to_tweet_id" validate:"omitempty,gt=0"`
+	QuotedTweetID  *int64         `json:"quoted_tweet_id" validate:"omitempty,gt=0"`
+	MediaIDs       []int64        `json:"media_ids" validate:"max=4"`
+	Poll           *createPollReq `json:"poll"`
+}
+
+// CreateTweet godoc
+// @Summary Create tweet
+// @Description Post a tweet. #hashtags and @mentions in the content are linked automatically. Set quoted_tweet_id to quote a tweet with your own commentary; replying to or quoting a retweet replies to or quotes the original, and only tweets you can see can be replied to or quoted. Media uploaded through POST /media can be attached by id; each upload can only be attached once and only by its uploader. Users who turned on require_alt_text cannot attach media without alt text. A tweet with content can instead carry a poll of 2 to 4 options.
+// @Tags tweets
+// @Accept json
+// @Produce json
+// @Param tweet body createTweetReq true "Tweet payload"
+// @Success 201 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/tweets [post]
+func (c *TweetController) Create(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	var req createTweetReq
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "createTweetReq")})
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "createTweetReq")})
+	}
+
+	mediaIDs := uniqueIDs(req.MediaIDs, 0)
+	if req.Content == "" && len(mediaIDs) == 0 {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "tweet must have content or media"})
+	}
+	var poll *entity.Poll
+	if req.Poll != nil {
+		if len(mediaIDs) > 0 || req.Content == "" {
+			return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "a poll needs content and cannot be combined with media"})
+		}
+		if poll, ok = c.newPoll(ctx, req.Poll); !ok {
+			return nil
+		}
+	}
+
+	reqCtx := ctx.Request().Context()
+	var conversationID int64
+	if req.ReplyToTweetID != nil {
+		parent, err := c.repo.FindVisibleOriginal(reqCtx, *req.ReplyToTweetID, userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrRecordNotFound) {
+				return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "replied tweet not found"})
+			}
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch replied tweet"})
+		}
+		req.ReplyToTweetID = &parent.ID
+		conversationID = parent.ConversationID
+	}
+	if req.QuotedTweetID != nil {
+		quoted, err := c.repo.FindVisibleOriginal(reqCtx, *req.QuotedTweetID, userID)
+		if err != nil {
+			if errors.Is(err, repository.ErrRecordNotFound) {
+				return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "quoted tweet not found"})
+			}
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch quoted tweet"})
+		}
+		req.QuotedTweetID = &quoted.ID
+	}
+
+	if len(mediaIDs) > 0 {
+		missing, err := missingAltText(reqCtx, c.userRepo, c.mediaRepo, userID, mediaIDs)
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to check media alt text"})
+		}
+		if missing {
+			return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "alt text is required on all media"})
+		}
+	}
+
+	tweet := entity.Tweet{
+		UserID:         userID,
+		Content:        req.Content,
+		ReplyToTweetID: req.ReplyToTweetID,
+		QuotedTweetID:  req.QuotedTweetID,
+		ConversationID: conversationID,
+	}
+	err := c.store.Atomic(reqCtx, func(s repository.DataStore) error {
+		if err := s.CreateTweet(reqCtx, &tweet, mediaIDs); err != nil {
+			return err
+		}
+		if poll != nil {
+			poll.TweetID = tweet.ID
+			if err := s.CreatePoll(reqCtx, poll); err != nil {
+				return err
+			}
+		}
+		return usecase.LinkTweetEntities(reqCtx, s, &tweet)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrMediaUnavailable) {
+			return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "media not found or already attached"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to create tweet"})
+	}
+
+	resp, err := c.presenter.build(ctx, []*entity.Tweet{&tweet})
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet media"})
+	}
+	return ctx.JSON(http.StatusCreated, dto.WebResponse[any]{Data: resp[0]})
+}
+
+// GetTweets godoc
+// @Summary List tweets
+// @Description List tweets, newest first, with cursor pagination. Tweets of protected accounts you do not follow and of users you block, mute or are blocked by are left out.
+// @Tags tweets
+// @Accept json
+// @Produce json
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/tweets [get]
+func (c *TweetController) FindAll(ctx echo.Context) error {
+	beforeID, err := pageutils.DecodeCursor(ctx.QueryParam("cursor"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid cursor"})
+	}
+	limit := pageutils.ParseLimit(ctx.QueryParam("limit"), defaultTweetsLimit, maxTweetsLimit)
+
+	viewerID, _ := currentUserID(ctx)
+	tweets, err := c.repo.FindPage(ctx.Request().Context(), viewerID, beforeID, limit)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweets"})
+	}
+
+	resp, err := c.presenter.build(ctx, tweets)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet media"})
+	}
+
+	cursor := &dto.CursorMetaData{Limit: limit}
+	if len(tweets) == limit {
+		cursor.NextCursor = pageutils.EncodeCursor(tweets[len(tweets)-1].ID)
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp, Cursor: cursor})
+}
+
+// GetTweet godoc
+// @Summary Get tweet
+// @Description Get a tweet by id. Tweets of protected accounts you do not follow and of users you block, mute or are blocked by are not found, nor are deleted tweets.
+// @Tags tweets
+// @Accept json
+// @Produce json
+// @Param id path int true "Tweet ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/tweets/{id} [get]
+func (c *TweetController) FindByID(ctx echo.Context) error {
+	tweet, ok := c.visibleTweet(ctx)
+	if !ok {
+		return nil
+	}
+
+	resp, err := c.presenter.build(ctx, []*entity.Tweet{tweet})
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet media"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp[0]})
+}
+
+// DeleteTweet godoc
+// @Summary Delete tweet
+// @Description Delete one of your own tweets together with its media and its retweets
+// @Tags tweets
+// @Accept json
+// @Produce json
+// @Param id path int true "Tweet ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/tweets/{id} [delete]
+func (c *TweetController) Delete(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	reqCtx := ctx.Request().Context()
+	tweet, err := c.repo.FindByID(reqCtx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "tweet not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet"})
+	}
+	if tweet.UserID != userID {
+		return ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "you can only delete your own tweets"})
+	}
+
+	if err := c.repo.Delete(reqCtx, id); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to delete tweet"})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "tweet deleted"})
+}
+
+// missingAltText reports whether the author requires alt text on their media
+// and any of the given uploads lacks it.
+func missingAltText(ctx context.Context, userRepo repository.UserRepositoryImpl, mediaRepo repository.MediaRepositoryImpl, userID int64, mediaIDs []int64) (bool, error) {
+	user, err := userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	if !user.RequireAltText {
+		return false, nil
+	}
+
+	media, err := mediaRepo.FindByIDs(ctx, mediaIDs)
+	if err != nil {
+		return false, err
+	}
+	for _, m := range media {
+		if m.AltText == "" {
+			return true, nil
+		}
+	}
+	return false, nil
+}
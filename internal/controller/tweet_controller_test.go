@@ -0,0 +1,53 @@
+package controller
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"TwClone/internal/config"
+	"TwClone/internal/constant"
+	"TwClone/internal/dto"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestTweetControllerCreateRejectsRepliesToHiddenTweets(t *testing.T) {
+	// tweet 10 of protected user 2 exists, but the visibility check
+	// returns nothing for user 1, who does not follow them
+	db := useFakeDB(t, fakeResult{
+		match:   `FROM "tweets"`,
+		columns: []string{"id", "user_id", "conversation_id"},
+		rows:    [][]driver.Value{{int64(10), int64(2), int64(10)}},
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tweets", strings.NewReader(`{"content":"hi","reply_to_tweet_id":10}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	ctx := newTestEcho().NewContext(req, rec)
+	ctx.Set(constant.CTX_USER_ID, int64(1))
+
+	c := NewTweetController(&config.TweetConfig{}, &config.PollConfig{}, nil, nil)
+	if err := c.Create(ctx); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("Create() status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	var resp dto.WebResponse[any]
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %q: %v", rec.Body.String(), err)
+	}
+	if resp.Message != "replied tweet not found" {
+		t.Errorf("Create() message = %q, want %q", resp.Message, "replied tweet not found")
+	}
+	if !db.ran("u.protected") {
+		t.Errorf("Create() did not check whether the replied tweet is visible")
+	}
+	if db.ran("INSERT") {
+		t.Errorf("Create() saved a reply to a hidden tweet")
+	}
+}
@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"TwClone/internal/dto"
+	"TwClone/internal/entity"
+	"TwClone/internal/repository"
+	"TwClone/internal/usecase"
+
+	"github.com/labstack/echo/v4"
+)
+
+type editTweetReq struct {
+	Content string `json:"content" validate:"max=280"`
+}
+
+// EditTweet godoc
+// @Summary Edit tweet
+// @Description Change the content of one of your own tweets. A tweet can be edited a limited number of times within a window after it was posted; earlier versions stay visible through GET /tweets/{id}/history. Quotes and retweets show the latest version, marked as edited. Media cannot be changed.
+// @Tags tweets
+// @Accept json
+// @Produce json
+// @Param id path int true "Tweet ID"
+// @Param tweet body editTweetReq true "Edit payload"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Failure 409 {object} dto.WebResponse
+// @Router /api/v1/tweets/{id} [patch]
+func (c *TweetController) Edit(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	var req editTweetReq
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "editTweetReq")})
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "editTweetReq")})
+	}
+
+	reqCtx := ctx.Request().Context()
+	tweet, err := c.repo.FindByID(reqCtx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "tweet not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet"})
+	}
+	if tweet.UserID != userID {
+		return ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "you can only edit your own tweets"})
+	}
+	if tweet.RetweetedTweetID != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "retweets cannot be edited"})
+	}
+	if time.Since(tweet.CreatedAt) > time.Duration(c.cfg.EditWindowMinutes)*time.Minute {
+		return ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "the tweet can no longer be edited"})
+	}
+	if tweet.EditCount >= c.cfg.MaxEdits {
+		return ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "the tweet has been edited too many times"})
+	}
+	if req.Content == tweet.Content {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "content is unchanged"})
+	}
+	if req.Content == "" {
+		media, err := c.mediaRepo.FindByTweetIDs(reqCtx, []int64{id})
+		if err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet media"})
+		}
+		if len(media) == 0 {
+			return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "tweet must have content or media"})
+		}
+	}
+
+	err = c.store.Atomic(reqCtx, func(s repository.DataStore) error {
+		if err := s.EditTweet(reqCtx, tweet, req.Content); err != nil {
+			return err
+		}
+		return usecase.LinkTweetEntities(reqCtx, s, tweet)
+	})
+	if err != nil {
+		if errors.Is(err, repository.ErrTweetChanged) {
+			return ctx.JSON(http.StatusConflict, dto.WebResponse[any]{Message: "the tweet was changed in the meantime"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to edit tweet"})
+	}
+
+	resp, err := c.presenter.build(ctx, []*entity.Tweet{tweet})
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet media"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp[0]})
+}
+
+// GetTweetHistory godoc
+// @Summary Get edit history
+// @Description List every version of a tweet's content, oldest first, ending with the current one
+// @Tags tweets
+// @Produce json
+// @Param id path int true "Tweet ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/tweets/{id}/history [get]
+func (c *TweetController) History(ctx echo.Context) error {
+	tweet, ok := c.visibleTweet(ctx)
+	if !ok {
+		return nil
+	}
+
+	versions, err := c.repo.FindVersions(ctx.Request().Context(), tweet.ID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet history"})
+	}
+
+	current := entity.TweetVersion{
+		TweetID:   tweet.ID,
+		Version:   tweet.EditCount + 1,
+		Content:   tweet.Content,
+		CreatedAt: tweet.CreatedAt,
+	}
+	if tweet.EditedAt != nil {
+		current.CreatedAt = *tweet.EditedAt
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: append(versions, &current)})
+}
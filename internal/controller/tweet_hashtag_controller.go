@@ -2,7 +2,6 @@ package controller
 
 import (
 	"TwClone/internal/dto"
-	"TwClone/internal/entity"
 	"TwClone/internal/repository"
 	"context"
 	"net/http"
@@ -21,33 +20,10 @@ func NewTweetHashtagController() *TweetHashtagController {
 
 func (c *TweetHashtagController) Route(g *echo.Group) {
 	thg := g.Group("/tweet-hashtags")
-	thg.POST("", c.Create)
 	thg.GET("/tweet/:tweet_id", c.ByTweet)
 	thg.GET("/hashtag/:hashtag_id", c.ByHashtag)
 }
 
-func (c *TweetHashtagController) Create(ctx echo.Context) error {
-	var th entity.TweetHashtag
-	if err := ctx.Bind(&th); err != nil {
-		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "TweetHashtag")})
-	}
-	if err := c.repo.Create(context.Background(), &th); err != nil {
-		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: err.Error()})
-	}
-	return ctx.JSON(http.StatusCreated, th)
-}
-
-// CreateTweetHashtag godoc
-// @Summary Create tweet-hashtag relation
-// @Description Attach hashtag to a tweet
-// @Tags tweet-hashtags
-// @Accept json
-// @Produce json
-// @Param th body entity.TweetHashtag true "TweetHashtag payload"
-// @Success 201 {object} entity.TweetHashtag
-// @Failure 400 {object} dto.WebResponse
-// @Router /api/v1/tweet-hashtags [post]
-
 // GetTweetHashtagsByTweet godoc
 // @Summary Hashtags by tweet
 // @Description Get hashtags for a tweet
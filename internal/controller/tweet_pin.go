@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"TwClone/internal/dto"
+	"TwClone/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PinTweet godoc
+// @Summary Pin tweet
+// @Description Pin one of your own tweets to the top of your profile, replacing the tweet pinned before. Retweets cannot be pinned.
+// @Tags tweets
+// @Produce json
+// @Param id path int true "Tweet ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/tweets/{id}/pin [post]
+func (c *TweetController) Pin(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	reqCtx := ctx.Request().Context()
+	tweet, err := c.repo.FindByID(reqCtx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "tweet not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet"})
+	}
+	if tweet.UserID != userID {
+		return ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "you can only pin your own tweets"})
+	}
+	if tweet.RetweetedTweetID != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "retweets cannot be pinned"})
+	}
+
+	if err := c.userRepo.PinTweet(reqCtx, userID, tweet.ID); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to pin tweet"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "tweet pinned"})
+}
+
+// UnpinTweet godoc
+// @Summary Unpin tweet
+// @Description Unpin the tweet pinned to your profile
+// @Tags tweets
+// @Produce json
+// @Param id path int true "Tweet ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/tweets/{id}/pin [delete]
+func (c *TweetController) Unpin(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	if err := c.userRepo.UnpinTweet(ctx.Request().Context(), userID, id); err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "tweet is not pinned"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to unpin tweet"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "tweet unpinned"})
+}
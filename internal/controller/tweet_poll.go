@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"TwClone/internal/dto"
+	"TwClone/internal/entity"
+	"TwClone/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+type createPollReq struct {
+	Options         []string `json:"options" validate:"min=2,max=4,dive,max=25"`
+	DurationMinutes int      `json:"duration_minutes" validate:"required,gt=0"`
+}
+
+type voteReq struct {
+	OptionID int64 `json:"option_id" validate:"required,gt=0"`
+}
+
+// VotePoll godoc
+// @Summary Vote in poll
+// @Description Vote for one option of a tweet's poll while it is open. You vote once per poll; afterwards you see the results.
+// @Tags tweets
+// @Accept json
+// @Produce json
+// @Param id path int true "Tweet ID"
+// @Param vote body voteReq true "Vote payload"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Failure 409 {object} dto.WebResponse
+// @Router /api/v1/tweets/{id}/poll/votes [post]
+func (c *TweetController) Vote(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	tweet, ok := c.visibleTweet(ctx)
+	if !ok {
+		return nil
+	}
+
+	var req voteReq
+	if err := ctx.Bind(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "voteReq")})
+	}
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "voteReq")})
+	}
+
+	reqCtx := ctx.Request().Context()
+	poll, err := c.pollRepo.FindByTweetID(reqCtx, tweet.ID)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "tweet has no poll"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch poll"})
+	}
+
+	if err := c.pollRepo.Vote(reqCtx, poll.ID, req.OptionID, userID); err != nil {
+		switch {
+		case errors.Is(err, repository.ErrDuplicate):
+			return ctx.JSON(http.StatusConflict, dto.WebResponse[any]{Message: "you already voted"})
+		case errors.Is(err, repository.ErrRecordNotFound):
+			return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "option is not part of this poll"})
+		case errors.Is(err, repository.ErrPollClosed):
+			return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "the poll has closed"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to vote"})
+	}
+
+	if poll, err = c.pollRepo.FindByTweetID(reqCtx, tweet.ID); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch poll"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: dto.FromPollEntity(poll, &req.OptionID, time.Now())})
+}
+
+// newPoll builds the poll of a new tweet. When ok is false the error
+// response has already been written.
+func (c *TweetController) newPoll(ctx echo.Context, req *createPollReq) (*entity.Poll, bool) {
+	if req.DurationMinutes < c.pollCfg.MinDurationMinutes || req.DurationMinutes > c.pollCfg.MaxDurationMinutes {
+		ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "poll duration is out of range"})
+		return nil, false
+	}
+
+	poll := &entity.Poll{EndsAt: time.Now().Add(time.Duration(req.DurationMinutes) * time.Minute)}
+	seen := make(map[string]struct{}, len(req.Options))
+	for i, label := range req.Options {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "poll options cannot be empty"})
+			return nil, false
+		}
+		if _, ok := seen[strings.ToLower(label)]; ok {
+			ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "poll options must differ"})
+			return nil, false
+		}
+		seen[strings.ToLower(label)] = struct{}{}
+		poll.Options = append(poll.Options, &entity.PollOption{Position: i, Label: label})
+	}
+	return poll, true
+}
@@ -0,0 +1,216 @@
+package controller
+
+import (
+	"time"
+
+	"TwClone/internal/dto"
+	"TwClone/internal/entity"
+	"TwClone/internal/pkg/utils/signutils"
+	"TwClone/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// tweetPresenter turns tweets into API responses for the current viewer.
+// Every controller returning tweets uses it so they render the same way.
+type tweetPresenter struct {
+	signer       signutils.MediaURLSigner
+	bookmarkRepo repository.BookmarkRepositoryImpl
+	mediaRepo    repository.MediaRepositoryImpl
+	pollRepo     repository.PollRepositoryImpl
+	tweetRepo    repository.TweetRepositoryImpl
+	userRepo     repository.UserRepositoryImpl
+}
+
+func newTweetPresenter(signer signutils.MediaURLSigner) *tweetPresenter {
+	return &tweetPresenter{
+		signer:       signer,
+		bookmarkRepo: repository.BookmarkRepositoryImpl{},
+		mediaRepo:    repository.MediaRepositoryImpl{},
+		pollRepo:     repository.PollRepositoryImpl{},
+		tweetRepo:    repository.TweetRepositoryImpl{},
+		userRepo:     repository.UserRepositoryImpl{},
+	}
+}
+
+// build attaches media and polls to tweets, keeping their order, and embeds
+// the tweets that retweets and quote tweets point at when the viewer may see
+// them. Sensitive media is presented according to the current viewer's
+// preference.
+func (p *tweetPresenter) build(ctx echo.Context, tweets []*entity.Tweet) ([]dto.TweetResponse, error) {
+	resp := make([]dto.TweetResponse, 0, len(tweets))
+	if len(tweets) == 0 {
+		return resp, nil
+	}
+
+	viewerID, preference, err := viewerMediaPreference(ctx, p.userRepo)
+	if err != nil {
+		return nil, err
+	}
+	embedded, err := p.embedded(ctx, tweets, viewerID, preference)
+	if err != nil {
+		return nil, err
+	}
+	resp, err = p.render(ctx, tweets, viewerID, preference)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, t := range tweets {
+		if t.RetweetedTweetID != nil {
+			resp[i].RetweetedTweet = embedded[*t.RetweetedTweetID]
+		}
+		if t.QuotedTweetID != nil {
+			resp[i].QuotedTweet = embedded[*t.QuotedTweetID]
+		}
+	}
+	return resp, nil
+}
+
+// embedded renders the tweets that the given retweets and quote tweets point
+// at and viewerID may see, by id.
+func (p *tweetPresenter) embedded(ctx echo.Context, tweets []*entity.Tweet, viewerID int64, preference string) (map[int64]*dto.TweetResponse, error) {
+	var ids []int64
+	for _, t := range tweets {
+		if t.RetweetedTweetID != nil {
+			ids = append(ids, *t.RetweetedTweetID)
+		}
+		if t.QuotedTweetID != nil {
+			ids = append(ids, *t.QuotedTweetID)
+		}
+	}
+	byID := make(map[int64]*dto.TweetResponse, len(ids))
+	if len(ids) == 0 {
+		return byID, nil
+	}
+
+	reqCtx := ctx.Request().Context()
+	originals, err := p.tweetRepo.FindByIDs(reqCtx, ids)
+	if err != nil {
+		return nil, err
+	}
+	visible, err := p.tweetRepo.FindVisibleIDs(reqCtx, ids, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	shown := originals[:0]
+	for _, t := range originals {
+		if visible[t.ID] {
+			shown = append(shown, t)
+		}
+	}
+
+	rendered, err := p.render(ctx, shown, viewerID, preference)
+	if err != nil {
+		return nil, err
+	}
+	for i := range rendered {
+		byID[rendered[i].ID] = &rendered[i]
+	}
+	return byID, nil
+}
+
+// render turns tweets into responses with their media and polls, keeping
+// their order.
+func (p *tweetPresenter) render(ctx echo.Context, tweets []*entity.Tweet, viewerID int64, preference string) ([]dto.TweetResponse, error) {
+	resp := make([]dto.TweetResponse, 0, len(tweets))
+	if len(tweets) == 0 {
+		return resp, nil
+	}
+
+	ids := make([]int64, 0, len(tweets))
+	for _, t := range tweets {
+		ids = append(ids, t.ID)
+	}
+	medias, err := p.mediaRepo.FindByTweetIDs(ctx.Request().Context(), ids)
+	if err != nil {
+		return nil, err
+	}
+	byTweet := make(map[int64][]*entity.Media, len(tweets))
+	for _, m := range medias {
+		byTweet[*m.TweetID] = append(byTweet[*m.TweetID], m)
+	}
+
+	polls, err := p.polls(ctx, ids, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	bookmarked, bookmarkCounts, err := p.bookmarks(ctx, tweets, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range tweets {
+		r := dto.FromTweetEntity(t, dto.FromMediaEntities(byTweet[t.ID], viewerID, preference, p.signer))
+		r.Poll = polls[t.ID]
+		r.Bookmarked = bookmarked[t.ID]
+		if t.UserID == viewerID {
+			count := bookmarkCounts[t.ID]
+			r.BookmarkCount = &count
+		}
+		resp = append(resp, r)
+	}
+	return resp, nil
+}
+
+// bookmarks reports which tweets viewerID bookmarked and how often the
+// viewer's own tweets were bookmarked.
+func (p *tweetPresenter) bookmarks(ctx echo.Context, tweets []*entity.Tweet, viewerID int64) (map[int64]bool, map[int64]int64, error) {
+	if viewerID == 0 {
+		return map[int64]bool{}, map[int64]int64{}, nil
+	}
+
+	ids := make([]int64, 0, len(tweets))
+	var own []int64
+	for _, t := range tweets {
+		ids = append(ids, t.ID)
+		if t.UserID == viewerID {
+			own = append(own, t.ID)
+		}
+	}
+
+	reqCtx := ctx.Request().Context()
+	bookmarked, err := p.bookmarkRepo.FindBookmarked(reqCtx, viewerID, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	counts, err := p.bookmarkRepo.CountByTweets(reqCtx, own)
+	if err != nil {
+		return nil, nil, err
+	}
+	return bookmarked, counts, nil
+}
+
+// polls presents the polls of the given tweets to viewerID, by tweet id.
+func (p *tweetPresenter) polls(ctx echo.Context, tweetIDs []int64, viewerID int64) (map[int64]*dto.PollResponse, error) {
+	reqCtx := ctx.Request().Context()
+	polls, err := p.pollRepo.FindByTweetIDs(reqCtx, tweetIDs)
+	if err != nil {
+		return nil, err
+	}
+	byTweet := make(map[int64]*dto.PollResponse, len(polls))
+	if len(polls) == 0 {
+		return byTweet, nil
+	}
+
+	pollIDs := make([]int64, 0, len(polls))
+	for _, poll := range polls {
+		pollIDs = append(pollIDs, poll.ID)
+	}
+	votes, err := p.pollRepo.FindVotes(reqCtx, pollIDs, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, poll := range polls {
+		var voted *int64
+		if optionID, ok := votes[poll.ID]; ok {
+			voted = &optionID
+		}
+		resp := dto.FromPollEntity(poll, voted, now)
+		byTweet[poll.TweetID] = &resp
+	}
+	return byTweet, nil
+}
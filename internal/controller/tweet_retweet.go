@@ -0,0 +1,169 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"TwClone/internal/dto"
+	"TwClone/internal/entity"
+	"TwClone/internal/pkg/utils/pageutils"
+	"TwClone/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Retweet godoc
+// @Summary Retweet
+// @Description Retweet a tweet; retweeting a retweet retweets the original. Each tweet can be retweeted once. Tweets of protected accounts cannot be retweeted.
+// @Tags tweets
+// @Produce json
+// @Param id path int true "Tweet ID"
+// @Success 201 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Failure 409 {object} dto.WebResponse
+// @Router /api/v1/tweets/{id}/retweet [post]
+func (c *TweetController) Retweet(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	reqCtx := ctx.Request().Context()
+	original, err := c.repo.FindVisibleOriginal(reqCtx, id, userID)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "tweet not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet"})
+	}
+	author, err := c.userRepo.FindByID(reqCtx, original.UserID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet author"})
+	}
+	if author.Protected {
+		return ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "tweets of protected accounts cannot be retweeted"})
+	}
+
+	retweet := entity.Tweet{UserID: userID, RetweetedTweetID: &original.ID}
+	if err := c.store.CreateTweet(reqCtx, &retweet, nil); err != nil {
+		if errors.Is(err, repository.ErrDuplicate) {
+			return ctx.JSON(http.StatusConflict, dto.WebResponse[any]{Message: "tweet already retweeted"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to retweet"})
+	}
+
+	resp, err := c.presenter.build(ctx, []*entity.Tweet{&retweet})
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet media"})
+	}
+	return ctx.JSON(http.StatusCreated, dto.WebResponse[any]{Data: resp[0]})
+}
+
+// Unretweet godoc
+// @Summary Undo retweet
+// @Description Undo your retweet of a tweet, given the original or the retweet
+// @Tags tweets
+// @Produce json
+// @Param id path int true "Tweet ID"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/tweets/{id}/retweet [delete]
+func (c *TweetController) Unretweet(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+
+	reqCtx := ctx.Request().Context()
+	original, err := c.repo.FindOriginal(reqCtx, id)
+	if err == nil {
+		id = original.ID
+	} else if !errors.Is(err, repository.ErrRecordNotFound) {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet"})
+	}
+	// the original may be gone already, so the retweet is looked up by id
+	retweet, err := c.repo.FindRetweet(reqCtx, userID, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "retweet not found"})
+		}
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch retweet"})
+	}
+
+	if err := c.repo.Delete(reqCtx, retweet.ID); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to undo retweet"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Message: "retweet undone"})
+}
+
+// GetTweetQuotes godoc
+// @Summary List quote tweets
+// @Description List the quote tweets of a tweet, newest first, with cursor pagination
+// @Tags tweets
+// @Produce json
+// @Param id path int true "Tweet ID"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/tweets/{id}/quotes [get]
+func (c *TweetController) Quotes(ctx echo.Context) error {
+	return c.referencing(ctx, c.repo.FindQuotes)
+}
+
+// GetTweetRetweets godoc
+// @Summary List retweets
+// @Description List the retweets of a tweet, newest first, with cursor pagination
+// @Tags tweets
+// @Produce json
+// @Param id path int true "Tweet ID"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 100)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/tweets/{id}/retweets [get]
+func (c *TweetController) Retweets(ctx echo.Context) error {
+	return c.referencing(ctx, c.repo.FindRetweets)
+}
+
+// referencing lists a page of the tweets that find returns for the visible
+// tweet from the :id path param.
+func (c *TweetController) referencing(ctx echo.Context, find func(ctx context.Context, tweetID, viewerID, beforeID int64, limit int) ([]*entity.Tweet, error)) error {
+	tweet, ok := c.visibleTweet(ctx)
+	if !ok {
+		return nil
+	}
+	beforeID, err := pageutils.DecodeCursor(ctx.QueryParam("cursor"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid cursor"})
+	}
+	limit := pageutils.ParseLimit(ctx.QueryParam("limit"), defaultTweetsLimit, maxTweetsLimit)
+
+	viewerID, _ := currentUserID(ctx)
+	tweets, err := find(ctx.Request().Context(), tweet.ID, viewerID, beforeID, limit)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweets"})
+	}
+
+	resp, err := c.presenter.build(ctx, tweets)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet media"})
+	}
+
+	cursor := &dto.CursorMetaData{Limit: limit}
+	if len(tweets) == limit {
+		cursor.NextCursor = pageutils.EncodeCursor(tweets[len(tweets)-1].ID)
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp, Cursor: cursor})
+}
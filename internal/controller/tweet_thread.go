@@ -0,0 +1,265 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"TwClone/internal/dto"
+	"TwClone/internal/entity"
+	"TwClone/internal/pkg/utils/pageutils"
+	"TwClone/internal/repository"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	maxThreadAncestors = 100
+	defaultThreadDepth = 3
+	maxThreadDepth     = 6
+	defaultThreadWidth = 10
+	maxThreadWidth     = 50
+)
+
+// GetTweetThread godoc
+// @Summary Get thread
+// @Description Get a tweet with the tweets it replies to up to the conversation root and its replies as a tree, oldest reply first. Deleted tweets and tweets you may not see appear as tombstones. Nodes with more replies than shown carry a cursor for GET /tweets/{id}/replies.
+// @Tags tweets
+// @Accept json
+// @Produce json
+// @Param id path int true "Tweet ID"
+// @Param depth query int false "Levels of replies (max 6)"
+// @Param limit query int false "Replies per tweet (max 50)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/tweets/{id}/thread [get]
+func (c *TweetController) Thread(ctx echo.Context) error {
+	focal, ok := c.visibleTweet(ctx)
+	if !ok {
+		return nil
+	}
+	depth := pageutils.ParseLimit(ctx.QueryParam("depth"), defaultThreadDepth, maxThreadDepth)
+	width := pageutils.ParseLimit(ctx.QueryParam("limit"), defaultThreadWidth, maxThreadWidth)
+
+	reqCtx := ctx.Request().Context()
+	ancestors, err := c.repo.FindAncestors(reqCtx, focal, maxThreadAncestors)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch thread"})
+	}
+	tree, err := c.replyTree(ctx, focal.ID, 0, depth, width)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch replies"})
+	}
+	if err := tree.render(ctx, c, append(ancestors, focal)); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch thread"})
+	}
+
+	resp := dto.ThreadResponse{
+		ConversationID: focal.ConversationID,
+		Ancestors:      make([]dto.ThreadNode, 0, len(ancestors)+1),
+		Focal:          tree.node(focal),
+	}
+	if len(ancestors) > 0 && ancestors[0].ReplyToTweetID != nil {
+		if len(ancestors) == maxThreadAncestors {
+			resp.HasMoreAncestors = true
+		} else {
+			// the chain reaches a tweet that was deleted without leaving a
+			// tombstone
+			resp.Ancestors = append(resp.Ancestors, dto.ThreadNode{ID: *ancestors[0].ReplyToTweetID, Tombstone: dto.TombstoneDeleted})
+		}
+	}
+	for _, t := range ancestors {
+		node := tree.node(t)
+		node.Replies, node.HasMoreReplies, node.RepliesCursor = nil, false, ""
+		resp.Ancestors = append(resp.Ancestors, node)
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp})
+}
+
+// GetTweetReplies godoc
+// @Summary Get replies
+// @Description Get the next page of a tweet's replies, oldest first, each with its own replies as a tree
+// @Tags tweets
+// @Accept json
+// @Produce json
+// @Param id path int true "Tweet ID"
+// @Param cursor query string false "replies_cursor of the tweet's thread node, or next_cursor of the previous page"
+// @Param depth query int false "Levels of replies (max 6)"
+// @Param limit query int false "Replies per tweet (max 50)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 404 {object} dto.WebResponse
+// @Router /api/v1/tweets/{id}/replies [get]
+func (c *TweetController) Replies(ctx echo.Context) error {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+	}
+	afterID, err := pageutils.DecodeCursor(ctx.QueryParam("cursor"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid cursor"})
+	}
+	depth := pageutils.ParseLimit(ctx.QueryParam("depth"), defaultThreadDepth, maxThreadDepth)
+	width := pageutils.ParseLimit(ctx.QueryParam("limit"), defaultThreadWidth, maxThreadWidth)
+
+	// replies of tombstones stay reachable, so the parent is not looked up
+	// as a live tweet
+	tree, err := c.replyTree(ctx, id, afterID, depth, width)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch replies"})
+	}
+	if err := tree.render(ctx, c, nil); err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch replies"})
+	}
+
+	replies := tree.children[id]
+	resp := make([]dto.ThreadNode, 0, len(replies))
+	for _, t := range replies {
+		resp = append(resp, tree.node(t))
+	}
+	cursor := &dto.CursorMetaData{Limit: width}
+	if tree.more[id] {
+		cursor.NextCursor = pageutils.EncodeCursor(replies[len(replies)-1].ID)
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp, Cursor: cursor})
+}
+
+// visibleTweet loads the live tweet from the :id path param if the viewer may
+// see it. When ok is false the error response has already been written.
+func (c *TweetController) visibleTweet(ctx echo.Context) (*entity.Tweet, bool) {
+	id, err := strconv.ParseInt(ctx.Param("id"), 10, 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
+		return nil, false
+	}
+
+	reqCtx := ctx.Request().Context()
+	tweet, err := c.repo.FindByID(reqCtx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "tweet not found"})
+		} else {
+			ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet"})
+		}
+		return nil, false
+	}
+
+	viewerID, _ := currentUserID(ctx)
+	visible, err := c.repo.FindVisibleIDs(reqCtx, []int64{id}, viewerID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch tweet"})
+		return nil, false
+	}
+	if !visible[id] {
+		ctx.JSON(http.StatusNotFound, dto.WebResponse[any]{Message: "tweet not found"})
+		return nil, false
+	}
+	return tweet, true
+}
+
+// replyTree fetches the replies below a tweet level by level, at most width
+// per tweet and depth levels deep. Only the first level starts after
+// afterID.
+func (c *TweetController) replyTree(ctx echo.Context, rootID, afterID int64, depth, width int) (*threadTree, error) {
+	reqCtx := ctx.Request().Context()
+	tree := &threadTree{
+		children: make(map[int64][]*entity.Tweet),
+		more:     make(map[int64]bool),
+	}
+
+	parents := []int64{rootID}
+	for level := 0; level < depth && len(parents) > 0; level++ {
+		replies, err := c.repo.FindReplies(reqCtx, parents, afterID, width)
+		if err != nil {
+			return nil, err
+		}
+		afterID = 0
+
+		parents = parents[:0]
+		for _, r := range replies {
+			parentID := *r.ReplyToTweetID
+			if len(tree.children[parentID]) == width {
+				tree.more[parentID] = true
+				continue
+			}
+			tree.children[parentID] = append(tree.children[parentID], r)
+			tree.tweets = append(tree.tweets, r)
+			parents = append(parents, r.ID)
+		}
+	}
+	return tree, nil
+}
+
+// threadTree holds the tweets of a thread while it is turned into nodes.
+type threadTree struct {
+	tweets   []*entity.Tweet
+	children map[int64][]*entity.Tweet
+	// more marks tweets with replies beyond those in children
+	more map[int64]bool
+
+	replyCounts map[int64]int64
+	visible     map[int64]bool
+	rendered    map[int64]dto.TweetResponse
+}
+
+// render prepares the responses of the tree's tweets and the given extra
+// tweets for the current viewer.
+func (t *threadTree) render(ctx echo.Context, c *TweetController, extra []*entity.Tweet) error {
+	reqCtx := ctx.Request().Context()
+	all := append(append(make([]*entity.Tweet, 0, len(extra)+len(t.tweets)), extra...), t.tweets...)
+	ids := make([]int64, 0, len(all))
+	for _, tweet := range all {
+		ids = append(ids, tweet.ID)
+	}
+
+	var err error
+	if t.replyCounts, err = c.repo.CountReplies(reqCtx, ids); err != nil {
+		return err
+	}
+	viewerID, _ := currentUserID(ctx)
+	if t.visible, err = c.repo.FindVisibleIDs(reqCtx, ids, viewerID); err != nil {
+		return err
+	}
+
+	live := make([]*entity.Tweet, 0, len(all))
+	for _, tweet := range all {
+		if tweet.DeletedAt == nil && t.visible[tweet.ID] {
+			live = append(live, tweet)
+		}
+	}
+	built, err := c.presenter.build(ctx, live)
+	if err != nil {
+		return err
+	}
+	t.rendered = make(map[int64]dto.TweetResponse, len(built))
+	for _, b := range built {
+		t.rendered[b.ID] = b
+	}
+	return nil
+}
+
+// node turns a tweet and the replies fetched below it into a thread node.
+func (t *threadTree) node(tweet *entity.Tweet) dto.ThreadNode {
+	node := dto.ThreadNode{ID: tweet.ID, ReplyCount: t.replyCounts[tweet.ID]}
+	switch resp, ok := t.rendered[tweet.ID]; {
+	case tweet.DeletedAt != nil:
+		node.Tombstone = dto.TombstoneDeleted
+	case !ok:
+		node.Tombstone = dto.TombstoneUnavailable
+	default:
+		node.Tweet = &resp
+	}
+
+	replies := t.children[tweet.ID]
+	for _, r := range replies {
+		node.Replies = append(node.Replies, t.node(r))
+	}
+	switch {
+	case t.more[tweet.ID]:
+		node.HasMoreReplies = true
+		node.RepliesCursor = pageutils.EncodeCursor(replies[len(replies)-1].ID)
+	case len(replies) == 0 && node.ReplyCount > 0:
+		// below the depth limit: replies start from the beginning
+		node.HasMoreReplies = true
+	}
+	return node
+}
@@ -3,23 +3,43 @@ package controller
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"TwClone/internal/dto"
 	"TwClone/internal/entity"
 	"TwClone/internal/middleware"
 	"TwClone/internal/pkg/utils/encryptutils"
+	"TwClone/internal/pkg/utils/pageutils"
 	"TwClone/internal/repository"
 
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	defaultUserSearchLimit = 20
+	maxUserSearchLimit     = 50
+	defaultTypeaheadLimit  = 8
+	maxTypeaheadLimit      = 10
+	maxUserQueryLength     = 100
+)
+
 // UserController handles user CRUD.
 type UserController struct {
-	repo repository.UserRepositoryImpl
+	presenter  *userPresenter
+	repo       repository.UserRepositoryImpl
+	linkRepo   repository.ProfileLinkRepositoryImpl
+	followRepo repository.FollowRepositoryImpl
 }
 
 func NewUserController() *UserController {
-	return &UserController{repo: repository.UserRepositoryImpl{}}
+	return &UserController{
+		presenter:  newUserPresenter(),
+		repo:       repository.UserRepositoryImpl{},
+		linkRepo:   repository.ProfileLinkRepositoryImpl{},
+		followRepo: repository.FollowRepositoryImpl{},
+	}
 }
 
 func (c *UserController) Route(g *echo.Group) {
@@ -27,6 +47,8 @@ func (c *UserController) Route(g *echo.Group) {
 	ug.POST("", c.Create)
 	ug.GET("", c.FindAll)
 	ug.GET("/token", c.UserToken)
+	ug.GET("/search", c.Search)
+	ug.GET("/typeahead", c.Typeahead)
 	ug.GET("/:id", c.FindByID)
 	ug.PUT("/:id", c.Update)
 	ug.DELETE("/:id", c.Delete)
@@ -43,12 +65,27 @@ type createUserReq struct {
 }
 
 type updateUserReq struct {
-	Email    *string `json:"email"`
-	Name     *string `json:"name"`
-	Avatar   *string `json:"avatar"`
-	Banner   *string `json:"banner"`
-	Bio      *string `json:"bio"`
-	Password *string `json:"password"`
+	Email              *string           `json:"email"`
+	Name               *string           `json:"name"`
+	Avatar             *string           `json:"avatar"`
+	Banner             *string           `json:"banner"`
+	Bio                *string           `json:"bio"`
+	Location           *string           `json:"location" validate:"omitempty,max=100"`
+	Website            *string           `json:"website" validate:"omitempty,http_url,max=1024"`
+	Birthday           *string           `json:"birthday" validate:"omitempty,datetime=2006-01-02"`
+	BirthdayVisibility *string           `json:"birthday_visibility" validate:"omitempty,oneof=public followers private"`
+	Links              *[]profileLinkReq `json:"links" validate:"omitempty,max=4,dive"`
+	DMPrivacy          *string           `json:"dm_privacy" validate:"omitempty,oneof=everyone followers nobody"`
+	HideReadReceipts   *bool             `json:"hide_read_receipts"`
+	RequireAltText     *bool             `json:"require_alt_text"`
+	SensitiveMedia     *string           `json:"sensitive_media" validate:"omitempty,oneof=show blur hide"`
+	Protected          *bool             `json:"protected"`
+	Password           *string           `json:"password"`
+}
+
+type profileLinkReq struct {
+	Label string `json:"label" validate:"required,max=30"`
+	URL   string `json:"url" validate:"required,http_url,max=1024"`
 }
 
 // CreateUser godoc
@@ -119,21 +156,23 @@ func (c *UserController) Create(ctx echo.Context) error {
 // @Success 200 {object} dto.WebResponse
 // @Router /api/v1/users [get]
 func (c *UserController) FindAll(ctx echo.Context) error {
-	users, err := c.repo.FindAll(ctx.Request().Context())
+	viewerID, _ := currentUserID(ctx)
+	reqCtx := ctx.Request().Context()
+	users, err := c.repo.FindAll(reqCtx)
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch users"})
 	}
 	// convert to response DTOs to avoid leaking password
-	resp := make([]dto.UserResponse, 0, len(users))
-	for _, u := range users {
-		resp = append(resp, dto.FromEntity(u))
+	resp, err := c.presenter.build(reqCtx, users, viewerID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch users"})
 	}
 	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: resp})
 }
 
 // GetUser godoc
 // @Summary Get user by id
-// @Description Get a user by its ID
+// @Description Get a user by its ID, with their profile links. The birthday is only included when its visibility setting lets you see it.
 // @Tags users
 // @Accept json
 // @Produce json
@@ -156,12 +195,18 @@ func (c *UserController) FindByID(ctx echo.Context) error {
 		}
 		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch user"})
 	}
-	return ctx.JSON(http.StatusOK, dto.WebResponse[dto.UserResponse]{Data: dto.FromEntity(user)})
+
+	viewerID, _ := currentUserID(ctx)
+	resp, err := c.presenter.profile(ctx.Request().Context(), user, viewerID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch user"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[dto.UserResponse]{Data: resp})
 }
 
 // UpdateUser godoc
 // @Summary Update user
-// @Description Update a user's information
+// @Description Update your own information. links replaces all profile links, up to 4; each is verified in the background by checking that the linked page links back to the profile with rel="me". An empty birthday clears it.
 // @Tags users
 // @Accept json
 // @Produce json
@@ -169,19 +214,30 @@ func (c *UserController) FindByID(ctx echo.Context) error {
 // @Param user body updateUserReq true "Update payload"
 // @Success 200 {object} dto.WebResponse
 // @Failure 400 {object} dto.WebResponse
+// @Failure 403 {object} dto.WebResponse
 // @Failure 404 {object} dto.WebResponse
 // @Router /api/v1/users/{id} [put]
 func (c *UserController) Update(ctx echo.Context) error {
+	viewerID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
 		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid id"})
 	}
+	if id != viewerID {
+		return ctx.JSON(http.StatusForbidden, dto.WebResponse[any]{Message: "you can only update your own account"})
+	}
 
 	var req updateUserReq
 	if err := ctx.Bind(&req); err != nil {
 		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "updateUserReq")})
 	}
+	if err := ctx.Validate(&req); err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid request", Errors: extractFieldErrors(err, "updateUserReq")})
+	}
 
 	user, err := c.repo.FindByID(ctx.Request().Context(), id)
 	if err != nil {
@@ -206,6 +262,45 @@ func (c *UserController) Update(ctx echo.Context) error {
 	if req.Bio != nil {
 		user.Bio = *req.Bio
 	}
+	if req.Location != nil {
+		user.Location = strings.TrimSpace(*req.Location)
+	}
+	if req.Website != nil {
+		user.Website = *req.Website
+	}
+	if req.Birthday != nil {
+		user.Birthday = nil
+		if *req.Birthday != "" {
+			birthday, _ := time.Parse("2006-01-02", *req.Birthday)
+			if birthday.After(time.Now()) {
+				return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{
+					Message: "validation error",
+					Errors:  []dto.FieldError{{Field: "birthday", Message: "birthday must be in the past"}},
+				})
+			}
+			user.Birthday = &birthday
+		}
+	}
+	if req.BirthdayVisibility != nil {
+		user.BirthdayVisibility = *req.BirthdayVisibility
+	}
+	if req.DMPrivacy != nil {
+		user.DMPrivacy = *req.DMPrivacy
+	}
+	if req.HideReadReceipts != nil {
+		user.HideReadReceipts = *req.HideReadReceipts
+	}
+	if req.RequireAltText != nil {
+		user.RequireAltText = *req.RequireAltText
+	}
+	if req.SensitiveMedia != nil {
+		user.SensitiveMedia = *req.SensitiveMedia
+	}
+	unprotected := false
+	if req.Protected != nil {
+		unprotected = user.Protected && !*req.Protected
+		user.Protected = *req.Protected
+	}
 	if req.Password != nil {
 		enc := encryptutils.NewBcryptEncryptor(10)
 		hashed, err := enc.Hash(*req.Password)
@@ -218,7 +313,27 @@ func (c *UserController) Update(ctx echo.Context) error {
 	if err := c.repo.Update(ctx.Request().Context(), user); err != nil {
 		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to update user"})
 	}
-	return ctx.JSON(http.StatusOK, dto.WebResponse[dto.UserResponse]{Message: "updated", Data: dto.FromEntity(user)})
+	if unprotected {
+		// people waiting to follow a protected account follow it once it opens up
+		if err := c.followRepo.ApproveAllRequests(ctx.Request().Context(), user.ID); err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to approve follow requests"})
+		}
+	}
+	if req.Links != nil {
+		links := make([]*entity.ProfileLink, 0, len(*req.Links))
+		for _, l := range *req.Links {
+			links = append(links, &entity.ProfileLink{Label: strings.TrimSpace(l.Label), URL: l.URL})
+		}
+		if err := c.linkRepo.Replace(ctx.Request().Context(), user.ID, links); err != nil {
+			return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to update profile links"})
+		}
+	}
+
+	resp, err := c.presenter.profile(ctx.Request().Context(), user, viewerID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch user"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[dto.UserResponse]{Message: "updated", Data: resp})
 }
 
 // DeleteUser godoc
@@ -275,6 +390,100 @@ func (c *UserController) UserToken(ctx echo.Context) error {
 	if err != nil {
 		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch user"})
 	}
+	resp, err := c.presenter.profile(ctx.Request().Context(), user, userID)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to fetch user"})
+	}
+
+	return ctx.JSON(http.StatusOK, dto.WebResponse[dto.UserResponse]{Data: resp})
+}
+
+// SearchUsers godoc
+// @Summary Search users
+// @Description Find users whose username or name starts with or resembles the query. Exact username matches come first, then people you follow, people followed by those you follow and popular accounts rank higher.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query"
+// @Param cursor query string false "Cursor from the previous page"
+// @Param limit query int false "Page size (max 50)"
+// @Success 200 {object} dto.WebResponse
+// @Failure 400 {object} dto.WebResponse
+// @Router /api/v1/users/search [get]
+func (c *UserController) Search(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
+
+	q := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(ctx.QueryParam("q")), "@"))
+	if q == "" {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "q is required"})
+	}
+	if utf8.RuneCountInString(q) > maxUserQueryLength {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "q is too long"})
+	}
+	offset, err := pageutils.DecodeCursor(ctx.QueryParam("cursor"))
+	if err != nil {
+		return ctx.JSON(http.StatusBadRequest, dto.WebResponse[any]{Message: "invalid cursor"})
+	}
+	limit := pageutils.ParseLimit(ctx.QueryParam("limit"), defaultUserSearchLimit, maxUserSearchLimit)
+
+	results, err := c.repo.Search(ctx.Request().Context(), q, userID, int(offset), limit)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to search users"})
+	}
+
+	cursor := &dto.CursorMetaData{Limit: limit}
+	if len(results) == limit {
+		cursor.NextCursor = pageutils.EncodeCursor(offset + int64(limit))
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: userSearchResponses(results), Cursor: cursor})
+}
+
+// UserTypeahead godoc
+// @Summary Autocomplete users
+// @Description Suggest users whose username or name starts with the query, for @mention autocomplete in the composer. People you follow come first.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param q query string true "Username or name prefix, with or without @"
+// @Param limit query int false "Number of suggestions (max 10)"
+// @Success 200 {object} dto.WebResponse
+// @Router /api/v1/users/typeahead [get]
+func (c *UserController) Typeahead(ctx echo.Context) error {
+	userID, ok := currentUserID(ctx)
+	if !ok {
+		return ctx.JSON(http.StatusUnauthorized, dto.WebResponse[any]{Message: "unauthorized"})
+	}
 
-	return ctx.JSON(http.StatusOK, dto.WebResponse[dto.UserResponse]{Data: dto.FromEntity(user)})
+	q := strings.TrimPrefix(strings.TrimSpace(ctx.QueryParam("q")), "@")
+	if q == "" || utf8.RuneCountInString(q) > maxUserQueryLength {
+		return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: []dto.UserSearchResponse{}})
+	}
+	limit := pageutils.ParseLimit(ctx.QueryParam("limit"), defaultTypeaheadLimit, maxTypeaheadLimit)
+
+	results, err := c.repo.Typeahead(ctx.Request().Context(), q, userID, limit)
+	if err != nil {
+		return ctx.JSON(http.StatusInternalServerError, dto.WebResponse[any]{Message: "failed to search users"})
+	}
+	return ctx.JSON(http.StatusOK, dto.WebResponse[any]{Data: userSearchResponses(results)})
+}
+
+func userSearchResponses(results []*repository.UserSearchResult) []dto.UserSearchResponse {
+	resp := make([]dto.UserSearchResponse, 0, len(results))
+	for _, r := range results {
+		resp = append(resp, dto.UserSearchResponse{
+			ID:             r.ID,
+			Username:       r.Username,
+			Name:           r.Name,
+			Avatar:         r.Avatar,
+			Bio:            r.Bio,
+			Protected:      r.Protected,
+			Following:      r.Following,
+			MutualCount:    r.MutualCount,
+			FollowersCount: r.FollowersCount,
+		})
+	}
+	return resp
 }
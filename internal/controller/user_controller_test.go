@@ -0,0 +1,43 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"TwClone/internal/constant"
+
+	"github.com/labstack/echo/v4"
+)
+
+func TestUserControllerUpdateRejectsOtherUsers(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"unprotect", `{"protected":false}`},
+		{"protect", `{"protected":true}`},
+		{"empty", `{}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/users/2", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			ctx := echo.New().NewContext(req, rec)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues("2")
+			ctx.Set(constant.CTX_USER_ID, int64(1))
+
+			// the check comes before any database access, which would
+			// panic here without a database
+			if err := NewUserController().Update(ctx); err != nil {
+				t.Fatalf("Update() error = %v", err)
+			}
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("Update() of another user status = %d, want %d", rec.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
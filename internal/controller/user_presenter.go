@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"context"
+
+	"TwClone/internal/dto"
+	"TwClone/internal/entity"
+	"TwClone/internal/repository"
+)
+
+// userPresenter turns users into API responses as a given viewer sees them:
+// with how the viewer relates to each, and without birthdays the viewer may
+// not see or settings that are the user's own business.
+type userPresenter struct {
+	userRepo repository.UserRepositoryImpl
+	linkRepo repository.ProfileLinkRepositoryImpl
+}
+
+func newUserPresenter() *userPresenter {
+	return &userPresenter{
+		userRepo: repository.UserRepositoryImpl{},
+		linkRepo: repository.ProfileLinkRepositoryImpl{},
+	}
+}
+
+// build renders users in order as the viewer sees them.
+func (p *userPresenter) build(ctx context.Context, users []*entity.User, viewerID int64) ([]dto.UserResponse, error) {
+	ids := make([]int64, 0, len(users))
+	for _, u := range users {
+		ids = append(ids, u.ID)
+	}
+	rels, err := p.userRepo.FindRelationships(ctx, viewerID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]dto.UserResponse, 0, len(users))
+	for _, u := range users {
+		resp = append(resp, p.render(u, viewerID, rels[u.ID]))
+	}
+	return resp, nil
+}
+
+// profile renders a single user as the viewer sees them, with their
+// profile links.
+func (p *userPresenter) profile(ctx context.Context, user *entity.User, viewerID int64) (dto.UserResponse, error) {
+	rels, err := p.userRepo.FindRelationships(ctx, viewerID, []int64{user.ID})
+	if err != nil {
+		return dto.UserResponse{}, err
+	}
+	resp := p.render(user, viewerID, rels[user.ID])
+
+	links, err := p.linkRepo.FindByUser(ctx, user.ID)
+	if err != nil {
+		return dto.UserResponse{}, err
+	}
+	resp.Links = dto.FromProfileLinks(links)
+	return resp, nil
+}
+
+func (p *userPresenter) render(user *entity.User, viewerID int64, rel *repository.Relationship) dto.UserResponse {
+	resp := dto.FromEntity(user)
+	if user.ID == viewerID {
+		return resp
+	}
+
+	resp.DMPrivacy = nil
+	resp.HideReadReceipts = nil
+	resp.RequireAltText = nil
+	resp.SensitiveMedia = nil
+
+	following := false
+	if rel != nil {
+		following = rel.Following
+		resp.Relationship = &dto.RelationshipResponse{
+			FollowedBy:           rel.FollowedBy,
+			Following:            rel.Following,
+			Blocking:             rel.Blocking,
+			Muting:               rel.Muting,
+			FollowRequestPending: rel.FollowRequestPending,
+		}
+	}
+	switch {
+	case user.BirthdayVisibility == entity.BirthdayVisibilityPublic:
+	case user.BirthdayVisibility == entity.BirthdayVisibilityFollowers && following:
+	default:
+		resp.Birthday = ""
+	}
+	return resp
+}
@@ -0,0 +1,21 @@
+package database
+
+import "gorm.io/gorm"
+
+// ReconcileUserCounts recomputes the follower, following and tweet counts of
+// every user from the follows and tweets tables, and returns how many users
+// had counts that were off.
+func ReconcileUserCounts(db *gorm.DB) (int64, error) {
+	result := db.Exec(`
+		UPDATE users SET followers_count = c.followers, following_count = c.following, tweets_count = c.tweets
+		FROM (
+			SELECT u.id,
+				(SELECT COUNT(*) FROM follows f WHERE f.following_id = u.id) AS followers,
+				(SELECT COUNT(*) FROM follows f WHERE f.follower_id = u.id) AS following,
+				(SELECT COUNT(*) FROM tweets t WHERE t.user_id = u.id AND t.deleted_at IS NULL) AS tweets
+			FROM users u
+		) c
+		WHERE users.id = c.id
+		AND (users.followers_count, users.following_count, users.tweets_count) IS DISTINCT FROM (c.followers, c.following, c.tweets)`)
+	return result.RowsAffected, result.Error
+}
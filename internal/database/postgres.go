@@ -46,20 +46,152 @@ func InitGorm(cfg *config.Config) (*gorm.DB, error) {
 	sqlDB.SetMaxOpenConns(dbCfg.MaxOpenConn)
 	sqlDB.SetConnMaxLifetime(time.Duration(dbCfg.MaxConnLifetime) * time.Minute)
 
+	// counts are backfilled once, when their columns are added
+	backfillCounts := !gdb.Migrator().HasColumn(&entity.User{}, "TweetsCount")
 	if err := gdb.AutoMigrate(
 		&entity.User{},
+		&entity.ProfileLink{},
 		&entity.Tweet{},
+		&entity.TweetVersion{},
+		&entity.Draft{},
+		&entity.DraftMedia{},
+		&entity.Poll{},
+		&entity.PollOption{},
+		&entity.PollVote{},
 		&entity.Follow{},
+		&entity.FollowRequest{},
+		&entity.Suggestion{},
+		&entity.SuggestionDismissal{},
+		&entity.List{},
+		&entity.ListMember{},
+		&entity.ListSubscription{},
+		&entity.TweetDismissal{},
+		&entity.Block{},
+		&entity.Mute{},
 		&entity.Like{},
+		&entity.Bookmark{},
+		&entity.BookmarkFolder{},
 		&entity.Hashtag{},
 		&entity.TweetHashtag{},
 		&entity.Mention{},
 		&entity.Media{},
+		&entity.MediaVariant{},
+		&entity.MediaBlob{},
 		&entity.Notification{},
+		&entity.SavedSearch{},
+		&entity.Conversation{},
+		&entity.ConversationMember{},
+		&entity.Message{},
+		&entity.MessageMedia{},
+		&entity.Report{},
+		&entity.DeviceKey{},
+		&entity.PreKey{},
+		&entity.MessageEnvelope{},
 	); err != nil {
 		logger.Log.Fatalf("failed to run automigrate: %v", err)
 		return nil, err
 	}
+	if backfillCounts {
+		if _, err := ReconcileUserCounts(gdb); err != nil {
+			logger.Log.Fatalf("failed to backfill user counts: %v", err)
+			return nil, err
+		}
+	}
+	if err := migrateTweetConversations(gdb); err != nil {
+		logger.Log.Fatalf("failed to backfill tweet conversations: %v", err)
+		return nil, err
+	}
+	if err := migrateRetweets(gdb); err != nil {
+		logger.Log.Fatalf("failed to migrate retweets: %v", err)
+		return nil, err
+	}
+	if err := migrateTweetSearch(gdb); err != nil {
+		logger.Log.Fatalf("failed to migrate tweet search: %v", err)
+		return nil, err
+	}
+	if err := migrateUserSearch(gdb); err != nil {
+		logger.Log.Fatalf("failed to migrate user search: %v", err)
+		return nil, err
+	}
 
 	return gdb, nil
 }
+
+// migrateTweetConversations fills in the conversation of tweets posted before
+// tweets recorded it, by walking every reply chain from its root. Replies
+// whose parent was deleted become the root of their own conversation.
+func migrateTweetConversations(gdb *gorm.DB) error {
+	var missing int64
+	if err := gdb.Model(&entity.Tweet{}).Where("conversation_id = 0").Count(&missing).Error; err != nil {
+		return err
+	}
+	if missing == 0 {
+		return nil
+	}
+
+	return gdb.Exec(`
+			WITH RECURSIVE chain AS (
+				SELECT id, id AS root FROM tweets
+				WHERE reply_to_tweet_id IS NULL OR NOT EXISTS (SELECT 1 FROM tweets p WHERE p.id = tweets.reply_to_tweet_id)
+				UNION ALL
+				SELECT t.id, chain.root FROM tweets t JOIN chain ON t.reply_to_tweet_id = chain.id
+			)
+			UPDATE tweets SET conversation_id = chain.root
+			FROM chain WHERE tweets.id = chain.id AND tweets.conversation_id = 0`).Error
+}
+
+// migrateRetweets enforces that a user retweets a tweet at most once with a
+// partial unique index. Retweets posted before this rule that carry content
+// become quote tweets, and repeated retweets are turned into tombstones,
+// keeping the first.
+func migrateRetweets(gdb *gorm.DB) error {
+	return gdb.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range []string{
+			`UPDATE tweets SET quoted_tweet_id = retweeted_tweet_id, retweeted_tweet_id = NULL
+			WHERE retweeted_tweet_id IS NOT NULL AND content <> ''`,
+			`UPDATE tweets SET deleted_at = NOW() FROM tweets first
+			WHERE tweets.retweeted_tweet_id = first.retweeted_tweet_id AND tweets.user_id = first.user_id
+			AND tweets.id > first.id AND tweets.deleted_at IS NULL AND first.deleted_at IS NULL`,
+			`CREATE UNIQUE INDEX IF NOT EXISTS idx_tweets_user_retweet ON tweets (user_id, retweeted_tweet_id)
+			WHERE retweeted_tweet_id IS NOT NULL AND deleted_at IS NULL`,
+		} {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// migrateTweetSearch adds the full-text search column of tweets, which GORM
+// cannot declare: a tsvector generated from the content with the "simple"
+// configuration so that words of any language are matched unstemmed.
+func migrateTweetSearch(gdb *gorm.DB) error {
+	return gdb.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`ALTER TABLE tweets ADD COLUMN IF NOT EXISTS search_vector tsvector
+			GENERATED ALWAYS AS (to_tsvector('simple', content)) STORED`).Error; err != nil {
+			return err
+		}
+		return tx.Exec(`CREATE INDEX IF NOT EXISTS idx_tweets_search_vector ON tweets USING GIN (search_vector)`).Error
+	})
+}
+
+// migrateUserSearch adds the indexes behind user search: pg_trgm indexes for
+// fuzzy matching and pattern indexes that serve prefix lookups, such as
+// @mention typeahead, without touching the trigram indexes.
+func migrateUserSearch(gdb *gorm.DB) error {
+	return gdb.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range []string{
+			`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+			`CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING GIN (LOWER(username) gin_trgm_ops)`,
+			`CREATE INDEX IF NOT EXISTS idx_users_name_trgm ON users USING GIN (LOWER(name) gin_trgm_ops)`,
+			`CREATE INDEX IF NOT EXISTS idx_users_username_prefix ON users (LOWER(username) text_pattern_ops)`,
+			`CREATE INDEX IF NOT EXISTS idx_users_name_prefix ON users (LOWER(name) text_pattern_ops)`,
+		} {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
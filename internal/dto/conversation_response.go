@@ -0,0 +1,94 @@
+package dto
+
+import (
+	"time"
+
+	"TwClone/internal/entity"
+)
+
+// ConversationResponse is the API representation of a direct message
+// conversation as seen by one of its members. Status, UnreadCount and
+// LastReadMessageID describe the viewer's own membership.
+type ConversationResponse struct {
+	ID                int64             `json:"id"`
+	Type              string            `json:"type"`
+	Name              string            `json:"name,omitempty"`
+	CreatorID         int64             `json:"creator_id"`
+	Encrypted         bool              `json:"encrypted"`
+	MemberIDs         []int64           `json:"member_ids"`
+	Status            string            `json:"status"`
+	UnreadCount       int64             `json:"unread_count"`
+	LastReadMessageID int64             `json:"last_read_message_id"`
+	ReadStates        []MemberReadState `json:"read_states"`
+	LastMessageAt     time.Time         `json:"last_message_at"`
+	CreatedAt         time.Time         `json:"created_at"`
+}
+
+// MemberReadState tells how far another member has received and read a
+// conversation. LastReadMessageID is omitted when read receipts are hidden.
+type MemberReadState struct {
+	UserID                 int64  `json:"user_id"`
+	LastDeliveredMessageID int64  `json:"last_delivered_message_id"`
+	LastReadMessageID      *int64 `json:"last_read_message_id,omitempty"`
+}
+
+// MessageResponse is the API representation of a direct message. Messages of
+// encrypted conversations have no content and carry the envelope addressed
+// to the viewer's device instead.
+type MessageResponse struct {
+	ID             int64                   `json:"id"`
+	ConversationID int64                   `json:"conversation_id"`
+	SenderID       int64                   `json:"sender_id"`
+	Content        string                  `json:"content"`
+	MediaIDs       []int64                 `json:"media_ids"`
+	Envelope       *entity.MessageEnvelope `json:"envelope,omitempty"`
+	CreatedAt      time.Time               `json:"created_at"`
+}
+
+// FromConversationEntity builds the viewer's view of a conversation. Read
+// positions of members listed in hiddenReceipts are left out.
+func FromConversationEntity(c *entity.Conversation, members []*entity.ConversationMember, viewerID int64, hiddenReceipts map[int64]bool) ConversationResponse {
+	resp := ConversationResponse{
+		ID:            c.ID,
+		Type:          c.Type,
+		Name:          c.Name,
+		CreatorID:     c.CreatorID,
+		Encrypted:     c.Encrypted,
+		MemberIDs:     make([]int64, 0, len(members)),
+		ReadStates:    make([]MemberReadState, 0, len(members)),
+		LastMessageAt: c.LastMessageAt,
+		CreatedAt:     c.CreatedAt,
+	}
+
+	for _, m := range members {
+		resp.MemberIDs = append(resp.MemberIDs, m.UserID)
+		if m.UserID == viewerID {
+			resp.Status = m.Status
+			resp.LastReadMessageID = m.LastReadMessageID
+			continue
+		}
+
+		state := MemberReadState{UserID: m.UserID, LastDeliveredMessageID: m.LastDeliveredMessageID}
+		if !hiddenReceipts[m.UserID] {
+			lastRead := m.LastReadMessageID
+			state.LastReadMessageID = &lastRead
+		}
+		resp.ReadStates = append(resp.ReadStates, state)
+	}
+	return resp
+}
+
+func FromMessageEntity(m *entity.Message, mediaIDs []int64) MessageResponse {
+	if mediaIDs == nil {
+		mediaIDs = []int64{}
+	}
+
+	return MessageResponse{
+		ID:             m.ID,
+		ConversationID: m.ConversationID,
+		SenderID:       m.SenderID,
+		Content:        m.Content,
+		MediaIDs:       mediaIDs,
+		CreatedAt:      m.CreatedAt,
+	}
+}
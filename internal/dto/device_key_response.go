@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"time"
+
+	"TwClone/internal/entity"
+)
+
+// DeviceKeyResponse is the public key bundle of one device. RemainingPreKeys
+// is only shown to the device owner and OneTimePreKey only when a prekey was
+// claimed to start a session.
+type DeviceKeyResponse struct {
+	UserID                int64          `json:"user_id"`
+	DeviceID              string         `json:"device_id"`
+	IdentityKey           string         `json:"identity_key"`
+	SignedPreKeyID        int64          `json:"signed_prekey_id"`
+	SignedPreKey          string         `json:"signed_prekey"`
+	SignedPreKeySignature string         `json:"signed_prekey_signature"`
+	RemainingPreKeys      *int64         `json:"remaining_prekeys,omitempty"`
+	OneTimePreKey         *entity.PreKey `json:"one_time_prekey,omitempty"`
+	CreatedAt             time.Time      `json:"created_at"`
+	UpdatedAt             time.Time      `json:"updated_at"`
+}
+
+func FromDeviceKeyEntity(k *entity.DeviceKey) DeviceKeyResponse {
+	return DeviceKeyResponse{
+		UserID:                k.UserID,
+		DeviceID:              k.DeviceID,
+		IdentityKey:           k.IdentityKey,
+		SignedPreKeyID:        k.SignedPreKeyID,
+		SignedPreKey:          k.SignedPreKey,
+		SignedPreKeySignature: k.SignedPreKeySignature,
+		CreatedAt:             k.CreatedAt,
+		UpdatedAt:             k.UpdatedAt,
+	}
+}
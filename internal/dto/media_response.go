@@ -0,0 +1,101 @@
+package dto
+
+import (
+	"time"
+
+	"TwClone/internal/entity"
+	"TwClone/internal/pkg/utils/signutils"
+)
+
+// MediaResponse is the API representation of an uploaded file as seen by a
+// viewer. Display tells the client how to present it: "show", "blur" (render
+// the blurhash behind a warning) or "hide", in which case the file URLs are
+// left out. URLs are signed and expire; clients should not store them.
+type MediaResponse struct {
+	ID             int64                  `json:"id"`
+	UserID         int64                  `json:"user_id"`
+	TweetID        *int64                 `json:"tweet_id"`
+	MediaType      string                 `json:"media_type"`
+	MimeType       string                 `json:"mime_type"`
+	Size           int64                  `json:"size"`
+	URL            string                 `json:"url,omitempty"`
+	Status         string                 `json:"status"`
+	Width          int                    `json:"width"`
+	Height         int                    `json:"height"`
+	Blurhash       string                 `json:"blurhash,omitempty"`
+	Variants       []MediaVariantResponse `json:"variants"`
+	AltText        string                 `json:"alt_text"`
+	Sensitive      bool                   `json:"sensitive"`
+	ContentWarning string                 `json:"content_warning,omitempty"`
+	Display        string                 `json:"display"`
+	CreatedAt      time.Time              `json:"created_at"`
+}
+
+type MediaVariantResponse struct {
+	Name     string `json:"name"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	MimeType string `json:"mime_type"`
+	Size     int64  `json:"size"`
+	URL      string `json:"url,omitempty"`
+}
+
+// MediaDisplay decides how media is presented to a viewer with the given
+// sensitive media preference. Uploaders always see their own media.
+func MediaDisplay(m *entity.Media, viewerID int64, preference string) string {
+	if !m.Sensitive || m.UserID == viewerID {
+		return entity.SensitiveMediaShow
+	}
+	if preference == "" {
+		return entity.SensitiveMediaBlur
+	}
+	return preference
+}
+
+func FromMediaEntity(m *entity.Media, viewerID int64, preference string, signer signutils.MediaURLSigner) MediaResponse {
+	resp := MediaResponse{
+		ID:             m.ID,
+		UserID:         m.UserID,
+		TweetID:        m.TweetID,
+		MediaType:      m.MediaType,
+		MimeType:       m.MimeType,
+		Size:           m.Size,
+		URL:            signer.SignedURL(m.ID, ""),
+		Status:         m.Status,
+		Width:          m.Width,
+		Height:         m.Height,
+		Blurhash:       m.Blurhash,
+		Variants:       make([]MediaVariantResponse, 0, len(m.Variants)),
+		AltText:        m.AltText,
+		Sensitive:      m.Sensitive,
+		ContentWarning: m.ContentWarning,
+		Display:        MediaDisplay(m, viewerID, preference),
+		CreatedAt:      m.CreatedAt,
+	}
+
+	if resp.Display == entity.SensitiveMediaHide {
+		resp.URL = ""
+		resp.Blurhash = ""
+		return resp
+	}
+	for _, v := range m.Variants {
+		resp.Variants = append(resp.Variants, MediaVariantResponse{
+			Name:     v.Name,
+			Width:    v.Width,
+			Height:   v.Height,
+			MimeType: v.MimeType,
+			Size:     v.Size,
+			URL:      signer.SignedURL(m.ID, v.Name),
+		})
+	}
+	return resp
+}
+
+// FromMediaEntities converts media for one viewer, keeping their order.
+func FromMediaEntities(medias []*entity.Media, viewerID int64, preference string, signer signutils.MediaURLSigner) []MediaResponse {
+	resp := make([]MediaResponse, 0, len(medias))
+	for _, m := range medias {
+		resp = append(resp, FromMediaEntity(m, viewerID, preference, signer))
+	}
+	return resp
+}
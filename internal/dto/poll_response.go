@@ -0,0 +1,51 @@
+package dto
+
+import (
+	"time"
+
+	"TwClone/internal/entity"
+)
+
+// PollResponse is the API representation of a poll for one viewer. The
+// votes of each option are only shown once the viewer voted or the poll
+// closed.
+type PollResponse struct {
+	ID            int64                `json:"id"`
+	EndsAt        time.Time            `json:"ends_at"`
+	Closed        bool                 `json:"closed"`
+	TotalVotes    int64                `json:"total_votes"`
+	Options       []PollOptionResponse `json:"options"`
+	VotedOptionID *int64               `json:"voted_option_id,omitempty"`
+}
+
+type PollOptionResponse struct {
+	ID    int64  `json:"id"`
+	Label string `json:"label"`
+	Votes *int64 `json:"votes,omitempty"`
+}
+
+// FromPollEntity presents a poll to a viewer who voted for votedOptionID,
+// or nil when they did not vote.
+func FromPollEntity(p *entity.Poll, votedOptionID *int64, now time.Time) PollResponse {
+	closed := !now.Before(p.EndsAt)
+	showResults := closed || votedOptionID != nil
+
+	options := make([]PollOptionResponse, 0, len(p.Options))
+	for _, o := range p.Options {
+		option := PollOptionResponse{ID: o.ID, Label: o.Label}
+		if showResults {
+			votes := o.Votes
+			option.Votes = &votes
+		}
+		options = append(options, option)
+	}
+
+	return PollResponse{
+		ID:            p.ID,
+		EndsAt:        p.EndsAt,
+		Closed:        closed,
+		TotalVotes:    p.TotalVotes,
+		Options:       options,
+		VotedOptionID: votedOptionID,
+	}
+}
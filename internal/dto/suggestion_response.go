@@ -0,0 +1,10 @@
+package dto
+
+// SuggestionResponse is an account suggested to follow. MutualCount is how
+// many of the accounts the viewer follows follow it, SharedHashtags how many
+// hashtags both recently tweeted.
+type SuggestionResponse struct {
+	User           UserResponse `json:"user"`
+	MutualCount    int64        `json:"mutual_count"`
+	SharedHashtags int64        `json:"shared_hashtags"`
+}
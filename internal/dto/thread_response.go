@@ -0,0 +1,30 @@
+package dto
+
+// Tombstone reasons of thread nodes whose tweet cannot be shown.
+const (
+	TombstoneDeleted     = "deleted"
+	TombstoneUnavailable = "unavailable"
+)
+
+// ThreadResponse is a tweet in the context of its conversation: the chain of
+// tweets it replies to, root first, and its replies as a tree.
+type ThreadResponse struct {
+	ConversationID   int64        `json:"conversation_id"`
+	Ancestors        []ThreadNode `json:"ancestors"`
+	HasMoreAncestors bool         `json:"has_more_ancestors"`
+	Focal            ThreadNode   `json:"focal"`
+}
+
+// ThreadNode is one tweet of a thread. Tweet is nil for a tombstone, a tweet
+// that was deleted or that the viewer may not see, which is kept so the
+// thread stays readable. When HasMoreReplies is set, further replies are
+// fetched from GET /tweets/{id}/replies with RepliesCursor.
+type ThreadNode struct {
+	ID             int64          `json:"id"`
+	Tweet          *TweetResponse `json:"tweet,omitempty"`
+	Tombstone      string         `json:"tombstone,omitempty"`
+	ReplyCount     int64          `json:"reply_count"`
+	Replies        []ThreadNode   `json:"replies,omitempty"`
+	HasMoreReplies bool           `json:"has_more_replies"`
+	RepliesCursor  string         `json:"replies_cursor,omitempty"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+// RankedTweetResponse is a tweet of the ranked home timeline with the
+// explanation of its score, given in debug mode.
+type RankedTweetResponse struct {
+	TweetResponse
+	Ranking *TimelineScoreResponse `json:"ranking,omitempty"`
+}
+
+// TimelineScoreResponse explains the score of a ranked tweet: the weighted
+// features, each with the count it is derived from, add up to a relevance
+// that is multiplied by the recency decay.
+type TimelineScoreResponse struct {
+	Score              float64 `json:"score"`
+	InNetwork          float64 `json:"in_network"`
+	Affinity           float64 `json:"affinity"`
+	Interactions       int64   `json:"interactions"`
+	Velocity           float64 `json:"velocity"`
+	Engagements        int64   `json:"engagements"`
+	SocialProof        float64 `json:"social_proof"`
+	SocialProofCount   int64   `json:"social_proof_count"`
+	Media              float64 `json:"media"`
+	NotInterested      float64 `json:"not_interested"`
+	NotInterestedCount int64   `json:"not_interested_count"`
+	Recency            float64 `json:"recency"`
+	AgeMinutes         float64 `json:"age_minutes"`
+}
@@ -0,0 +1,19 @@
+package dto
+
+import "time"
+
+// TrendsResponse is the current trend ranking.
+type TrendsResponse struct {
+	AsOf   time.Time       `json:"as_of"`
+	Trends []TrendResponse `json:"trends"`
+}
+
+// TrendResponse is a trending hashtag with a few recent public tweets using
+// it. Counts cover the trend window.
+type TrendResponse struct {
+	HashtagID    int64           `json:"hashtag_id"`
+	Hashtag      string          `json:"hashtag"`
+	TweetCount   int64           `json:"tweet_count"`
+	AuthorCount  int64           `json:"author_count"`
+	SampleTweets []TweetResponse `json:"sample_tweets"`
+}
@@ -0,0 +1,55 @@
+package dto
+
+import (
+	"time"
+
+	"TwClone/internal/entity"
+)
+
+// TweetResponse is the API representation of a tweet with its media. The
+// tweet a retweet or quote tweet points at is embedded, without its own
+// embedded tweet, when the viewer may see it. Edited tweets are marked as
+// such, embedded ones included. Whether the viewer bookmarked a tweet is
+// only told to them, and bookmark counts only to the tweet's author.
+type TweetResponse struct {
+	ID               int64           `json:"id"`
+	UserID           int64           `json:"user_id"`
+	Content          string          `json:"content"`
+	ReplyToTweetID   *int64          `json:"reply_to_tweet_id,omitempty"`
+	RetweetedTweetID *int64          `json:"retweeted_tweet_id,omitempty"`
+	QuotedTweetID    *int64          `json:"quoted_tweet_id,omitempty"`
+	ConversationID   int64           `json:"conversation_id"`
+	RetweetedTweet   *TweetResponse  `json:"retweeted_tweet,omitempty"`
+	QuotedTweet      *TweetResponse  `json:"quoted_tweet,omitempty"`
+	Media            []MediaResponse `json:"media"`
+	Poll             *PollResponse   `json:"poll,omitempty"`
+	Bookmarked       bool            `json:"bookmarked"`
+	BookmarkCount    *int64          `json:"bookmark_count,omitempty"`
+	Edited           bool            `json:"edited"`
+	EditCount        int             `json:"edit_count"`
+	EditedAt         *time.Time      `json:"edited_at,omitempty"`
+	CreatedAt        time.Time       `json:"created_at"`
+	UpdatedAt        time.Time       `json:"updated_at"`
+}
+
+func FromTweetEntity(t *entity.Tweet, media []MediaResponse) TweetResponse {
+	if media == nil {
+		media = []MediaResponse{}
+	}
+
+	return TweetResponse{
+		ID:               t.ID,
+		UserID:           t.UserID,
+		Content:          t.Content,
+		ReplyToTweetID:   t.ReplyToTweetID,
+		RetweetedTweetID: t.RetweetedTweetID,
+		QuotedTweetID:    t.QuotedTweetID,
+		ConversationID:   t.ConversationID,
+		Media:            media,
+		Edited:           t.EditCount > 0,
+		EditCount:        t.EditCount,
+		EditedAt:         t.EditedAt,
+		CreatedAt:        t.CreatedAt,
+		UpdatedAt:        t.UpdatedAt,
+	}
+}
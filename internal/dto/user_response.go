@@ -1,18 +1,40 @@
 package dto
 
-import "TwClone/internal/entity"
+import (
+	"time"
+
+	"TwClone/internal/entity"
+)
 
 // UserResponse is the API representation of a user (no password included).
+// Birthday is left out when the viewer may not see it, and the DM and media
+// settings when anyone but the user is viewing it. Relationship is set when someone
+// other than the user is viewing it.
 type UserResponse struct {
-	ID        int64  `json:"id"`
-	Email     string `json:"email"`
-	Name      string `json:"name"`
-	Username  string `json:"username"`
-	Avatar    string `json:"avatar,omitempty"`
-	Banner    string `json:"banner,omitempty"`
-	Bio       string `json:"bio,omitempty"`
-	CreatedAt string `json:"created_at"`
-	UpdatedAt string `json:"updated_at"`
+	ID                 int64                 `json:"id"`
+	Email              string                `json:"email"`
+	Name               string                `json:"name"`
+	Username           string                `json:"username"`
+	Avatar             string                `json:"avatar,omitempty"`
+	Banner             string                `json:"banner,omitempty"`
+	Bio                string                `json:"bio,omitempty"`
+	Location           string                `json:"location,omitempty"`
+	Website            string                `json:"website,omitempty"`
+	PinnedTweetID      *int64                `json:"pinned_tweet_id,omitempty"`
+	Birthday           string                `json:"birthday,omitempty"`
+	BirthdayVisibility string                `json:"birthday_visibility"`
+	Links              []ProfileLinkResponse `json:"links,omitempty"`
+	DMPrivacy          *string               `json:"dm_privacy,omitempty"`
+	HideReadReceipts   *bool                 `json:"hide_read_receipts,omitempty"`
+	RequireAltText     *bool                 `json:"require_alt_text,omitempty"`
+	SensitiveMedia     *string               `json:"sensitive_media,omitempty"`
+	Protected          bool                  `json:"protected"`
+	FollowersCount     int64                 `json:"followers_count"`
+	FollowingCount     int64                 `json:"following_count"`
+	TweetsCount        int64                 `json:"tweets_count"`
+	Relationship       *RelationshipResponse `json:"relationship,omitempty"`
+	CreatedAt          string                `json:"created_at"`
+	UpdatedAt          string                `json:"updated_at"`
 }
 
 // FromEntity converts an entity.User to UserResponse. Time formatting is RFC3339.
@@ -24,16 +46,67 @@ func FromEntity(u *entity.User) UserResponse {
 	if !u.UpdatedAt.IsZero() {
 		updatedAt = u.UpdatedAt.Format("2006-01-02T15:04:05Z07:00")
 	}
+	var birthday string
+	if u.Birthday != nil {
+		birthday = u.Birthday.Format("2006-01-02")
+	}
 
 	return UserResponse{
-		ID:        u.ID,
-		Email:     u.Email,
-		Name:      u.Name,
-		Username:  u.Username,
-		Avatar:    u.Avatar,
-		Banner:    u.Banner,
-		Bio:       u.Bio,
-		CreatedAt: createdAt,
-		UpdatedAt: updatedAt,
+		ID:                 u.ID,
+		Email:              u.Email,
+		Name:               u.Name,
+		Username:           u.Username,
+		Avatar:             u.Avatar,
+		Banner:             u.Banner,
+		Bio:                u.Bio,
+		Location:           u.Location,
+		Website:            u.Website,
+		PinnedTweetID:      u.PinnedTweetID,
+		Birthday:           birthday,
+		BirthdayVisibility: u.BirthdayVisibility,
+		DMPrivacy:          &u.DMPrivacy,
+		HideReadReceipts:   &u.HideReadReceipts,
+		RequireAltText:     &u.RequireAltText,
+		SensitiveMedia:     &u.SensitiveMedia,
+		Protected:          u.Protected,
+		FollowersCount:     u.FollowersCount,
+		FollowingCount:     u.FollowingCount,
+		TweetsCount:        u.TweetsCount,
+		CreatedAt:          createdAt,
+		UpdatedAt:          updatedAt,
+	}
+}
+
+// RelationshipResponse describes how the viewer relates to a user.
+// FollowedBy is set when the user follows the viewer, Following when the
+// viewer follows the user.
+type RelationshipResponse struct {
+	FollowedBy           bool `json:"followed_by"`
+	Following            bool `json:"following"`
+	Blocking             bool `json:"blocking"`
+	Muting               bool `json:"muting"`
+	FollowRequestPending bool `json:"follow_request_pending"`
+}
+
+// ProfileLinkResponse is a custom link on a user's profile. Verified is set
+// when the linked page links back to the profile with rel="me".
+type ProfileLinkResponse struct {
+	Label      string     `json:"label"`
+	URL        string     `json:"url"`
+	Verified   bool       `json:"verified"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+}
+
+// FromProfileLinks converts profile links to their API representation.
+func FromProfileLinks(links []*entity.ProfileLink) []ProfileLinkResponse {
+	resp := make([]ProfileLinkResponse, 0, len(links))
+	for _, l := range links {
+		resp = append(resp, ProfileLinkResponse{
+			Label:      l.Label,
+			URL:        l.URL,
+			Verified:   l.VerifiedAt != nil,
+			VerifiedAt: l.VerifiedAt,
+		})
 	}
+	return resp
 }
@@ -0,0 +1,15 @@
+package dto
+
+// UserSearchResponse is a user in search or typeahead results. It only
+// carries public profile fields.
+type UserSearchResponse struct {
+	ID             int64  `json:"id"`
+	Username       string `json:"username"`
+	Name           string `json:"name"`
+	Avatar         string `json:"avatar,omitempty"`
+	Bio            string `json:"bio,omitempty"`
+	Protected      bool   `json:"protected"`
+	Following      bool   `json:"following"`
+	MutualCount    int64  `json:"mutual_count,omitempty"`
+	FollowersCount int64  `json:"followers_count"`
+}
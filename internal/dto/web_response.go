@@ -1,10 +1,11 @@
 package dto
 
 type WebResponse[T any] struct {
-	Message string        `json:"message,omitempty"`
-	Data    T             `json:"data,omitempty"`
-	Paging  *PageMetaData `json:"paging,omitempty"`
-	Errors  []FieldError  `json:"errors,omitempty"`
+	Message string          `json:"message,omitempty"`
+	Data    T               `json:"data,omitempty"`
+	Paging  *PageMetaData   `json:"paging,omitempty"`
+	Cursor  *CursorMetaData `json:"cursor,omitempty"`
+	Errors  []FieldError    `json:"errors,omitempty"`
 }
 
 type PageMetaData struct {
@@ -15,6 +16,13 @@ type PageMetaData struct {
 	Links     *Links `json:"links"`
 }
 
+// CursorMetaData describes a page of a cursor-paginated listing. NextCursor is
+// empty when there are no more items.
+type CursorMetaData struct {
+	NextCursor string `json:"next_cursor,omitempty"`
+	Limit      int    `json:"limit"`
+}
+
 type Links struct {
 	Self  string `json:"self"`
 	First string `json:"first"`
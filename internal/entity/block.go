@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// Block hides two users from each other. It is one-directional in storage,
+// but either side's content is hidden from the other.
+type Block struct {
+	BlockerID int64     `gorm:"primaryKey;index" json:"blocker_id"`
+	BlockedID int64     `gorm:"primaryKey;index" json:"blocked_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// Mute hides a user's content from the muter without the muted user
+// knowing.
+type Mute struct {
+	MuterID   int64     `gorm:"primaryKey;index" json:"muter_id"`
+	MutedID   int64     `gorm:"primaryKey;index" json:"muted_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,21 @@
+package entity
+
+import "time"
+
+// Bookmark represents a user privately saving a tweet, optionally filed in
+// one of their folders.
+type Bookmark struct {
+	UserID    int64     `gorm:"primaryKey;index:idx_bookmarks_user_created,priority:1" json:"user_id"`
+	TweetID   int64     `gorm:"primaryKey;index" json:"tweet_id"`
+	FolderID  *int64    `gorm:"index" json:"folder_id,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime;index:idx_bookmarks_user_created,priority:2" json:"created_at"`
+}
+
+// BookmarkFolder is a named group of a user's bookmarks.
+type BookmarkFolder struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID    int64     `gorm:"not null;uniqueIndex:idx_bookmark_folders_user_name" json:"user_id"`
+	Name      string    `gorm:"size:50;not null;uniqueIndex:idx_bookmark_folders_user_name" json:"name"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
@@ -0,0 +1,44 @@
+package entity
+
+import "time"
+
+const (
+	ConversationTypeDirect = "direct"
+	ConversationTypeGroup  = "group"
+)
+
+// Member statuses. Conversations started by someone the member does not follow
+// stay pending in the member's requests inbox until accepted.
+const (
+	ConversationMemberAccepted = "accepted"
+	ConversationMemberPending  = "pending"
+)
+
+// Conversation is a direct message thread between two or more users.
+// DirectKey is only set for one-to-one conversations so the same pair of
+// users always resolves to a single thread. Encrypted conversations only
+// accept end-to-end encrypted message envelopes.
+type Conversation struct {
+	ID            int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Type          string    `gorm:"size:20;not null" json:"type"`
+	Name          string    `gorm:"size:100" json:"name,omitempty"`
+	CreatorID     int64     `gorm:"index;not null" json:"creator_id"`
+	Encrypted     bool      `gorm:"not null;default:false" json:"encrypted"`
+	DirectKey     *string   `gorm:"size:60;uniqueIndex" json:"-"`
+	LastMessageAt time.Time `gorm:"index" json:"last_message_at"`
+	CreatedAt     time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt     time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// ConversationMember links a user to a conversation. LeftAt is set when a
+// member leaves a group conversation or deletes a message request. The last
+// read and delivered message ids drive unread badges and read receipts.
+type ConversationMember struct {
+	ConversationID         int64      `gorm:"primaryKey;index" json:"conversation_id"`
+	UserID                 int64      `gorm:"primaryKey;index" json:"user_id"`
+	Status                 string     `gorm:"size:20;not null;default:accepted" json:"status"`
+	LastReadMessageID      int64      `gorm:"not null;default:0" json:"last_read_message_id"`
+	LastDeliveredMessageID int64      `gorm:"not null;default:0" json:"last_delivered_message_id"`
+	JoinedAt               time.Time  `gorm:"autoCreateTime" json:"joined_at"`
+	LeftAt                 *time.Time `json:"left_at,omitempty"`
+}
@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// DeviceKey is the public identity of one of a user's devices used for end-to-end
+// encrypted conversations. The server only ever stores public key material.
+type DeviceKey struct {
+	ID                    int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID                int64      `gorm:"not null;uniqueIndex:idx_device_key_user_device" json:"user_id"`
+	DeviceID              string     `gorm:"size:64;not null;uniqueIndex:idx_device_key_user_device" json:"device_id"`
+	IdentityKey           string     `gorm:"type:text;not null" json:"identity_key"`
+	SignedPreKeyID        int64      `gorm:"not null" json:"signed_prekey_id"`
+	SignedPreKey          string     `gorm:"type:text;not null" json:"signed_prekey"`
+	SignedPreKeySignature string     `gorm:"type:text;not null" json:"signed_prekey_signature"`
+	CreatedAt             time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt             time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	RevokedAt             *time.Time `json:"revoked_at,omitempty"`
+}
+
+// PreKey is a one-time public prekey uploaded by a device. Each prekey is
+// handed out to at most one peer starting a session with the device;
+// ClaimedBy records which, so that claims can be capped.
+type PreKey struct {
+	ID          int64      `gorm:"primaryKey;autoIncrement" json:"-"`
+	DeviceKeyID int64      `gorm:"not null;uniqueIndex:idx_prekey_device_key" json:"-"`
+	KeyID       int64      `gorm:"not null;uniqueIndex:idx_prekey_device_key" json:"key_id"`
+	PublicKey   string     `gorm:"type:text;not null" json:"public_key"`
+	CreatedAt   time.Time  `gorm:"autoCreateTime" json:"-"`
+	ClaimedAt   *time.Time `gorm:"index" json:"-"`
+	ClaimedBy   *int64     `json:"-"`
+}
@@ -0,0 +1,27 @@
+package entity
+
+import "time"
+
+// Draft is a tweet a user is still composing. A draft with PublishAt set is
+// a scheduled tweet: it is published, and the draft removed, once that time
+// has come. When publishing fails PublishAt is cleared and Error says why.
+type Draft struct {
+	ID             int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID         int64      `gorm:"not null;index" json:"user_id"`
+	Content        string     `gorm:"size:280;not null;default:''" json:"content"`
+	ReplyToTweetID *int64     `json:"reply_to_tweet_id,omitempty"`
+	QuotedTweetID  *int64     `json:"quoted_tweet_id,omitempty"`
+	MediaIDs       []int64    `gorm:"-" json:"media_ids"`
+	PublishAt      *time.Time `gorm:"index" json:"publish_at,omitempty"`
+	Error          string     `gorm:"size:255;not null;default:''" json:"error,omitempty"`
+	CreatedAt      time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// DraftMedia attaches an upload to a draft, in the order it will have on
+// the tweet.
+type DraftMedia struct {
+	DraftID  int64 `gorm:"primaryKey" json:"draft_id"`
+	MediaID  int64 `gorm:"primaryKey;index" json:"media_id"`
+	Position int   `gorm:"not null" json:"position"`
+}
@@ -8,3 +8,11 @@ type Follow struct {
 	FollowingID int64     `gorm:"primaryKey;index" json:"following_id"`
 	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
 }
+
+// FollowRequest is a pending request to follow a protected user. It becomes
+// a Follow once the user approves it.
+type FollowRequest struct {
+	RequesterID int64     `gorm:"primaryKey;index" json:"requester_id"`
+	TargetID    int64     `gorm:"primaryKey;index" json:"target_id"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
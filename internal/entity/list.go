@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// List is a curated group of accounts whose tweets make up the list's
+// timeline. Private lists are only visible to their owner. MemberCount and
+// SubscriberCount are maintained as members and subscribers come and go.
+type List struct {
+	ID              int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	OwnerID         int64     `gorm:"not null;index" json:"owner_id"`
+	Name            string    `gorm:"size:25;not null" json:"name"`
+	Description     string    `gorm:"size:100" json:"description,omitempty"`
+	Private         bool      `gorm:"not null;default:false" json:"private"`
+	MemberCount     int64     `gorm:"not null;default:0" json:"member_count"`
+	SubscriberCount int64     `gorm:"not null;default:0" json:"subscriber_count"`
+	CreatedAt       time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt       time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
+
+// ListMember is an account on a list.
+type ListMember struct {
+	ListID    int64     `gorm:"primaryKey" json:"list_id"`
+	UserID    int64     `gorm:"primaryKey;index" json:"user_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// ListSubscription is a user following someone else's public list.
+type ListSubscription struct {
+	ListID    int64     `gorm:"primaryKey" json:"list_id"`
+	UserID    int64     `gorm:"primaryKey;index" json:"user_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -2,11 +2,67 @@ package entity
 
 import "time"
 
-// Media stores metadata for media attached to a tweet.
+const (
+	MediaTypeImage = "image"
+	MediaTypeGif   = "gif"
+	MediaTypeVideo = "video"
+)
+
+const (
+	MediaStatusProcessing = "processing"
+	MediaStatusReady      = "ready"
+	MediaStatusFailed     = "failed"
+)
+
+// Content warning labels an uploader can put on sensitive media.
+const (
+	ContentWarningNudity    = "nudity"
+	ContentWarningViolence  = "violence"
+	ContentWarningSensitive = "sensitive"
+)
+
+const (
+	MediaVariantThumbnail = "thumbnail"
+	MediaVariantSmall     = "small"
+	MediaVariantLarge     = "large"
+)
+
+// Media stores metadata for an uploaded file. TweetID stays nil until the
+// uploader attaches the media to one of their tweets. Images are processed
+// in the background; Width, Height, Blurhash and Variants are filled in once
+// Status becomes ready. Files are only served through signed URLs of the raw
+// media endpoint, so the storage URL is never exposed.
 type Media struct {
-	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	TweetID   int64     `gorm:"index;not null" json:"tweet_id"`
-	MediaType string    `gorm:"size:50" json:"media_type"`
-	URL       string    `gorm:"size:1024" json:"url"`
-	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+	ID             int64           `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID         int64           `gorm:"index;not null;default:0" json:"user_id"`
+	TweetID        *int64          `gorm:"index" json:"tweet_id"`
+	MediaType      string          `gorm:"size:50" json:"media_type"`
+	MimeType       string          `gorm:"size:100" json:"mime_type"`
+	Size           int64           `gorm:"not null;default:0" json:"size"`
+	BlobHash       string          `gorm:"size:64;index" json:"-"`
+	StorageKey     string          `gorm:"size:512" json:"-"`
+	URL            string          `gorm:"size:1024" json:"-"`
+	Status         string          `gorm:"size:20;not null;default:ready;index" json:"status"`
+	Width          int             `gorm:"not null;default:0" json:"width"`
+	Height         int             `gorm:"not null;default:0" json:"height"`
+	Blurhash       string          `gorm:"size:64" json:"blurhash,omitempty"`
+	AltText        string          `gorm:"size:1000" json:"alt_text"`
+	Sensitive      bool            `gorm:"not null;default:false" json:"sensitive"`
+	ContentWarning string          `gorm:"size:20" json:"content_warning,omitempty"`
+	Variants       []*MediaVariant `gorm:"foreignKey:MediaID;constraint:OnDelete:CASCADE" json:"variants,omitempty"`
+	CreatedAt      time.Time       `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// MediaVariant is a resized rendition of an uploaded image.
+type MediaVariant struct {
+	ID         int64  `gorm:"primaryKey;autoIncrement" json:"-"`
+	MediaID    int64  `gorm:"uniqueIndex:idx_media_variant;not null" json:"-"`
+	Name       string `gorm:"uniqueIndex:idx_media_variant;size:20;not null" json:"name"`
+	Width      int    `gorm:"not null" json:"width"`
+	Height     int    `gorm:"not null" json:"height"`
+	MimeType   string `gorm:"size:100" json:"mime_type"`
+	Size       int64  `gorm:"not null" json:"size"`
+	BlobHash   string `gorm:"size:64;index" json:"-"`
+	StorageKey string `gorm:"size:512" json:"-"`
+	URL        string `gorm:"size:1024" json:"-"`
 }
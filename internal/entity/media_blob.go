@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// MediaBlob is a stored file addressed by the SHA-256 of its content.
+// Identical uploads and renditions share one blob; RefCount counts the media
+// and media variants pointing at it. Blobs left without references are
+// deleted by the media garbage collector once UpdatedAt is older than the
+// grace period.
+type MediaBlob struct {
+	Hash       string    `gorm:"primaryKey;size:64" json:"hash"`
+	StorageKey string    `gorm:"size:512;not null" json:"-"`
+	MimeType   string    `gorm:"size:100" json:"mime_type"`
+	Size       int64     `gorm:"not null" json:"size"`
+	RefCount   int64     `gorm:"not null;default:0;index" json:"ref_count"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
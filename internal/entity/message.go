@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// Message is a single direct message sent to a conversation.
+type Message struct {
+	ID             int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	ConversationID int64     `gorm:"index;not null" json:"conversation_id"`
+	SenderID       int64     `gorm:"index;not null" json:"sender_id"`
+	Content        string    `gorm:"type:text" json:"content"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// MessageMedia is the many-to-many join between messages and media.
+type MessageMedia struct {
+	MessageID int64 `gorm:"primaryKey;index" json:"message_id"`
+	MediaID   int64 `gorm:"primaryKey;index" json:"media_id"`
+}
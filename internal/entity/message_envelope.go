@@ -0,0 +1,13 @@
+package entity
+
+// MessageEnvelope carries the ciphertext of an end-to-end encrypted message
+// for a single recipient device.
+type MessageEnvelope struct {
+	ID                int64  `gorm:"primaryKey;autoIncrement" json:"-"`
+	MessageID         int64  `gorm:"not null;index" json:"message_id"`
+	SenderDeviceID    string `gorm:"size:64;not null" json:"sender_device_id"`
+	RecipientUserID   int64  `gorm:"not null;index:idx_envelope_recipient" json:"recipient_user_id"`
+	RecipientDeviceID string `gorm:"size:64;not null;index:idx_envelope_recipient" json:"recipient_device_id"`
+	Type              string `gorm:"size:20;not null" json:"type"`
+	Ciphertext        string `gorm:"type:text;not null" json:"ciphertext"`
+}
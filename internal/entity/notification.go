@@ -2,13 +2,29 @@ package entity
 
 import "time"
 
-// Notification represents a notification sent to a user.
+const (
+	NotificationTypeKeyChange            = "key_change"
+	NotificationTypeSearchAlert          = "search_alert"
+	NotificationTypeScheduledTweetFailed = "scheduled_tweet_failed"
+	NotificationTypePollClosed           = "poll_closed"
+	NotificationTypeListAdded            = "list_added"
+)
+
+// Notification represents a notification sent to a user. A search alert
+// covers MatchCount new tweets of a saved search, TweetID being the newest.
+// A failed scheduled tweet points at the draft that kept it. A closed poll
+// points at its tweet. Being added to a list points at the list.
 type Notification struct {
-	ID          int64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	RecipientID int64     `gorm:"index;not null" json:"recipient_id"`
-	SenderID    *int64    `json:"sender_id,omitempty"`
-	Type        string    `gorm:"size:50" json:"type"`
-	TweetID     *int64    `gorm:"index" json:"tweet_id,omitempty"`
-	IsRead      bool      `gorm:"default:false" json:"is_read"`
-	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	ID             int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	RecipientID    int64     `gorm:"index;not null" json:"recipient_id"`
+	SenderID       *int64    `json:"sender_id,omitempty"`
+	Type           string    `gorm:"size:50" json:"type"`
+	TweetID        *int64    `gorm:"index" json:"tweet_id,omitempty"`
+	ConversationID *int64    `gorm:"index" json:"conversation_id,omitempty"`
+	SavedSearchID  *int64    `gorm:"index" json:"saved_search_id,omitempty"`
+	DraftID        *int64    `json:"draft_id,omitempty"`
+	ListID         *int64    `gorm:"index" json:"list_id,omitempty"`
+	MatchCount     int       `gorm:"not null;default:0" json:"match_count,omitempty"`
+	IsRead         bool      `gorm:"default:false" json:"is_read"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
 }
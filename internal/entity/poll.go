@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// Poll is a question attached to a tweet. Votes are counted on the options
+// and in TotalVotes as they are cast, so results never need to be tallied.
+// A poll is open until EndsAt; ClosedAt is set once its author and voters
+// have been told it ended.
+type Poll struct {
+	ID         int64         `gorm:"primaryKey;autoIncrement" json:"id"`
+	TweetID    int64         `gorm:"not null;uniqueIndex" json:"tweet_id"`
+	EndsAt     time.Time     `gorm:"not null;index" json:"ends_at"`
+	ClosedAt   *time.Time    `json:"closed_at,omitempty"`
+	TotalVotes int64         `gorm:"not null;default:0" json:"total_votes"`
+	Options    []*PollOption `gorm:"foreignKey:PollID;constraint:OnDelete:CASCADE" json:"options"`
+	CreatedAt  time.Time     `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// PollOption is one of the answers of a poll, in the order of Position.
+type PollOption struct {
+	ID       int64  `gorm:"primaryKey;autoIncrement" json:"id"`
+	PollID   int64  `gorm:"not null;index" json:"poll_id"`
+	Position int    `gorm:"not null" json:"position"`
+	Label    string `gorm:"size:25;not null" json:"label"`
+	Votes    int64  `gorm:"not null;default:0" json:"votes"`
+}
+
+// PollVote records the option a user voted for. A user votes once per poll.
+type PollVote struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	PollID    int64     `gorm:"not null;uniqueIndex:idx_poll_votes_poll_user" json:"poll_id"`
+	UserID    int64     `gorm:"not null;uniqueIndex:idx_poll_votes_poll_user;index" json:"user_id"`
+	OptionID  int64     `gorm:"not null" json:"option_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
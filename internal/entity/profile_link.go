@@ -0,0 +1,17 @@
+package entity
+
+import "time"
+
+// ProfileLink is one of the custom links shown on a user's profile. A link
+// is verified when the page it points to links back to the profile with
+// rel="me".
+type ProfileLink struct {
+	ID         int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID     int64      `gorm:"not null;uniqueIndex:idx_profile_links_user_position" json:"user_id"`
+	Position   int        `gorm:"not null;uniqueIndex:idx_profile_links_user_position" json:"position"`
+	Label      string     `gorm:"size:30;not null" json:"label"`
+	URL        string     `gorm:"size:1024;not null" json:"url"`
+	VerifiedAt *time.Time `json:"verified_at,omitempty"`
+	CheckedAt  *time.Time `gorm:"index" json:"checked_at,omitempty"`
+	CreatedAt  time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
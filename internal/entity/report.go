@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+const (
+	ReportTargetConversation = "conversation"
+)
+
+const (
+	ReportStatusOpen     = "open"
+	ReportStatusResolved = "resolved"
+)
+
+// Report is a user complaint queued for moderator review.
+type Report struct {
+	ID         int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	ReporterID int64     `gorm:"index;not null" json:"reporter_id"`
+	TargetType string    `gorm:"size:50;not null;index:idx_report_target" json:"target_type"`
+	TargetID   int64     `gorm:"not null;index:idx_report_target" json:"target_id"`
+	Reason     string    `gorm:"size:50;not null" json:"reason"`
+	Comment    string    `gorm:"type:text" json:"comment,omitempty"`
+	Status     string    `gorm:"size:20;not null;default:open;index" json:"status"`
+	CreatedAt  time.Time `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+}
@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// SavedSearch is a tweet search a user kept for later, in the same syntax as
+// the search endpoint. Subscribed searches are evaluated periodically and
+// alert their owner about tweets newer than LastMatchedTweetID.
+type SavedSearch struct {
+	ID                 int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID             int64      `gorm:"not null;uniqueIndex:idx_saved_searches_user_query" json:"user_id"`
+	Name               string     `gorm:"size:100" json:"name"`
+	Query              string     `gorm:"size:500;not null;uniqueIndex:idx_saved_searches_user_query" json:"query"`
+	Subscribed         bool       `gorm:"not null;default:false;index" json:"subscribed"`
+	LastMatchedTweetID int64      `gorm:"not null;default:0" json:"-"`
+	LastAlertedAt      *time.Time `json:"last_alerted_at,omitempty"`
+	CreatedAt          time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt          time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+}
@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+// Suggestion is an account recommended for a user to follow, precomputed
+// from the follow graph. MutualCount is how many of the accounts the user
+// follows follow it, SharedHashtags how many hashtags both recently tweeted.
+type Suggestion struct {
+	UserID         int64     `gorm:"primaryKey;index:idx_suggestions_user_score,priority:1" json:"user_id"`
+	SuggestedID    int64     `gorm:"primaryKey;index" json:"suggested_id"`
+	Score          float64   `gorm:"not null;index:idx_suggestions_user_score,priority:2,sort:desc" json:"score"`
+	MutualCount    int64     `gorm:"not null" json:"mutual_count"`
+	SharedHashtags int64     `gorm:"not null" json:"shared_hashtags"`
+	CreatedAt      time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// SuggestionDismissal records that a user does not want an account
+// suggested again.
+type SuggestionDismissal struct {
+	UserID      int64     `gorm:"primaryKey" json:"user_id"`
+	DismissedID int64     `gorm:"primaryKey" json:"dismissed_id"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
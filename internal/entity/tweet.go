@@ -2,13 +2,28 @@ package entity
 
 import "time"
 
-// Tweet represents a post (tweet) created by a user.
+// Tweet represents a post (tweet) created by a user. ConversationID is the id
+// of the thread's root tweet, the tweet's own id for a tweet that is not a
+// reply. A deleted tweet that has replies is kept, emptied, as a tombstone
+// with DeletedAt set so that its thread stays connected.
+//
+// A retweet has no content of its own and points at the original with
+// RetweetedTweetID; a user retweets a tweet at most once. A quote tweet is an
+// ordinary tweet whose QuotedTweetID points at the tweet it comments on.
+//
+// EditCount counts the edits of the content, the last one at EditedAt; the
+// content before each edit is kept as a TweetVersion.
 type Tweet struct {
-	ID               int64     `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserID           int64     `gorm:"not null;index" json:"user_id"`
-	Content          string    `gorm:"size:280;not null" json:"content"`
-	ReplyToTweetID   *int64    `gorm:"index" json:"reply_to_tweet_id,omitempty"`
-	RetweetedTweetID *int64    `gorm:"index" json:"retweeted_tweet_id,omitempty"`
-	CreatedAt        time.Time `gorm:"autoCreateTime" json:"created_at"`
-	UpdatedAt        time.Time `gorm:"autoUpdateTime" json:"updated_at"`
+	ID               int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserID           int64      `gorm:"not null;index" json:"user_id"`
+	Content          string     `gorm:"size:280;not null" json:"content"`
+	ReplyToTweetID   *int64     `gorm:"index" json:"reply_to_tweet_id,omitempty"`
+	RetweetedTweetID *int64     `gorm:"index" json:"retweeted_tweet_id,omitempty"`
+	QuotedTweetID    *int64     `gorm:"index" json:"quoted_tweet_id,omitempty"`
+	ConversationID   int64      `gorm:"not null;default:0;index" json:"conversation_id"`
+	EditCount        int        `gorm:"not null;default:0" json:"edit_count"`
+	EditedAt         *time.Time `json:"edited_at,omitempty"`
+	CreatedAt        time.Time  `gorm:"autoCreateTime" json:"created_at"`
+	UpdatedAt        time.Time  `gorm:"autoUpdateTime" json:"updated_at"`
+	DeletedAt        *time.Time `gorm:"index" json:"-"`
 }
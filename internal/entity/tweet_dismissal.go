@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// TweetDismissal records that a user is not interested in a tweet. The tweet
+// is left out of the user's home timeline, and AuthorID's other tweets rank
+// lower in it. Dismissals outlive the tweet, since they are feedback on its
+// author too.
+type TweetDismissal struct {
+	UserID    int64     `gorm:"primaryKey" json:"user_id"`
+	TweetID   int64     `gorm:"primaryKey;index" json:"tweet_id"`
+	AuthorID  int64     `gorm:"not null;index" json:"author_id"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
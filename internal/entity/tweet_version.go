@@ -0,0 +1,14 @@
+package entity
+
+import "time"
+
+// TweetVersion is the content a tweet had before one of its edits. Version
+// numbers start at 1 for the content the tweet was posted with; CreatedAt
+// is when that content was posted or written by an edit.
+type TweetVersion struct {
+	ID        int64     `gorm:"primaryKey;autoIncrement" json:"-"`
+	TweetID   int64     `gorm:"not null;uniqueIndex:idx_tweet_versions_tweet_version" json:"tweet_id"`
+	Version   int       `gorm:"not null;uniqueIndex:idx_tweet_versions_tweet_version" json:"version"`
+	Content   string    `gorm:"size:280;not null" json:"content"`
+	CreatedAt time.Time `gorm:"not null" json:"created_at"`
+}
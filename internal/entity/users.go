@@ -2,17 +2,52 @@ package entity
 
 import "time"
 
+// Direct message privacy settings: who may start a conversation with the user.
+const (
+	DMPrivacyEveryone  = "everyone"
+	DMPrivacyFollowers = "followers"
+	DMPrivacyNobody    = "nobody"
+)
+
+// Sensitive media preferences: how media flagged as sensitive by its
+// uploader is presented to the user.
+const (
+	SensitiveMediaShow = "show"
+	SensitiveMediaBlur = "blur"
+	SensitiveMediaHide = "hide"
+)
+
+// Birthday visibility settings: who may see the user's birthday.
+const (
+	BirthdayVisibilityPublic    = "public"
+	BirthdayVisibilityFollowers = "followers"
+	BirthdayVisibilityPrivate   = "private"
+)
+
 // User represents a user in the system. Includes GORM tags for migrations.
 // If your DB column names differ, adjust the `gorm:"column:..."` tags.
 type User struct {
-	ID        int64     `gorm:"primaryKey;autoIncrement" db:"id" json:"id"`
-	Email     string    `gorm:"size:255;uniqueIndex;not null" db:"email" json:"email"`
-	Name      string    `gorm:"size:255" db:"name" json:"name"`
-	Username  string    `gorm:"size:100;uniqueIndex;not null" db:"username" json:"username"`
-	Avatar    string    `gorm:"size:1024" db:"avatar" json:"avatar,omitempty"`
-	Banner    string    `gorm:"size:1024" db:"banner" json:"banner,omitempty"`
-	Bio       string    `gorm:"type:text" db:"bio" json:"bio,omitempty"`
-	Password  string    `gorm:"size:255;not null" db:"password" json:"-"`
-	CreatedAt time.Time `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
-	UpdatedAt time.Time `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
+	ID                 int64      `gorm:"primaryKey;autoIncrement" db:"id" json:"id"`
+	Email              string     `gorm:"size:255;uniqueIndex;not null" db:"email" json:"email"`
+	Name               string     `gorm:"size:255" db:"name" json:"name"`
+	Username           string     `gorm:"size:100;uniqueIndex;not null" db:"username" json:"username"`
+	Avatar             string     `gorm:"size:1024" db:"avatar" json:"avatar,omitempty"`
+	Banner             string     `gorm:"size:1024" db:"banner" json:"banner,omitempty"`
+	Bio                string     `gorm:"type:text" db:"bio" json:"bio,omitempty"`
+	Location           string     `gorm:"size:100" db:"location" json:"location,omitempty"`
+	Website            string     `gorm:"size:1024" db:"website" json:"website,omitempty"`
+	PinnedTweetID      *int64     `gorm:"index" db:"pinned_tweet_id" json:"pinned_tweet_id,omitempty"`
+	Birthday           *time.Time `gorm:"type:date" db:"birthday" json:"birthday,omitempty"`
+	BirthdayVisibility string     `gorm:"size:10;not null;default:private" db:"birthday_visibility" json:"birthday_visibility"`
+	DMPrivacy          string     `gorm:"size:20;not null;default:everyone" db:"dm_privacy" json:"dm_privacy"`
+	HideReadReceipts   bool       `gorm:"not null;default:false" db:"hide_read_receipts" json:"hide_read_receipts"`
+	RequireAltText     bool       `gorm:"not null;default:false" db:"require_alt_text" json:"require_alt_text"`
+	SensitiveMedia     string     `gorm:"size:10;not null;default:blur" db:"sensitive_media" json:"sensitive_media"`
+	Protected          bool       `gorm:"not null;default:false" db:"protected" json:"protected"`
+	FollowersCount     int64      `gorm:"not null;default:0" db:"followers_count" json:"followers_count"`
+	FollowingCount     int64      `gorm:"not null;default:0" db:"following_count" json:"following_count"`
+	TweetsCount        int64      `gorm:"not null;default:0" db:"tweets_count" json:"tweets_count"`
+	Password           string     `gorm:"size:255;not null" db:"password" json:"-"`
+	CreatedAt          time.Time  `gorm:"autoCreateTime" db:"created_at" json:"created_at"`
+	UpdatedAt          time.Time  `gorm:"autoUpdateTime" db:"updated_at" json:"updated_at"`
 }
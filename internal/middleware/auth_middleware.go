@@ -44,6 +44,33 @@ func AuthMiddleware() echo.MiddlewareFunc {
 	return NewAuthMiddleware(defaultJwt).Authorization()
 }
 
+// OptionalAuthMiddleware identifies the user like AuthMiddleware when the
+// request carries a valid access token, but lets anonymous requests through.
+func OptionalAuthMiddleware() echo.MiddlewareFunc {
+	if defaultJwt == nil {
+		return func(next echo.HandlerFunc) echo.HandlerFunc {
+			return next
+		}
+	}
+	return NewAuthMiddleware(defaultJwt).OptionalAuthorization()
+}
+
+func (m *AuthMiddlewareImpl) OptionalAuthorization() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			accessToken, err := m.parseAccessToken(ctx)
+			if err != nil {
+				return next(ctx)
+			}
+
+			if claims, err := m.jwtUtil.Parse(accessToken); err == nil {
+				ctx.Set(constant.CTX_USER_ID, claims.UserID)
+			}
+			return next(ctx)
+		}
+	}
+}
+
 func (m *AuthMiddlewareImpl) Authorization() echo.MiddlewareFunc {
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(ctx echo.Context) error {
@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStorageRoundTrip(t *testing.T) {
+	root := t.TempDir()
+	s, err := NewLocalStorage(root, "http://cdn.example/media/")
+	if err != nil {
+		t.Fatalf("NewLocalStorage() error = %v", err)
+	}
+	ctx := context.Background()
+	const key, content = "ab/cd/blob", "hello, media"
+
+	if err := s.Put(ctx, key, strings.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got, err := readObject(s.Get(ctx, key)); err != nil || got != content {
+		t.Errorf("Get() = %q, %v, want %q", got, err, content)
+	}
+	if got, err := readObject(s.GetRange(ctx, key, 7, 5)); err != nil || got != "media" {
+		t.Errorf("GetRange(7, 5) = %q, %v, want %q", got, err, "media")
+	}
+	if got, err := readObject(s.GetRange(ctx, key, 7, -1)); err != nil || got != "media" {
+		t.Errorf("GetRange(7, -1) = %q, %v, want %q", got, err, "media")
+	}
+	if got, want := s.URL(key), "http://cdn.example/media/ab/cd/blob"; got != want {
+		t.Errorf("URL() = %q, want %q", got, want)
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.Get(ctx, key); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrObjectNotFound", err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Errorf("Delete() of a missing key error = %v, want nil", err)
+	}
+}
+
+func TestLocalStorageKeepsKeysBelowRoot(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "root")
+	s, err := NewLocalStorage(root, "")
+	if err != nil {
+		t.Fatalf("NewLocalStorage() error = %v", err)
+	}
+
+	if err := s.Put(context.Background(), "../escaped", strings.NewReader("x"), 1, "text/plain"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(parent, "escaped")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("file written outside the storage root")
+	}
+	if _, err := os.Stat(filepath.Join(root, "escaped")); err != nil {
+		t.Errorf("file not written below the storage root: %v", err)
+	}
+}
+
+// readObject reads a whole object returned by Get or GetRange.
+func readObject(rc io.ReadCloser, err error) (string, error) {
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	return string(data), err
+}
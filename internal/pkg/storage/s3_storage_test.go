@@ -0,0 +1,134 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"TwClone/internal/config"
+)
+
+// fakeS3 is a minimal path-style S3-compatible server keeping objects in
+// memory. It checks that requests are signed for the expected access key.
+type fakeS3 struct {
+	t         *testing.T
+	bucket    string
+	accessKey string
+
+	mu      sync.Mutex
+	objects map[string]string
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, s3Algorithm+" Credential="+f.accessKey+"/") || !strings.Contains(auth, "Signature=") {
+		f.t.Errorf("%s %s: missing or malformed Authorization %q", r.Method, r.URL.Path, auth)
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	if r.Header.Get("X-Amz-Date") == "" || r.Header.Get("X-Amz-Content-Sha256") == "" {
+		f.t.Errorf("%s %s: missing SigV4 headers", r.Method, r.URL.Path)
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+f.bucket+"/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.objects[key] = string(data)
+		f.types[key] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		obj, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		if rng := r.Header.Get("Range"); rng != "" {
+			var start, end int
+			if n, _ := fmt.Sscanf(rng, "bytes=%d-%d", &start, &end); n < 2 {
+				end = len(obj) - 1
+			}
+			w.Header().Set("Content-Range", "bytes "+strconv.Itoa(start)+"-"+strconv.Itoa(end)+"/"+strconv.Itoa(len(obj)))
+			w.WriteHeader(http.StatusPartialContent)
+			io.WriteString(w, obj[start:end+1])
+			return
+		}
+		io.WriteString(w, obj)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func TestS3StorageRoundTrip(t *testing.T) {
+	fake := &fakeS3{t: t, bucket: "media", accessKey: "test-key", objects: map[string]string{}, types: map[string]string{}}
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+
+	s := NewS3Storage(&config.StorageConfig{
+		S3Endpoint:     srv.URL,
+		S3Region:       "us-east-1",
+		S3Bucket:       "media",
+		S3AccessKey:    "test-key",
+		S3SecretKey:    "test-secret",
+		S3UsePathStyle: true,
+	})
+	ctx := context.Background()
+	const key, content = "ab/cd/blob name", "hello, media"
+
+	if err := s.Put(ctx, key, strings.NewReader(content), int64(len(content)), "image/png"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got := fake.objects[key]; got != content {
+		t.Errorf("stored object = %q, want %q", got, content)
+	}
+	if got := fake.types[key]; got != "image/png" {
+		t.Errorf("stored content type = %q, want image/png", got)
+	}
+	if got, err := readObject(s.Get(ctx, key)); err != nil || got != content {
+		t.Errorf("Get() = %q, %v, want %q", got, err, content)
+	}
+	if got, err := readObject(s.GetRange(ctx, key, 7, 5)); err != nil || got != "media" {
+		t.Errorf("GetRange(7, 5) = %q, %v, want %q", got, err, "media")
+	}
+	if got, err := readObject(s.GetRange(ctx, key, 7, -1)); err != nil || got != "media" {
+		t.Errorf("GetRange(7, -1) = %q, %v, want %q", got, err, "media")
+	}
+
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := s.Get(ctx, key); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrObjectNotFound", err)
+	}
+}
+
+func TestS3StorageReportsServerErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "<Error><Code>AccessDenied</Code></Error>")
+	}))
+	defer srv.Close()
+
+	s := NewS3Storage(&config.StorageConfig{S3Endpoint: srv.URL, S3Bucket: "media", S3UsePathStyle: true})
+	err := s.Put(context.Background(), "key", strings.NewReader("x"), 1, "text/plain")
+	if err == nil || !strings.Contains(err.Error(), "AccessDenied") {
+		t.Errorf("Put() error = %v, want the server's AccessDenied", err)
+	}
+}
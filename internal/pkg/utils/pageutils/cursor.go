@@ -0,0 +1,122 @@
+package pageutils
+
+import (
+	"encoding/base64"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ParseLimit parses a page size query value, falling back to def when it is
+// missing or invalid and capping it at max.
+func ParseLimit(raw string, def, max int) int {
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 {
+		return def
+	}
+	if limit > max {
+		return max
+	}
+	return limit
+}
+
+// EncodeCursor turns the id of the last item of a page into an opaque cursor.
+func EncodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+// DecodeCursor reverses EncodeCursor. An empty cursor decodes to zero.
+func DecodeCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+
+	id, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidCursor
+	}
+	return id, nil
+}
+
+// EncodeTimeCursor turns the time and id of the last item of a page ordered
+// by time into an opaque cursor. The id breaks ties between equal times.
+func EncodeTimeCursor(t time.Time, id int64) string {
+	raw := strconv.FormatInt(t.UnixMicro(), 10) + ":" + strconv.FormatInt(id, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeTimeCursor reverses EncodeTimeCursor. An empty cursor decodes to
+// the zero time and id.
+func DecodeTimeCursor(cursor string) (time.Time, int64, error) {
+	if cursor == "" {
+		return time.Time{}, 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	rawTime, rawID, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	micros, err := strconv.ParseInt(rawTime, 10, 64)
+	if err != nil || micros <= 0 {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil || id <= 0 {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+	return time.UnixMicro(micros), id, nil
+}
+
+// EncodeScoreCursor turns the score and id of the last item of a page
+// ranked as of t into an opaque cursor, so that the next page can be
+// ranked as of the same time and continue below that score. The id breaks
+// ties between equal scores.
+func EncodeScoreCursor(t time.Time, score float64, id int64) string {
+	raw := strconv.FormatInt(t.UnixMicro(), 10) + ":" +
+		strconv.FormatFloat(score, 'g', -1, 64) + ":" +
+		strconv.FormatInt(id, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeScoreCursor reverses EncodeScoreCursor. An empty cursor decodes to
+// the zero time, score and id.
+func DecodeScoreCursor(cursor string) (time.Time, float64, int64, error) {
+	if cursor == "" {
+		return time.Time{}, 0, 0, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, 0, ErrInvalidCursor
+	}
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 {
+		return time.Time{}, 0, 0, ErrInvalidCursor
+	}
+	micros, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil || micros <= 0 {
+		return time.Time{}, 0, 0, ErrInvalidCursor
+	}
+	score, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || math.IsNaN(score) || math.IsInf(score, 0) || score < 0 {
+		return time.Time{}, 0, 0, ErrInvalidCursor
+	}
+	id, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || id <= 0 {
+		return time.Time{}, 0, 0, ErrInvalidCursor
+	}
+	return time.UnixMicro(micros), score, id, nil
+}
@@ -0,0 +1,220 @@
+package searchutils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"TwClone/internal/pkg/utils/textutils"
+)
+
+const dateLayout = "2006-01-02"
+
+var (
+	ErrEmptyQuery   = errors.New("empty search query")
+	ErrInvalidQuery = errors.New("invalid search query")
+)
+
+// Query is a parsed tweet search. Words and phrases are matched with full
+// text search; the other fields are filters. Usernames and hashtags are
+// normalised the way they are stored.
+type Query struct {
+	// Text is the free-text part in websearch_to_tsquery syntax: plain
+	// words, "exact phrases" and -excluded words or phrases.
+	Text string
+
+	From        []string
+	NotFrom     []string
+	To          []string
+	NotTo       []string
+	Hashtags    []string
+	NotHashtags []string
+
+	Since *time.Time
+	Until *time.Time
+
+	// HasMedia and Replies are nil when not filtered on, true for has:media
+	// and filter:replies and false for their negations.
+	HasMedia *bool
+	Replies  *bool
+	MinLikes int64
+}
+
+// Parse parses a search such as
+//
+//	golang -rust "generics are here" from:gopher #release since:2024-01-01 has:media min_likes:10
+//
+// Operators can be negated with a leading -. since: is inclusive and until:
+// exclusive, both as YYYY-MM-DD dates in UTC. Unknown operators are searched
+// for as plain words.
+func Parse(raw string) (*Query, error) {
+	q := &Query{}
+	var text []string
+	positive := false
+
+	for _, tok := range tokenize(raw) {
+		if tok.phrase {
+			if tok.negated {
+				text = append(text, `-"`+tok.value+`"`)
+			} else {
+				text = append(text, `"`+tok.value+`"`)
+				positive = true
+			}
+			continue
+		}
+
+		negated := tok.negated
+		value := tok.value
+		if strings.HasPrefix(value, "#") || strings.HasPrefix(value, "\uff03") {
+			tag := textutils.FoldHashtag(value)
+			if tag == "" {
+				continue
+			}
+			if negated {
+				q.NotHashtags = append(q.NotHashtags, tag)
+			} else {
+				q.Hashtags = append(q.Hashtags, tag)
+				positive = true
+			}
+			continue
+		}
+
+		op, arg, isOp := strings.Cut(value, ":")
+		if isOp && arg != "" {
+			handled, err := q.applyOperator(strings.ToLower(op), arg, negated)
+			if err != nil {
+				return nil, err
+			}
+			if handled {
+				if !negated {
+					positive = true
+				}
+				continue
+			}
+		}
+
+		if negated {
+			text = append(text, "-"+value)
+		} else {
+			text = append(text, value)
+			positive = true
+		}
+	}
+
+	// a search made only of exclusions would scan every tweet
+	if !positive {
+		return nil, ErrEmptyQuery
+	}
+	q.Text = strings.Join(text, " ")
+	return q, nil
+}
+
+// applyOperator applies op:arg to the query and reports whether op is a
+// known operator.
+func (q *Query) applyOperator(op, arg string, negated bool) (bool, error) {
+	switch op {
+	case "from", "to":
+		name := strings.ToLower(strings.TrimPrefix(arg, "@"))
+		if name == "" {
+			return false, ErrInvalidQuery
+		}
+		switch {
+		case op == "from" && negated:
+			q.NotFrom = append(q.NotFrom, name)
+		case op == "from":
+			q.From = append(q.From, name)
+		case negated:
+			q.NotTo = append(q.NotTo, name)
+		default:
+			q.To = append(q.To, name)
+		}
+	case "since", "until":
+		if negated {
+			return false, ErrInvalidQuery
+		}
+		t, err := time.Parse(dateLayout, arg)
+		if err != nil {
+			return false, ErrInvalidQuery
+		}
+		if op == "since" {
+			q.Since = &t
+		} else {
+			q.Until = &t
+		}
+	case "has":
+		if strings.ToLower(arg) != "media" {
+			return false, ErrInvalidQuery
+		}
+		v := !negated
+		q.HasMedia = &v
+	case "filter":
+		if strings.ToLower(arg) != "replies" {
+			return false, ErrInvalidQuery
+		}
+		v := !negated
+		q.Replies = &v
+	case "min_likes":
+		n, err := strconv.ParseInt(arg, 10, 64)
+		if err != nil || n < 0 || negated {
+			return false, ErrInvalidQuery
+		}
+		q.MinLikes = n
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+type token struct {
+	value   string
+	phrase  bool
+	negated bool
+}
+
+// tokenize splits a search on whitespace, keeping double-quoted phrases
+// together. An unterminated quote runs to the end of the input.
+func tokenize(raw string) []token {
+	var tokens []token
+	runes := []rune(raw)
+	for i := 0; i < len(runes); {
+		if unicode.IsSpace(runes[i]) {
+			i++
+			continue
+		}
+
+		negated := false
+		if runes[i] == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) {
+			negated = true
+			i++
+		}
+
+		if runes[i] == '"' {
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			// quotes and backslashes are the only characters with a meaning
+			// inside a websearch phrase
+			phrase := strings.Join(strings.FieldsFunc(string(runes[i+1:end]), func(r rune) bool {
+				return unicode.IsSpace(r) || r == '\\'
+			}), " ")
+			if phrase != "" {
+				tokens = append(tokens, token{value: phrase, phrase: true, negated: negated})
+			}
+			i = end + 1
+			continue
+		}
+
+		end := i
+		for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '"' {
+			end++
+		}
+		if end > i {
+			tokens = append(tokens, token{value: string(runes[i:end]), negated: negated})
+		}
+		i = end
+	}
+	return tokens
+}
@@ -0,0 +1,99 @@
+package textutils
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Longest hashtag and username, in runes, that is recognised. Longer tokens
+// are ignored rather than truncated.
+const (
+	maxHashtagLength  = 100
+	maxUsernameLength = 100
+)
+
+// Hashtags returns the distinct #hashtags of text in order of appearance.
+// Tags are NFC-normalised and case-folded so that #Café, #CAFÉ and #café
+// are the same tag. Tags made only of digits (#1) are not hashtags.
+func Hashtags(text string) []string {
+	var tags []string
+	seen := make(map[string]struct{})
+	for _, token := range scanTokens(text, '#', '\uff03', maxHashtagLength) {
+		if !strings.ContainsFunc(token, unicode.IsLetter) {
+			continue
+		}
+		tag := FoldHashtag(token)
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+		seen[tag] = struct{}{}
+		tags = append(tags, tag)
+	}
+	return tags
+}
+
+// FoldHashtag returns the canonical form of a hashtag, without its leading
+// #, under which it is stored and compared.
+func FoldHashtag(tag string) string {
+	tag = strings.TrimLeft(tag, "#\uff03")
+	// a Caser keeps state and cannot be shared between goroutines
+	return cases.Fold().String(norm.NFC.String(tag))
+}
+
+// Mentions returns the distinct @usernames of text in order of appearance,
+// lower-cased for case-insensitive lookup.
+func Mentions(text string) []string {
+	var names []string
+	seen := make(map[string]struct{})
+	for _, token := range scanTokens(text, '@', '\uff20', maxUsernameLength) {
+		name := strings.ToLower(norm.NFC.String(token))
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		names = append(names, name)
+	}
+	return names
+}
+
+// scanTokens returns the words introduced by sigil (or its full-width form)
+// in text. A sigil only starts a token at the beginning of text or after a
+// non-word rune, so e-mail addresses and HTML entities like &#39; are
+// skipped.
+func scanTokens(text string, sigil, wideSigil rune, maxLength int) []string {
+	var tokens []string
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != sigil && runes[i] != wideSigil {
+			continue
+		}
+		if i > 0 && (isWordRune(runes[i-1]) || runes[i-1] == '&') {
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) && isWordRune(runes[end]) {
+			end++
+		}
+		// a sigil directly after the token (#a#b, a@b@c) makes it ambiguous
+		if end < len(runes) && (runes[end] == sigil || runes[end] == wideSigil) {
+			i = end
+			continue
+		}
+		if n := end - i - 1; n > 0 && n <= maxLength {
+			tokens = append(tokens, string(runes[i+1:end]))
+		}
+		i = end - 1
+	}
+	return tokens
+}
+
+// isWordRune reports whether r can be part of a hashtag or username: any
+// letter, combining mark or digit of any script, the underscore, and the
+// zero-width joiner used by some scripts.
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsMark(r) || unicode.IsDigit(r) || r == '_' || r == '\u200d'
+}
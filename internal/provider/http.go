@@ -6,6 +6,7 @@ import (
 	"TwClone/internal/config"
 	"TwClone/internal/controller"
 	"TwClone/internal/pkg/utils/jwtutils"
+	"TwClone/internal/pkg/utils/signutils"
 
 	echo "github.com/labstack/echo/v4"
 )
@@ -18,16 +19,31 @@ func BootstrapHttp(cfg *config.Config, router *echo.Echo) {
 	// API v1 group for all controllers
 	api := router.Group("/api/v1")
 
+	mediaURLSigner := signutils.NewMediaURLSigner(cfg.Media)
+
 	// Register controllers (in-place constructors)
 	controller.NewAuthController(cfg).Route(api)
 	controller.NewUserController().Route(api)
 	controller.NewLikeController().Route(api)
 	controller.NewFollowController().Route(api)
+	controller.NewBlockController().Route(api)
 	controller.NewHashtagController().Route(api)
-	controller.NewMediaController().Route(api)
+	controller.NewMediaController(cfg.Media, store, mediaBlobStore, mediaProcessor, mediaURLSigner).Route(api)
 	controller.NewMentionController().Route(api)
 	controller.NewNotificationController().Route(api)
 	controller.NewTweetHashtagController().Route(api)
+	controller.NewConversationController().Route(api)
+	controller.NewKeyController().Route(api)
+	controller.NewTweetController(cfg.Tweet, cfg.Poll, dataStore, mediaURLSigner).Route(api)
+	controller.NewTrendController(trendService, mediaURLSigner).Route(api)
+	controller.NewSearchController(mediaURLSigner).Route(api)
+	controller.NewSavedSearchController(cfg.SavedSearch).Route(api)
+	controller.NewDraftController(cfg.Draft).Route(api)
+	controller.NewBookmarkController(mediaURLSigner).Route(api)
+	controller.NewSuggestionController(suggester).Route(api)
+	controller.NewListController(mediaURLSigner).Route(api)
+	controller.NewTimelineController(cfg.Timeline, mediaURLSigner).Route(api)
+	controller.NewReportController(cfg.Moderation).Route(api)
 
 	// Ensure OPTIONS preflight requests are handled even if a specific route isn't matched.
 	// Echo's CORS middleware will attach the necessary CORS headers; this handler returns 200 for OPTIONS.
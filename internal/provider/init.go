@@ -3,11 +3,25 @@ package provider
 import (
 	"TwClone/internal/config"
 	"TwClone/internal/database"
+	"TwClone/internal/pkg/storage"
+	"TwClone/internal/repository"
+	"TwClone/internal/usecase"
 	"gorm.io/gorm"
 )
 
 var (
-	db *gorm.DB
+	db             *gorm.DB
+	dataStore      repository.DataStore
+	store          storage.Storage
+	mediaBlobStore *usecase.MediaBlobStore
+	mediaProcessor *usecase.MediaProcessor
+	mediaGC        *usecase.MediaGC
+	trendService   *usecase.TrendService
+	searchAlerter  *usecase.SearchAlerter
+	draftPublisher *usecase.DraftPublisher
+	pollCloser     *usecase.PollCloser
+	linkVerifier   *usecase.ProfileLinkVerifier
+	suggester      *usecase.FollowSuggester
 )
 
 func InitGlobal(cfg *config.Config) {
@@ -16,4 +30,19 @@ func InitGlobal(cfg *config.Config) {
 	if err != nil {
 		panic(err)
 	}
+	dataStore = repository.NewDataStore(db)
+
+	store, err = storage.New(cfg.Storage)
+	if err != nil {
+		panic(err)
+	}
+	mediaBlobStore = usecase.NewMediaBlobStore(store)
+	mediaProcessor = usecase.NewMediaProcessor(mediaBlobStore, cfg.Media.Workers, cfg.Media.MaxImageMegapixels*1_000_000)
+	mediaGC = usecase.NewMediaGC(store, cfg.Media)
+	trendService = usecase.NewTrendService(cfg.Trends)
+	searchAlerter = usecase.NewSearchAlerter(cfg.SavedSearch)
+	draftPublisher = usecase.NewDraftPublisher(dataStore, cfg.Draft)
+	pollCloser = usecase.NewPollCloser(cfg.Poll)
+	linkVerifier = usecase.NewProfileLinkVerifier(cfg.Profile, nil)
+	suggester = usecase.NewFollowSuggester(cfg.Suggestions)
 }
@@ -0,0 +1,46 @@
+package provider
+
+import (
+	"context"
+	"sync"
+
+	"TwClone/internal/repository"
+)
+
+// RunJobs runs the background jobs until ctx is cancelled.
+func RunJobs(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, job := range []func(context.Context){
+		mediaProcessor.Run,
+		mediaGC.Run,
+		trendService.Run,
+		searchAlerter.Run,
+		draftPublisher.Run,
+		pollCloser.Run,
+		linkVerifier.Run,
+		suggester.Run,
+	} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			job(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// RunMediaGC runs a single media garbage collection pass.
+func RunMediaGC(ctx context.Context) error {
+	_, err := mediaGC.Collect(ctx)
+	return err
+}
+
+// RunScheduler publishes scheduled tweets until ctx is cancelled.
+func RunScheduler(ctx context.Context) {
+	draftPublisher.Run(ctx)
+}
+
+// RunReconcileCounts repairs drift in the maintained user counts.
+func RunReconcileCounts(ctx context.Context) (int64, error) {
+	return repository.UserRepositoryImpl{}.ReconcileCounts(ctx)
+}
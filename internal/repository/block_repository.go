@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BlockRepositoryImpl struct{}
+
+// Create blocks a user. Blocking removes follows, follow requests, list
+// memberships and list subscriptions in both directions; blocking someone
+// already blocked is a no-op.
+func (r BlockRepositoryImpl) Create(ctx context.Context, block *entity.Block) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(block).Error; err != nil {
+			return err
+		}
+		_, err := deleteFollows(tx, "(follower_id = ? AND following_id = ?) OR (follower_id = ? AND following_id = ?)",
+			block.BlockerID, block.BlockedID, block.BlockedID, block.BlockerID)
+		if err != nil {
+			return err
+		}
+		err = tx.Where("(requester_id = ? AND target_id = ?) OR (requester_id = ? AND target_id = ?)",
+			block.BlockerID, block.BlockedID, block.BlockedID, block.BlockerID).
+			Delete(&entity.FollowRequest{}).Error
+		if err != nil {
+			return err
+		}
+		eitherOwnsList := "(user_id = ? AND list_id IN (SELECT id FROM lists WHERE owner_id = ?)) OR (user_id = ? AND list_id IN (SELECT id FROM lists WHERE owner_id = ?))"
+		_, err = removeListMembers(tx, eitherOwnsList, block.BlockerID, block.BlockedID, block.BlockedID, block.BlockerID)
+		if err != nil {
+			return err
+		}
+		_, err = removeListSubscriptions(tx, eitherOwnsList, block.BlockerID, block.BlockedID, block.BlockedID, block.BlockerID)
+		return err
+	})
+}
+
+func (r BlockRepositoryImpl) Delete(ctx context.Context, blockerID, blockedID int64) error {
+	return database.DB.WithContext(ctx).Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).Delete(&entity.Block{}).Error
+}
+
+// FindByBlocker returns the users blocked by blockerID, most recent first.
+func (r BlockRepositoryImpl) FindByBlocker(ctx context.Context, blockerID int64) ([]*entity.Block, error) {
+	var blocks []*entity.Block
+	result := database.DB.WithContext(ctx).Where("blocker_id = ?", blockerID).Order("created_at DESC").Find(&blocks)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return blocks, nil
+}
+
+// ExistsEither reports whether either user blocked the other.
+func (r BlockRepositoryImpl) ExistsEither(ctx context.Context, userID, otherID int64) (bool, error) {
+	var count int64
+	result := database.DB.WithContext(ctx).Model(&entity.Block{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", userID, otherID, otherID, userID).
+		Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
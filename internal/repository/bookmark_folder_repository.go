@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type BookmarkFolderRepositoryImpl struct{}
+
+// Create adds a folder. Folder names are unique per user; a second folder
+// with the same name returns ErrDuplicate.
+func (r BookmarkFolderRepositoryImpl) Create(ctx context.Context, folder *entity.BookmarkFolder) error {
+	result := database.DB.WithContext(ctx).Create(folder)
+	if result.Error != nil {
+		errMsg := result.Error.Error()
+		if strings.Contains(errMsg, "duplicate key") || strings.Contains(errMsg, "unique constraint") {
+			return ErrDuplicate
+		}
+		return result.Error
+	}
+	return nil
+}
+
+func (r BookmarkFolderRepositoryImpl) CountByUser(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	result := database.DB.WithContext(ctx).Model(&entity.BookmarkFolder{}).Where("user_id = ?", userID).Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
+
+// FindByUser returns a user's folders by name.
+func (r BookmarkFolderRepositoryImpl) FindByUser(ctx context.Context, userID int64) ([]*entity.BookmarkFolder, error) {
+	var folders []*entity.BookmarkFolder
+	result := database.DB.WithContext(ctx).Where("user_id = ?", userID).Order("name, id").Find(&folders)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return folders, nil
+}
+
+// FindByID finds a folder of the given user.
+func (r BookmarkFolderRepositoryImpl) FindByID(ctx context.Context, id, userID int64) (*entity.BookmarkFolder, error) {
+	var folder entity.BookmarkFolder
+	result := database.DB.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&folder)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, result.Error
+	}
+	return &folder, nil
+}
+
+// Rename renames a folder, returning ErrDuplicate when the user already has
+// a folder with that name.
+func (r BookmarkFolderRepositoryImpl) Rename(ctx context.Context, folder *entity.BookmarkFolder) error {
+	result := database.DB.WithContext(ctx).Model(folder).Select("name", "updated_at").Updates(folder)
+	if result.Error != nil {
+		errMsg := result.Error.Error()
+		if strings.Contains(errMsg, "duplicate key") || strings.Contains(errMsg, "unique constraint") {
+			return ErrDuplicate
+		}
+		return result.Error
+	}
+	return nil
+}
+
+// Delete removes a folder. Its bookmarks are kept outside any folder.
+func (r BookmarkFolderRepositoryImpl) Delete(ctx context.Context, id, userID int64) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&entity.BookmarkFolder{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+		return tx.Model(&entity.Bookmark{}).Where("folder_id = ?", id).Update("folder_id", nil).Error
+	})
+}
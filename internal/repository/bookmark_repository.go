@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+)
+
+type BookmarkRepositoryImpl struct{}
+
+// Create bookmarks a tweet. Bookmarking it twice returns ErrDuplicate.
+func (r BookmarkRepositoryImpl) Create(ctx context.Context, bookmark *entity.Bookmark) error {
+	result := database.DB.WithContext(ctx).Create(bookmark)
+	if result.Error != nil {
+		errMsg := result.Error.Error()
+		if strings.Contains(errMsg, "duplicate key") || strings.Contains(errMsg, "unique constraint") {
+			return ErrDuplicate
+		}
+		return result.Error
+	}
+	return nil
+}
+
+// Move files a bookmark in a folder, or takes it out of its folder when
+// folderID is nil.
+func (r BookmarkRepositoryImpl) Move(ctx context.Context, userID, tweetID int64, folderID *int64) error {
+	result := database.DB.WithContext(ctx).
+		Model(&entity.Bookmark{}).
+		Where("user_id = ? AND tweet_id = ?", userID, tweetID).
+		Update("folder_id", folderID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+func (r BookmarkRepositoryImpl) Delete(ctx context.Context, userID, tweetID int64) error {
+	result := database.DB.WithContext(ctx).Where("user_id = ? AND tweet_id = ?", userID, tweetID).Delete(&entity.Bookmark{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// BookmarkedTweet is a tweet with when it was bookmarked.
+type BookmarkedTweet struct {
+	entity.Tweet
+	BookmarkedAt time.Time
+}
+
+// FindPage returns up to limit of the tweets userID bookmarked and may still
+// see, most recently bookmarked first. A non-nil folderID limits them to one
+// folder. Only bookmarks made before the one for beforeTweetID at before are
+// returned when before is not zero.
+func (r BookmarkRepositoryImpl) FindPage(ctx context.Context, userID int64, folderID *int64, before time.Time, beforeTweetID int64, limit int) ([]*BookmarkedTweet, error) {
+	query := database.DB.WithContext(ctx).
+		Table("bookmarks b").
+		Select("t.*, b.created_at AS bookmarked_at").
+		Joins("JOIN tweets t ON t.id = b.tweet_id").
+		Where("b.user_id = ? AND t.deleted_at IS NULL", userID).
+		Scopes(visibleTweets(userID))
+	if folderID != nil {
+		query = query.Where("b.folder_id = ?", *folderID)
+	}
+	if !before.IsZero() {
+		query = query.Where("(b.created_at, b.tweet_id) < (?, ?)", before, beforeTweetID)
+	}
+
+	var page []*BookmarkedTweet
+	if err := query.Order("b.created_at DESC, b.tweet_id DESC").Limit(limit).Scan(&page).Error; err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// FindBookmarked returns which of the given tweets userID bookmarked.
+func (r BookmarkRepositoryImpl) FindBookmarked(ctx context.Context, userID int64, tweetIDs []int64) (map[int64]bool, error) {
+	bookmarked := make(map[int64]bool, len(tweetIDs))
+	if userID == 0 || len(tweetIDs) == 0 {
+		return bookmarked, nil
+	}
+
+	var ids []int64
+	result := database.DB.WithContext(ctx).
+		Model(&entity.Bookmark{}).
+		Where("user_id = ? AND tweet_id IN ?", userID, tweetIDs).
+		Pluck("tweet_id", &ids)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for _, id := range ids {
+		bookmarked[id] = true
+	}
+	return bookmarked, nil
+}
+
+// CountByTweets counts the bookmarks of each of the given tweets.
+func (r BookmarkRepositoryImpl) CountByTweets(ctx context.Context, tweetIDs []int64) (map[int64]int64, error) {
+	counts := make(map[int64]int64, len(tweetIDs))
+	if len(tweetIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		TweetID   int64
+		Bookmarks int64
+	}
+	result := database.DB.WithContext(ctx).
+		Model(&entity.Bookmark{}).
+		Select("tweet_id, COUNT(*) AS bookmarks").
+		Where("tweet_id IN ?", tweetIDs).
+		Group("tweet_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for _, row := range rows {
+		counts[row.TweetID] = row.Bookmarks
+	}
+	return counts, nil
+}
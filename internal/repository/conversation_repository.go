@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type ConversationRepositoryImpl struct{}
+
+// CreateWithMembers inserts a conversation together with its members in a
+// single transaction.
+func (r ConversationRepositoryImpl) CreateWithMembers(ctx context.Context, conv *entity.Conversation, members []*entity.ConversationMember) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if conv.LastMessageAt.IsZero() {
+			conv.LastMessageAt = time.Now()
+		}
+		if err := tx.Create(conv).Error; err != nil {
+			return err
+		}
+
+		for _, m := range members {
+			m.ConversationID = conv.ID
+		}
+		return tx.Create(&members).Error
+	})
+}
+
+// FindByID finds a conversation by id.
+func (r ConversationRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Conversation, error) {
+	var conv entity.Conversation
+	result := database.DB.WithContext(ctx).First(&conv, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, result.Error
+	}
+	return &conv, nil
+}
+
+// FindByDirectKey finds the one-to-one conversation for a pair of users.
+func (r ConversationRepositoryImpl) FindByDirectKey(ctx context.Context, key string) (*entity.Conversation, error) {
+	var conv entity.Conversation
+	result := database.DB.WithContext(ctx).Where("direct_key = ?", key).First(&conv)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, result.Error
+	}
+	return &conv, nil
+}
+
+// FindByMember returns the conversations a user is still part of with the
+// given member status, most recently active first.
+func (r ConversationRepositoryImpl) FindByMember(ctx context.Context, userID int64, status string) ([]*entity.Conversation, error) {
+	var convs []*entity.Conversation
+	result := database.DB.WithContext(ctx).
+		Joins("JOIN conversation_members cm ON cm.conversation_id = conversations.id").
+		Where("cm.user_id = ? AND cm.status = ? AND cm.left_at IS NULL", userID, status).
+		Order("conversations.last_message_at DESC").
+		Find(&convs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return convs, nil
+}
+
+// FindMember returns the membership row of a user in a conversation.
+func (r ConversationRepositoryImpl) FindMember(ctx context.Context, conversationID, userID int64) (*entity.ConversationMember, error) {
+	var member entity.ConversationMember
+	result := database.DB.WithContext(ctx).Where("conversation_id = ? AND user_id = ?", conversationID, userID).First(&member)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, result.Error
+	}
+	return &member, nil
+}
+
+// FindMembers returns the active members of a conversation.
+func (r ConversationRepositoryImpl) FindMembers(ctx context.Context, conversationID int64) ([]*entity.ConversationMember, error) {
+	var members []*entity.ConversationMember
+	result := database.DB.WithContext(ctx).Where("conversation_id = ? AND left_at IS NULL", conversationID).Find(&members)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return members, nil
+}
+
+// Rename changes the display name of a conversation.
+func (r ConversationRepositoryImpl) Rename(ctx context.Context, id int64, name string) error {
+	return database.DB.WithContext(ctx).Model(&entity.Conversation{}).Where("id = ?", id).Update("name", name).Error
+}
+
+// Leave marks a member as having left a conversation.
+func (r ConversationRepositoryImpl) Leave(ctx context.Context, conversationID, userID int64) error {
+	return database.DB.WithContext(ctx).Model(&entity.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ? AND left_at IS NULL", conversationID, userID).
+		Update("left_at", time.Now()).Error
+}
+
+// Accept moves a pending conversation into the member's primary inbox.
+func (r ConversationRepositoryImpl) Accept(ctx context.Context, conversationID, userID int64) error {
+	return database.DB.WithContext(ctx).Model(&entity.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Update("status", entity.ConversationMemberAccepted).Error
+}
+
+// Reopen puts a deleted message request back into the member's requests inbox.
+func (r ConversationRepositoryImpl) Reopen(ctx context.Context, conversationID, userID int64) error {
+	return database.DB.WithContext(ctx).Model(&entity.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ? AND left_at IS NOT NULL", conversationID, userID).
+		Updates(map[string]any{"left_at": nil, "status": entity.ConversationMemberPending}).Error
+}
+
+// MarkRead records that a member has read the conversation up to messageID.
+// Read positions never move backwards and imply delivery.
+func (r ConversationRepositoryImpl) MarkRead(ctx context.Context, conversationID, userID, messageID int64) error {
+	return database.DB.WithContext(ctx).Model(&entity.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Updates(map[string]any{
+			"last_read_message_id":      gorm.Expr("GREATEST(last_read_message_id, ?)", messageID),
+			"last_delivered_message_id": gorm.Expr("GREATEST(last_delivered_message_id, ?)", messageID),
+		}).Error
+}
+
+// MarkDelivered records that messages up to messageID reached a member's client.
+func (r ConversationRepositoryImpl) MarkDelivered(ctx context.Context, conversationID, userID, messageID int64) error {
+	return database.DB.WithContext(ctx).Model(&entity.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Update("last_delivered_message_id", gorm.Expr("GREATEST(last_delivered_message_id, ?)", messageID)).Error
+}
+
+// UnreadCounts returns, per conversation with the given member status, the
+// number of messages from other members newer than the user's read position.
+// Conversations without unread messages are omitted.
+func (r ConversationRepositoryImpl) UnreadCounts(ctx context.Context, userID int64, status string) (map[int64]int64, error) {
+	var rows []struct {
+		ConversationID int64
+		Unread         int64
+	}
+	result := database.DB.WithContext(ctx).
+		Table("messages m").
+		Select("m.conversation_id, COUNT(*) AS unread").
+		Joins("JOIN conversation_members cm ON cm.conversation_id = m.conversation_id AND cm.user_id = ?", userID).
+		Where("cm.status = ? AND cm.left_at IS NULL AND m.sender_id <> ? AND m.id > cm.last_read_message_id", status, userID).
+		Group("m.conversation_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	counts := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		counts[row.ConversationID] = row.Unread
+	}
+	return counts, nil
+}
+
+// FindEncryptedPeers returns the other active members of every encrypted
+// conversation the user belongs to.
+func (r ConversationRepositoryImpl) FindEncryptedPeers(ctx context.Context, userID int64) ([]*entity.ConversationMember, error) {
+	var members []*entity.ConversationMember
+	result := database.DB.WithContext(ctx).
+		Table("conversation_members peer").
+		Select("peer.*").
+		Joins("JOIN conversations c ON c.id = peer.conversation_id AND c.encrypted").
+		Joins("JOIN conversation_members me ON me.conversation_id = c.id AND me.user_id = ? AND me.left_at IS NULL", userID).
+		Where("peer.user_id <> ? AND peer.left_at IS NULL", userID).
+		Scan(&members)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return members, nil
+}
@@ -2,48 +2,59 @@ package repository
 
 import (
 	"context"
-	"database/sql"
+	"time"
 
-	"github.com/jmoiron/sqlx"
-)
+	"TwClone/internal/entity"
 
-type DBTX interface {
-	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
-	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
-	QueryxContext(context.Context, string, ...interface{}) (*sqlx.Rows, error)
-	QueryRowContext(context.Context, string, ...interface{}) *sql.Row
-	QueryRowxContext(context.Context, string, ...interface{}) *sqlx.Row
-}
+	"gorm.io/gorm"
+)
 
+// DataStore groups writes that span several tables. Atomic runs fn with a
+// DataStore bound to a single transaction, so everything fn writes through
+// it is committed or rolled back together.
 type DataStore interface {
 	Atomic(ctx context.Context, fn func(DataStore) error) error
+
+	// CreateTweet inserts a tweet and attaches the given uploads to it. Only
+	// unattached media owned by the tweet's author can be attached. A tweet
+	// without a ConversationID becomes the root of a new conversation.
+	// Retweeting a tweet the author already retweeted returns ErrDuplicate.
+	CreateTweet(ctx context.Context, tweet *entity.Tweet, mediaIDs []int64) error
+	// EditTweet replaces the content of a tweet, keeping the previous
+	// content as a version, and updates tweet to match. It returns
+	// ErrTweetChanged when the tweet was edited or deleted since it was
+	// read.
+	EditTweet(ctx context.Context, tweet *entity.Tweet, content string) error
+	// CreatePoll attaches a poll with its options to a tweet.
+	CreatePoll(ctx context.Context, poll *entity.Poll) error
+	// ClaimDueDraft locks the scheduled tweet that has been due the longest
+	// at now, skipping those locked by others, and returns it with its
+	// media. Without one due it returns ErrRecordNotFound. The lock is held
+	// until the surrounding Atomic block ends.
+	ClaimDueDraft(ctx context.Context, now time.Time) (*entity.Draft, error)
+	// DeleteDraft removes a draft once it has been published.
+	DeleteDraft(ctx context.Context, id int64) error
+	// FailDraft turns a scheduled tweet that could not be published back
+	// into a draft recording why, and notifies its author.
+	FailDraft(ctx context.Context, draft *entity.Draft, reason string) error
+	// SetTweetHashtags replaces the hashtags of a tweet, creating the
+	// hashtags that do not exist yet.
+	SetTweetHashtags(ctx context.Context, tweetID int64, tags []string) error
+	// SetTweetMentions replaces the users mentioned by a tweet. Usernames are
+	// matched case-insensitively; unknown ones are ignored.
+	SetTweetMentions(ctx context.Context, tweetID int64, usernames []string) error
 }
 
 type dataStore struct {
-	conn *sqlx.DB
-	db   DBTX
+	db *gorm.DB
 }
 
-func NewDataStore(db *sqlx.DB) DataStore {
-	return &dataStore{
-		conn: db,
-		db:   db,
-	}
+func NewDataStore(db *gorm.DB) DataStore {
+	return &dataStore{db: db}
 }
 
 func (s *dataStore) Atomic(ctx context.Context, fn func(DataStore) error) error {
-	tx, err := s.conn.BeginTxx(ctx, &sql.TxOptions{})
-	if err != nil {
-		return err
-	}
-
-	err = fn(&dataStore{conn: s.conn, db: tx})
-	if err != nil {
-		if errRollback := tx.Rollback(); errRollback != nil {
-			return err
-		}
-		return err
-	}
-
-	return tx.Commit()
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&dataStore{db: tx})
+	})
 }
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"TwClone/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *dataStore) ClaimDueDraft(ctx context.Context, now time.Time) (*entity.Draft, error) {
+	db := s.db.WithContext(ctx)
+	var draft entity.Draft
+	result := db.
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Where("publish_at <= ?", now).
+		Order("publish_at, id").
+		First(&draft)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, result.Error
+	}
+	if err := loadDraftMedia(db, []*entity.Draft{&draft}); err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+func (s *dataStore) DeleteDraft(ctx context.Context, id int64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("draft_id = ?", id).Delete(&entity.DraftMedia{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&entity.Draft{}, id).Error
+	})
+}
+
+func (s *dataStore) FailDraft(ctx context.Context, draft *entity.Draft, reason string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(draft).Updates(map[string]any{"publish_at": nil, "error": reason})
+		if result.Error != nil {
+			return result.Error
+		}
+		draft.PublishAt = nil
+		draft.Error = reason
+
+		return tx.Create(&entity.Notification{
+			RecipientID: draft.UserID,
+			Type:        entity.NotificationTypeScheduledTweetFailed,
+			DraftID:     &draft.ID,
+		}).Error
+	})
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"TwClone/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *dataStore) CreateTweet(ctx context.Context, tweet *entity.Tweet, mediaIDs []int64) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(tweet).Error; err != nil {
+			errMsg := err.Error()
+			if strings.Contains(errMsg, "duplicate key") || strings.Contains(errMsg, "unique constraint") {
+				return ErrDuplicate
+			}
+			return err
+		}
+		if err := tx.Model(&entity.User{}).Where("id = ?", tweet.UserID).
+			UpdateColumn("tweets_count", gorm.Expr("tweets_count + 1")).Error; err != nil {
+			return err
+		}
+		// a tweet that is not a reply starts its own conversation
+		if tweet.ConversationID == 0 {
+			tweet.ConversationID = tweet.ID
+			if err := tx.Model(tweet).Update("conversation_id", tweet.ID).Error; err != nil {
+				return err
+			}
+		}
+		if len(mediaIDs) == 0 {
+			return nil
+		}
+
+		result := tx.Model(&entity.Media{}).
+			Where("id IN ? AND user_id = ? AND tweet_id IS NULL", mediaIDs, tweet.UserID).
+			Update("tweet_id", tweet.ID)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(mediaIDs)) {
+			return ErrMediaUnavailable
+		}
+		return nil
+	})
+}
+
+func (s *dataStore) EditTweet(ctx context.Context, tweet *entity.Tweet, content string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		postedAt := tweet.CreatedAt
+		if tweet.EditedAt != nil {
+			postedAt = *tweet.EditedAt
+		}
+		version := entity.TweetVersion{
+			TweetID:   tweet.ID,
+			Version:   tweet.EditCount + 1,
+			Content:   tweet.Content,
+			CreatedAt: postedAt,
+		}
+		if err := tx.Create(&version).Error; err != nil {
+			errMsg := err.Error()
+			if strings.Contains(errMsg, "duplicate key") || strings.Contains(errMsg, "unique constraint") {
+				return ErrTweetChanged
+			}
+			return err
+		}
+
+		now := time.Now()
+		result := tx.Model(&entity.Tweet{}).
+			Where("id = ? AND edit_count = ? AND deleted_at IS NULL", tweet.ID, tweet.EditCount).
+			Updates(map[string]any{
+				"content":    content,
+				"edit_count": tweet.EditCount + 1,
+				"edited_at":  now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrTweetChanged
+		}
+
+		tweet.Content = content
+		tweet.EditCount++
+		tweet.EditedAt = &now
+		tweet.UpdatedAt = now
+		return nil
+	})
+}
+
+func (s *dataStore) CreatePoll(ctx context.Context, poll *entity.Poll) error {
+	return s.db.WithContext(ctx).Create(poll).Error
+}
+
+func (s *dataStore) SetTweetHashtags(ctx context.Context, tweetID int64, tags []string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tweet_id = ?", tweetID).Delete(&entity.TweetHashtag{}).Error; err != nil {
+			return err
+		}
+		if len(tags) == 0 {
+			return nil
+		}
+
+		// inserting in a fixed order keeps concurrent upserts of the same
+		// tags from deadlocking
+		sorted := slices.Sorted(slices.Values(tags))
+		hashtags := make([]*entity.Hashtag, 0, len(sorted))
+		for _, tag := range slices.Compact(sorted) {
+			hashtags = append(hashtags, &entity.Hashtag{TagName: tag})
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&hashtags).Error; err != nil {
+			return err
+		}
+
+		var ids []int64
+		if err := tx.Model(&entity.Hashtag{}).Where("tag_name IN ?", sorted).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		links := make([]*entity.TweetHashtag, 0, len(ids))
+		for _, id := range ids {
+			links = append(links, &entity.TweetHashtag{TweetID: tweetID, HashtagID: id})
+		}
+		return tx.Create(&links).Error
+	})
+}
+
+func (s *dataStore) SetTweetMentions(ctx context.Context, tweetID int64, usernames []string) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("tweet_id = ?", tweetID).Delete(&entity.Mention{}).Error; err != nil {
+			return err
+		}
+		if len(usernames) == 0 {
+			return nil
+		}
+
+		var userIDs []int64
+		if err := tx.Model(&entity.User{}).Where("LOWER(username) IN ?", usernames).Pluck("id", &userIDs).Error; err != nil {
+			return err
+		}
+		if len(userIDs) == 0 {
+			return nil
+		}
+		mentions := make([]*entity.Mention, 0, len(userIDs))
+		for _, id := range userIDs {
+			mentions = append(mentions, &entity.Mention{TweetID: tweetID, UserID: id})
+		}
+		return tx.Create(&mentions).Error
+	})
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DeviceKeyRepositoryImpl struct{}
+
+// Upsert registers a device or replaces the keys of an existing one. The
+// device's unclaimed prekeys are dropped when its identity key changes since
+// they belong to the old identity.
+func (r DeviceKeyRepositoryImpl) Upsert(ctx context.Context, key *entity.DeviceKey, preKeys []*entity.PreKey) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var existing entity.DeviceKey
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND device_id = ?", key.UserID, key.DeviceID).
+			First(&existing)
+		switch {
+		case result.Error == nil:
+			if existing.IdentityKey != key.IdentityKey {
+				if err := tx.Where("device_key_id = ? AND claimed_at IS NULL", existing.ID).Delete(&entity.PreKey{}).Error; err != nil {
+					return err
+				}
+			}
+			key.ID = existing.ID
+			key.CreatedAt = existing.CreatedAt
+			key.RevokedAt = nil
+			if err := tx.Save(key).Error; err != nil {
+				return err
+			}
+		case errors.Is(result.Error, gorm.ErrRecordNotFound):
+			if err := tx.Create(key).Error; err != nil {
+				return err
+			}
+		default:
+			return result.Error
+		}
+
+		return addPreKeys(tx, key.ID, preKeys)
+	})
+}
+
+// AddPreKeys stores more one-time prekeys for a device. Key ids already
+// uploaded for the device are ignored.
+func (r DeviceKeyRepositoryImpl) AddPreKeys(ctx context.Context, deviceKeyID int64, preKeys []*entity.PreKey) error {
+	return addPreKeys(database.DB.WithContext(ctx), deviceKeyID, preKeys)
+}
+
+func addPreKeys(tx *gorm.DB, deviceKeyID int64, preKeys []*entity.PreKey) error {
+	if len(preKeys) == 0 {
+		return nil
+	}
+	for _, pk := range preKeys {
+		pk.DeviceKeyID = deviceKeyID
+	}
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&preKeys).Error
+}
+
+// FindActiveByUser returns the devices of a user that have not been revoked.
+func (r DeviceKeyRepositoryImpl) FindActiveByUser(ctx context.Context, userID int64) ([]*entity.DeviceKey, error) {
+	var keys []*entity.DeviceKey
+	result := database.DB.WithContext(ctx).Where("user_id = ? AND revoked_at IS NULL", userID).Order("id ASC").Find(&keys)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return keys, nil
+}
+
+// FindActiveByUsers returns the non-revoked devices of several users.
+func (r DeviceKeyRepositoryImpl) FindActiveByUsers(ctx context.Context, userIDs []int64) ([]*entity.DeviceKey, error) {
+	var keys []*entity.DeviceKey
+	result := database.DB.WithContext(ctx).Where("user_id IN ? AND revoked_at IS NULL", userIDs).Find(&keys)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return keys, nil
+}
+
+// FindActive finds a non-revoked device of a user.
+func (r DeviceKeyRepositoryImpl) FindActive(ctx context.Context, userID int64, deviceID string) (*entity.DeviceKey, error) {
+	var key entity.DeviceKey
+	result := database.DB.WithContext(ctx).Where("user_id = ? AND device_id = ? AND revoked_at IS NULL", userID, deviceID).First(&key)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, result.Error
+	}
+	return &key, nil
+}
+
+// Revoke marks a device as revoked and drops its unclaimed prekeys.
+func (r DeviceKeyRepositoryImpl) Revoke(ctx context.Context, deviceKeyID int64) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entity.DeviceKey{}).Where("id = ?", deviceKeyID).Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Where("device_key_id = ? AND claimed_at IS NULL", deviceKeyID).Delete(&entity.PreKey{}).Error
+	})
+}
+
+// RevokePreKeys drops the unclaimed one-time prekeys of a device.
+func (r DeviceKeyRepositoryImpl) RevokePreKeys(ctx context.Context, deviceKeyID int64) error {
+	return database.DB.WithContext(ctx).Where("device_key_id = ? AND claimed_at IS NULL", deviceKeyID).Delete(&entity.PreKey{}).Error
+}
+
+// CountPreKeys returns the number of unclaimed prekeys per device.
+func (r DeviceKeyRepositoryImpl) CountPreKeys(ctx context.Context, deviceKeyIDs []int64) (map[int64]int64, error) {
+	var rows []struct {
+		DeviceKeyID int64
+		Remaining   int64
+	}
+	result := database.DB.WithContext(ctx).
+		Model(&entity.PreKey{}).
+		Select("device_key_id, COUNT(*) AS remaining").
+		Where("device_key_id IN ? AND claimed_at IS NULL", deviceKeyIDs).
+		Group("device_key_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	counts := make(map[int64]int64, len(rows))
+	for _, row := range rows {
+		counts[row.DeviceKeyID] = row.Remaining
+	}
+	return counts, nil
+}
+
+// ClaimPreKey hands out one unclaimed prekey of a device to claimerID, or
+// nil when the device has run out or the claimer already claimed limit of
+// its prekeys since since. Concurrent claims never receive the same prekey.
+func (r DeviceKeyRepositoryImpl) ClaimPreKey(ctx context.Context, deviceKeyID, claimerID int64, since time.Time, limit int) (*entity.PreKey, error) {
+	var claimed *entity.PreKey
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// claims on a device take turns so that the cap cannot be raced
+		var device entity.DeviceKey
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&device, deviceKeyID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		var recent int64
+		err := tx.Model(&entity.PreKey{}).
+			Where("device_key_id = ? AND claimed_by = ? AND claimed_at >= ?", deviceKeyID, claimerID, since).
+			Count(&recent).Error
+		if err != nil {
+			return err
+		}
+		if recent >= int64(limit) {
+			return nil
+		}
+
+		var pk entity.PreKey
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("device_key_id = ? AND claimed_at IS NULL", deviceKeyID).
+			Order("key_id ASC").
+			Limit(1).
+			Find(&pk)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		if err := tx.Model(&pk).Updates(map[string]any{"claimed_at": now, "claimed_by": claimerID}).Error; err != nil {
+			return err
+		}
+		pk.ClaimedAt = &now
+		pk.ClaimedBy = &claimerID
+		claimed = &pk
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claimed, nil
+}
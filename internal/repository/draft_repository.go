@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type DraftRepositoryImpl struct{}
+
+// Create saves a draft with its media. The media must be the author's own
+// uploads that are not attached to a tweet, or ErrMediaUnavailable is
+// returned.
+func (r DraftRepositoryImpl) Create(ctx context.Context, draft *entity.Draft) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(draft).Error; err != nil {
+			return err
+		}
+		return setDraftMedia(tx, draft)
+	})
+}
+
+// Update replaces the content, media and schedule of a draft and clears its
+// error. It returns ErrRecordNotFound when the draft no longer exists, for
+// instance because it was just published.
+func (r DraftRepositoryImpl) Update(ctx context.Context, draft *entity.Draft) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		draft.Error = ""
+		result := tx.Model(draft).
+			Where("user_id = ?", draft.UserID).
+			Select("content", "reply_to_tweet_id", "quoted_tweet_id", "publish_at", "error", "updated_at").
+			Updates(draft)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+
+		if err := tx.Where("draft_id = ?", draft.ID).Delete(&entity.DraftMedia{}).Error; err != nil {
+			return err
+		}
+		return setDraftMedia(tx, draft)
+	})
+}
+
+func (r DraftRepositoryImpl) CountByUser(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	result := database.DB.WithContext(ctx).Model(&entity.Draft{}).Where("user_id = ?", userID).Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
+
+// FindByUser returns a user's drafts with their media. Scheduled tweets come
+// soonest first, other drafts most recently saved first.
+func (r DraftRepositoryImpl) FindByUser(ctx context.Context, userID int64, scheduled bool) ([]*entity.Draft, error) {
+	query := database.DB.WithContext(ctx).Where("user_id = ?", userID)
+	if scheduled {
+		query = query.Where("publish_at IS NOT NULL").Order("publish_at, id")
+	} else {
+		query = query.Where("publish_at IS NULL").Order("updated_at DESC, id DESC")
+	}
+
+	var drafts []*entity.Draft
+	if err := query.Find(&drafts).Error; err != nil {
+		return nil, err
+	}
+	if err := loadDraftMedia(database.DB.WithContext(ctx), drafts); err != nil {
+		return nil, err
+	}
+	return drafts, nil
+}
+
+// FindByID finds a draft of the given user with its media.
+func (r DraftRepositoryImpl) FindByID(ctx context.Context, id, userID int64) (*entity.Draft, error) {
+	var draft entity.Draft
+	result := database.DB.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&draft)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, result.Error
+	}
+	if err := loadDraftMedia(database.DB.WithContext(ctx), []*entity.Draft{&draft}); err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// Delete removes a draft. Its uploads are left for the media garbage
+// collector unless they are used elsewhere.
+func (r DraftRepositoryImpl) Delete(ctx context.Context, id, userID int64) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND user_id = ?", id, userID).Delete(&entity.Draft{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+		return tx.Where("draft_id = ?", id).Delete(&entity.DraftMedia{}).Error
+	})
+}
+
+// setDraftMedia links a draft to its media in order.
+func setDraftMedia(tx *gorm.DB, draft *entity.Draft) error {
+	if len(draft.MediaIDs) == 0 {
+		draft.MediaIDs = []int64{}
+		return nil
+	}
+
+	var usable int64
+	result := tx.Model(&entity.Media{}).
+		Where("id IN ? AND user_id = ? AND tweet_id IS NULL", draft.MediaIDs, draft.UserID).
+		Count(&usable)
+	if result.Error != nil {
+		return result.Error
+	}
+	if usable != int64(len(draft.MediaIDs)) {
+		return ErrMediaUnavailable
+	}
+
+	links := make([]*entity.DraftMedia, 0, len(draft.MediaIDs))
+	for i, id := range draft.MediaIDs {
+		links = append(links, &entity.DraftMedia{DraftID: draft.ID, MediaID: id, Position: i})
+	}
+	return tx.Create(&links).Error
+}
+
+// loadDraftMedia fills in the media ids of drafts.
+func loadDraftMedia(db *gorm.DB, drafts []*entity.Draft) error {
+	if len(drafts) == 0 {
+		return nil
+	}
+	byID := make(map[int64]*entity.Draft, len(drafts))
+	ids := make([]int64, 0, len(drafts))
+	for _, d := range drafts {
+		d.MediaIDs = []int64{}
+		byID[d.ID] = d
+		ids = append(ids, d.ID)
+	}
+
+	var links []*entity.DraftMedia
+	if err := db.Where("draft_id IN ?", ids).Order("draft_id, position").Find(&links).Error; err != nil {
+		return err
+	}
+	for _, l := range links {
+		byID[l.DraftID].MediaIDs = append(byID[l.DraftID].MediaIDs, l.MediaID)
+	}
+	return nil
+}
@@ -4,16 +4,41 @@ import (
 	"TwClone/internal/database"
 	"TwClone/internal/entity"
 	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type FollowRepositoryImpl struct{}
 
+// Create follows a user and updates the follow counts of both users in the
+// same transaction. Following someone already followed returns
+// ErrDuplicate.
 func (r FollowRepositoryImpl) Create(ctx context.Context, follow *entity.Follow) error {
-	return database.DB.WithContext(ctx).Create(follow).Error
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createFollow(tx, follow)
+	})
 }
 
+// Delete unfollows a user, also withdrawing a pending request to follow
+// them. It returns ErrRecordNotFound when there was neither.
 func (r FollowRepositoryImpl) Delete(ctx context.Context, followerID, followingID int64) error {
-	return database.DB.WithContext(ctx).Where("follower_id = ? AND following_id = ?", followerID, followingID).Delete(&entity.Follow{}).Error
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		unfollowed, err := deleteFollows(tx, "follower_id = ? AND following_id = ?", followerID, followingID)
+		if err != nil {
+			return err
+		}
+		result := tx.Where("requester_id = ? AND target_id = ?", followerID, followingID).Delete(&entity.FollowRequest{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if unfollowed == 0 && result.RowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+		return nil
+	})
 }
 
 func (r FollowRepositoryImpl) FindFollowers(ctx context.Context, userID int64) ([]*entity.Follow, error) {
@@ -33,3 +58,205 @@ func (r FollowRepositoryImpl) FindFollowing(ctx context.Context, userID int64) (
 	}
 	return follows, nil
 }
+
+func (r FollowRepositoryImpl) Exists(ctx context.Context, followerID, followingID int64) (bool, error) {
+	var count int64
+	result := database.DB.WithContext(ctx).Model(&entity.Follow{}).Where("follower_id = ? AND following_id = ?", followerID, followingID).Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
+// FollowedUser is a user in a list of followers or followed users, with
+// when the follow began.
+type FollowedUser struct {
+	entity.User
+	FollowedAt time.Time
+}
+
+// FollowPageQuery selects a page of a follower or following list. Pages are
+// ordered by when the follow began, newest first unless OldestFirst is set,
+// and continue after the follow of CursorUserID at CursorTime when it is
+// not zero.
+type FollowPageQuery struct {
+	CursorTime   time.Time
+	CursorUserID int64
+	OldestFirst  bool
+	Limit        int
+}
+
+// FindFollowerPage returns a page of the users following a user.
+func (r FollowRepositoryImpl) FindFollowerPage(ctx context.Context, userID int64, q FollowPageQuery) ([]*FollowedUser, error) {
+	query := database.DB.WithContext(ctx).
+		Joins("JOIN users u ON u.id = f.follower_id").
+		Where("f.following_id = ?", userID)
+	return findFollowPage(query, q)
+}
+
+// FindFollowingPage returns a page of the users a user follows.
+func (r FollowRepositoryImpl) FindFollowingPage(ctx context.Context, userID int64, q FollowPageQuery) ([]*FollowedUser, error) {
+	query := database.DB.WithContext(ctx).
+		Joins("JOIN users u ON u.id = f.following_id").
+		Where("f.follower_id = ?", userID)
+	return findFollowPage(query, q)
+}
+
+// FindFollowersYouKnowPage returns a page of the users following a user
+// that the viewer follows too.
+func (r FollowRepositoryImpl) FindFollowersYouKnowPage(ctx context.Context, userID, viewerID int64, q FollowPageQuery) ([]*FollowedUser, error) {
+	query := database.DB.WithContext(ctx).
+		Joins("JOIN users u ON u.id = f.follower_id").
+		Joins("JOIN follows vf ON vf.following_id = f.follower_id AND vf.follower_id = ?", viewerID).
+		Where("f.following_id = ?", userID)
+	return findFollowPage(query, q)
+}
+
+// findFollowPage pages through follows f joined with the listed users u.
+func findFollowPage(query *gorm.DB, q FollowPageQuery) ([]*FollowedUser, error) {
+	query = query.Table("follows f").Select("u.*, f.created_at AS followed_at")
+	order := "f.created_at DESC, u.id DESC"
+	if q.OldestFirst {
+		order = "f.created_at, u.id"
+	}
+	if !q.CursorTime.IsZero() {
+		if q.OldestFirst {
+			query = query.Where("(f.created_at, u.id) > (?, ?)", q.CursorTime, q.CursorUserID)
+		} else {
+			query = query.Where("(f.created_at, u.id) < (?, ?)", q.CursorTime, q.CursorUserID)
+		}
+	}
+
+	var page []*FollowedUser
+	if err := query.Order(order).Limit(q.Limit).Scan(&page).Error; err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// CreateRequest asks to follow a protected user. Asking again while the
+// request is pending returns ErrDuplicate.
+func (r FollowRepositoryImpl) CreateRequest(ctx context.Context, req *entity.FollowRequest) error {
+	result := database.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(req)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDuplicate
+	}
+	return nil
+}
+
+// FindRequests returns the pending requests to follow a user, oldest first.
+func (r FollowRepositoryImpl) FindRequests(ctx context.Context, targetID int64) ([]*entity.FollowRequest, error) {
+	var requests []*entity.FollowRequest
+	result := database.DB.WithContext(ctx).Where("target_id = ?", targetID).Order("created_at, requester_id").Find(&requests)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return requests, nil
+}
+
+// ApproveRequest turns a pending request into a follow. It returns
+// ErrRecordNotFound when there is no such request.
+func (r FollowRepositoryImpl) ApproveRequest(ctx context.Context, requesterID, targetID int64) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var requests []*entity.FollowRequest
+		result := tx.Clauses(clause.Returning{}).
+			Where("requester_id = ? AND target_id = ?", requesterID, targetID).
+			Delete(&requests)
+		if result.Error != nil {
+			return result.Error
+		}
+		if len(requests) == 0 {
+			return ErrRecordNotFound
+		}
+		err := createFollow(tx, &entity.Follow{FollowerID: requesterID, FollowingID: targetID})
+		if errors.Is(err, ErrDuplicate) {
+			return nil
+		}
+		return err
+	})
+}
+
+// ApproveAllRequests turns every pending request to follow a user into a
+// follow, for when the user stops being protected.
+func (r FollowRepositoryImpl) ApproveAllRequests(ctx context.Context, targetID int64) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var requests []*entity.FollowRequest
+		if err := tx.Clauses(clause.Returning{}).Where("target_id = ?", targetID).Delete(&requests).Error; err != nil {
+			return err
+		}
+		for _, req := range requests {
+			err := createFollow(tx, &entity.Follow{FollowerID: req.RequesterID, FollowingID: req.TargetID})
+			if err != nil && !errors.Is(err, ErrDuplicate) {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteRequest declines a pending request. It returns ErrRecordNotFound
+// when there is no such request.
+func (r FollowRepositoryImpl) DeleteRequest(ctx context.Context, requesterID, targetID int64) error {
+	result := database.DB.WithContext(ctx).Where("requester_id = ? AND target_id = ?", requesterID, targetID).Delete(&entity.FollowRequest{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// createFollow inserts a follow and counts it for both users.
+func createFollow(tx *gorm.DB, follow *entity.Follow) error {
+	result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(follow)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDuplicate
+	}
+	return adjustFollowCounts(tx, follow.FollowerID, follow.FollowingID, 1)
+}
+
+// deleteFollows removes the follows matching the condition, uncounts them
+// and returns how many there were.
+func deleteFollows(tx *gorm.DB, query string, args ...any) (int, error) {
+	var deleted []*entity.Follow
+	if err := tx.Clauses(clause.Returning{}).Where(query, args...).Delete(&deleted).Error; err != nil {
+		return 0, err
+	}
+	for _, f := range deleted {
+		if err := adjustFollowCounts(tx, f.FollowerID, f.FollowingID, -1); err != nil {
+			return 0, err
+		}
+	}
+	return len(deleted), nil
+}
+
+// adjustFollowCounts changes the following count of the follower and the
+// follower count of the followed user by delta. The rows are updated in id
+// order so that two users following each other at once cannot deadlock.
+func adjustFollowCounts(tx *gorm.DB, followerID, followingID int64, delta int) error {
+	updates := []struct {
+		id     int64
+		column string
+	}{
+		{followerID, "following_count"},
+		{followingID, "followers_count"},
+	}
+	if followingID < followerID {
+		updates[0], updates[1] = updates[1], updates[0]
+	}
+	for _, u := range updates {
+		err := tx.Model(&entity.User{}).Where("id = ?", u.id).
+			UpdateColumn(u.column, gorm.Expr(u.column+" + ?", delta)).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"TwClone/internal/database"
 	"TwClone/internal/entity"
 	"context"
+	"time"
 )
 
 type HashtagRepositoryImpl struct{}
@@ -29,3 +30,58 @@ func (r HashtagRepositoryImpl) FindAll(ctx context.Context) ([]*entity.Hashtag,
 	}
 	return hashtags, nil
 }
+
+// HashtagAuthorUsage is how often one author used a hashtag in a period.
+type HashtagAuthorUsage struct {
+	HashtagID  int64
+	TagName    string
+	UserID     int64
+	TweetCount int64
+	LastUsedAt time.Time
+}
+
+// FindAuthorUsageSince aggregates hashtag use per hashtag and author over
+// the tweets posted since the given time.
+func (r HashtagRepositoryImpl) FindAuthorUsageSince(ctx context.Context, since time.Time) ([]*HashtagAuthorUsage, error) {
+	var usage []*HashtagAuthorUsage
+	result := database.DB.WithContext(ctx).
+		Table("tweet_hashtags th").
+		Select("th.hashtag_id, h.tag_name, t.user_id, COUNT(*) AS tweet_count, MAX(t.created_at) AS last_used_at").
+		Joins("JOIN tweets t ON t.id = th.tweet_id").
+		Joins("JOIN hashtags h ON h.id = th.hashtag_id").
+		Where("t.created_at >= ?", since).
+		Group("th.hashtag_id, h.tag_name, t.user_id").
+		Scan(&usage)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return usage, nil
+}
+
+// CountAuthorsBetween counts the distinct authors that used each of the given
+// hashtags in [from, to).
+func (r HashtagRepositoryImpl) CountAuthorsBetween(ctx context.Context, hashtagIDs []int64, from, to time.Time) (map[int64]int64, error) {
+	counts := make(map[int64]int64, len(hashtagIDs))
+	if len(hashtagIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		HashtagID int64
+		Authors   int64
+	}
+	result := database.DB.WithContext(ctx).
+		Table("tweet_hashtags th").
+		Select("th.hashtag_id, COUNT(DISTINCT t.user_id) AS authors").
+		Joins("JOIN tweets t ON t.id = th.tweet_id").
+		Where("th.hashtag_id IN ? AND t.created_at >= ? AND t.created_at < ?", hashtagIDs, from, to).
+		Group("th.hashtag_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for _, row := range rows {
+		counts[row.HashtagID] = row.Authors
+	}
+	return counts, nil
+}
@@ -0,0 +1,279 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ListRepositoryImpl struct{}
+
+// ListedUser is a member or subscriber of a list, with when they joined it.
+type ListedUser struct {
+	entity.User
+	JoinedAt time.Time
+}
+
+func (r ListRepositoryImpl) Create(ctx context.Context, list *entity.List) error {
+	return database.DB.WithContext(ctx).Create(list).Error
+}
+
+func (r ListRepositoryImpl) CountByOwner(ctx context.Context, ownerID int64) (int64, error) {
+	var count int64
+	result := database.DB.WithContext(ctx).Model(&entity.List{}).Where("owner_id = ?", ownerID).Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
+
+func (r ListRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.List, error) {
+	var list entity.List
+	result := database.DB.WithContext(ctx).First(&list, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, result.Error
+	}
+	return &list, nil
+}
+
+// Update saves the name, description and privacy of a list. Making a list
+// private drops its subscribers.
+func (r ListRepositoryImpl) Update(ctx context.Context, list *entity.List) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if list.Private {
+			if err := tx.Where("list_id = ?", list.ID).Delete(&entity.ListSubscription{}).Error; err != nil {
+				return err
+			}
+			list.SubscriberCount = 0
+		}
+		return tx.Model(list).
+			Select("name", "description", "private", "subscriber_count", "updated_at").
+			Updates(list).Error
+	})
+}
+
+// Delete removes a list of the given owner with its members and
+// subscriptions.
+func (r ListRepositoryImpl) Delete(ctx context.Context, id, ownerID int64) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("id = ? AND owner_id = ?", id, ownerID).Delete(&entity.List{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+		for _, model := range []any{&entity.ListMember{}, &entity.ListSubscription{}} {
+			if err := tx.Where("list_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindByOwner returns a page of the lists a user owns, newest first,
+// continuing before beforeID when it is not zero. Private lists are only
+// included when includePrivate is set.
+func (r ListRepositoryImpl) FindByOwner(ctx context.Context, ownerID int64, includePrivate bool, beforeID int64, limit int) ([]*entity.List, error) {
+	query := database.DB.WithContext(ctx).Where("owner_id = ?", ownerID)
+	if !includePrivate {
+		query = query.Where("NOT private")
+	}
+	return findListPage(query, beforeID, limit)
+}
+
+// FindSubscribed returns a page of the lists a user subscribes to, most
+// recently created first.
+func (r ListRepositoryImpl) FindSubscribed(ctx context.Context, userID int64, beforeID int64, limit int) ([]*entity.List, error) {
+	query := database.DB.WithContext(ctx).
+		Where("id IN (?)", database.DB.Model(&entity.ListSubscription{}).Select("list_id").Where("user_id = ?", userID))
+	return findListPage(query, beforeID, limit)
+}
+
+// FindMemberships returns a page of the lists a user is a member of that
+// the viewer can see: public lists and the viewer's own.
+func (r ListRepositoryImpl) FindMemberships(ctx context.Context, userID, viewerID int64, beforeID int64, limit int) ([]*entity.List, error) {
+	query := database.DB.WithContext(ctx).
+		Where("id IN (?)", database.DB.Model(&entity.ListMember{}).Select("list_id").Where("user_id = ?", userID)).
+		Where("NOT private OR owner_id = ?", viewerID)
+	return findListPage(query, beforeID, limit)
+}
+
+// AddMember adds a user to a list. Members of public lists are notified,
+// unless they added themselves. Adding a member twice returns ErrDuplicate.
+func (r ListRepositoryImpl) AddMember(ctx context.Context, list *entity.List, userID int64) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.ListMember{ListID: list.ID, UserID: userID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrDuplicate
+		}
+		if err := adjustListCount(tx, list.ID, "member_count", 1); err != nil {
+			return err
+		}
+		if list.Private || userID == list.OwnerID {
+			return nil
+		}
+		return tx.Create(&entity.Notification{
+			RecipientID: userID,
+			SenderID:    &list.OwnerID,
+			Type:        entity.NotificationTypeListAdded,
+			ListID:      &list.ID,
+		}).Error
+	})
+}
+
+// RemoveMember removes a user from a list. It returns ErrRecordNotFound
+// when the user is not a member.
+func (r ListRepositoryImpl) RemoveMember(ctx context.Context, listID, userID int64) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		removed, err := removeListMembers(tx, "list_id = ? AND user_id = ?", listID, userID)
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			return ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// Subscribe subscribes a user to a list. Subscribing twice returns
+// ErrDuplicate.
+func (r ListRepositoryImpl) Subscribe(ctx context.Context, listID, userID int64) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&entity.ListSubscription{ListID: listID, UserID: userID})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrDuplicate
+		}
+		return adjustListCount(tx, listID, "subscriber_count", 1)
+	})
+}
+
+// Unsubscribe unsubscribes a user from a list. It returns
+// ErrRecordNotFound when the user is not subscribed.
+func (r ListRepositoryImpl) Unsubscribe(ctx context.Context, listID, userID int64) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		removed, err := removeListSubscriptions(tx, "list_id = ? AND user_id = ?", listID, userID)
+		if err != nil {
+			return err
+		}
+		if removed == 0 {
+			return ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// FindMemberPage returns a page of the members of a list, most recently
+// added first, continuing after the member cursorUserID added at
+// cursorTime when it is not zero.
+func (r ListRepositoryImpl) FindMemberPage(ctx context.Context, listID int64, cursorTime time.Time, cursorUserID int64, limit int) ([]*ListedUser, error) {
+	return findListUserPage(database.DB.WithContext(ctx).Table("list_members l"), listID, cursorTime, cursorUserID, limit)
+}
+
+// FindSubscriberPage returns a page of the subscribers of a list, most
+// recently subscribed first.
+func (r ListRepositoryImpl) FindSubscriberPage(ctx context.Context, listID int64, cursorTime time.Time, cursorUserID int64, limit int) ([]*ListedUser, error) {
+	return findListUserPage(database.DB.WithContext(ctx).Table("list_subscriptions l"), listID, cursorTime, cursorUserID, limit)
+}
+
+// FindTimeline returns up to limit of the latest tweets and retweets of a
+// list's members that the viewer can see, older than beforeID when it is
+// not zero.
+func (r ListRepositoryImpl) FindTimeline(ctx context.Context, listID, viewerID, beforeID int64, limit int) ([]*entity.Tweet, error) {
+	query := database.DB.WithContext(ctx).
+		Table("tweets t").
+		Select("t.*").
+		Where("t.user_id IN (?)", database.DB.Model(&entity.ListMember{}).Select("user_id").Where("list_id = ?", listID)).
+		Where("t.deleted_at IS NULL").
+		Scopes(visibleTweets(viewerID))
+	if beforeID > 0 {
+		query = query.Where("t.id < ?", beforeID)
+	}
+
+	var tweets []*entity.Tweet
+	if err := query.Order("t.id DESC").Limit(limit).Find(&tweets).Error; err != nil {
+		return nil, err
+	}
+	return tweets, nil
+}
+
+func findListPage(query *gorm.DB, beforeID int64, limit int) ([]*entity.List, error) {
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	var lists []*entity.List
+	if err := query.Order("id DESC").Limit(limit).Find(&lists).Error; err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+// findListUserPage pages through the users of the list_members or
+// list_subscriptions table aliased l.
+func findListUserPage(query *gorm.DB, listID int64, cursorTime time.Time, cursorUserID int64, limit int) ([]*ListedUser, error) {
+	query = query.
+		Select("u.*, l.created_at AS joined_at").
+		Joins("JOIN users u ON u.id = l.user_id").
+		Where("l.list_id = ?", listID)
+	if !cursorTime.IsZero() {
+		query = query.Where("(l.created_at, u.id) < (?, ?)", cursorTime, cursorUserID)
+	}
+
+	var page []*ListedUser
+	if err := query.Order("l.created_at DESC, u.id DESC").Limit(limit).Scan(&page).Error; err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// removeListMembers removes the list members matching the condition,
+// uncounts them and returns how many there were.
+func removeListMembers(tx *gorm.DB, query string, args ...any) (int, error) {
+	var removed []*entity.ListMember
+	if err := tx.Clauses(clause.Returning{}).Where(query, args...).Delete(&removed).Error; err != nil {
+		return 0, err
+	}
+	for _, m := range removed {
+		if err := adjustListCount(tx, m.ListID, "member_count", -1); err != nil {
+			return 0, err
+		}
+	}
+	return len(removed), nil
+}
+
+// removeListSubscriptions removes the list subscriptions matching the
+// condition, uncounts them and returns how many there were.
+func removeListSubscriptions(tx *gorm.DB, query string, args ...any) (int, error) {
+	var removed []*entity.ListSubscription
+	if err := tx.Clauses(clause.Returning{}).Where(query, args...).Delete(&removed).Error; err != nil {
+		return 0, err
+	}
+	for _, s := range removed {
+		if err := adjustListCount(tx, s.ListID, "subscriber_count", -1); err != nil {
+			return 0, err
+		}
+	}
+	return len(removed), nil
+}
+
+func adjustListCount(tx *gorm.DB, listID int64, column string, delta int) error {
+	return tx.Model(&entity.List{}).Where("id = ?", listID).
+		UpdateColumn(column, gorm.Expr(column+" + ?", delta)).Error
+}
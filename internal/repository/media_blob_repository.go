@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type MediaBlobRepositoryImpl struct{}
+
+// Touch restarts the grace period of an existing blob and reports whether the
+// blob exists. A blob that is touched cannot be collected before the grace
+// period has elapsed again.
+func (r MediaBlobRepositoryImpl) Touch(ctx context.Context, hash string) (bool, error) {
+	result := database.DB.WithContext(ctx).Model(&entity.MediaBlob{}).Where("hash = ?", hash).Update("updated_at", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindByHash finds a blob by the SHA-256 of its content.
+func (r MediaBlobRepositoryImpl) FindByHash(ctx context.Context, hash string) (*entity.MediaBlob, error) {
+	var blob entity.MediaBlob
+	result := database.DB.WithContext(ctx).Where("hash = ?", hash).First(&blob)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, result.Error
+	}
+	return &blob, nil
+}
+
+// Ensure records a stored blob. An existing record is kept but its grace
+// period restarts, so the garbage collector cannot delete the file while the
+// caller is about to reference it.
+func (r MediaBlobRepositoryImpl) Ensure(ctx context.Context, blob *entity.MediaBlob) error {
+	return database.DB.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "hash"}},
+		DoUpdates: clause.Assignments(map[string]any{"updated_at": time.Now()}),
+	}).Create(blob).Error
+}
+
+// FindUnreferenced returns up to limit blobs that have had no references
+// since before cutoff.
+func (r MediaBlobRepositoryImpl) FindUnreferenced(ctx context.Context, cutoff time.Time, limit int) ([]*entity.MediaBlob, error) {
+	var blobs []*entity.MediaBlob
+	result := database.DB.WithContext(ctx).
+		Where("ref_count <= 0 AND updated_at < ?", cutoff).
+		Order("updated_at ASC").
+		Limit(limit).
+		Find(&blobs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return blobs, nil
+}
+
+// DeleteUnreferenced removes a blob record if it is still unreferenced and
+// past cutoff, calling deleteFile to drop the stored file before committing.
+// Concurrent uploads of the same content wait for the outcome, so they never
+// reuse a blob whose file is being deleted. It reports whether the blob was
+// removed.
+func (r MediaBlobRepositoryImpl) DeleteUnreferenced(ctx context.Context, hash string, cutoff time.Time, deleteFile func() error) (bool, error) {
+	deleted := false
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("hash = ? AND ref_count <= 0 AND updated_at < ?", hash, cutoff).Delete(&entity.MediaBlob{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := deleteFile(); err != nil {
+			return err
+		}
+		deleted = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return deleted, nil
+}
+
+// adjustBlobRefs applies reference count changes to blobs inside tx. Every
+// change restarts the blob's grace period.
+func adjustBlobRefs(tx *gorm.DB, deltas map[string]int64) error {
+	now := time.Now()
+	for hash, delta := range deltas {
+		if hash == "" || delta == 0 {
+			continue
+		}
+		result := tx.Model(&entity.MediaBlob{}).
+			Where("hash = ?", hash).
+			Updates(map[string]any{"ref_count": gorm.Expr("ref_count + ?", delta), "updated_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 && delta > 0 {
+			return ErrRecordNotFound
+		}
+	}
+	return nil
+}
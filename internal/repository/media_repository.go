@@ -4,17 +4,28 @@ import (
 	"TwClone/internal/database"
 	"TwClone/internal/entity"
 	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MediaRepositoryImpl struct{}
 
+// Create inserts media and takes a reference on its blob.
 func (r MediaRepositoryImpl) Create(ctx context.Context, media *entity.Media) error {
-	return database.DB.WithContext(ctx).Create(media).Error
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(media).Error; err != nil {
+			return err
+		}
+		return adjustBlobRefs(tx, map[string]int64{media.BlobHash: 1})
+	})
 }
 
 func (r MediaRepositoryImpl) FindByTweetID(ctx context.Context, tweetID int64) ([]*entity.Media, error) {
 	var medias []*entity.Media
-	result := database.DB.WithContext(ctx).Where("tweet_id = ?", tweetID).Find(&medias)
+	result := database.DB.WithContext(ctx).Preload("Variants").Where("tweet_id = ?", tweetID).Find(&medias)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -23,9 +34,190 @@ func (r MediaRepositoryImpl) FindByTweetID(ctx context.Context, tweetID int64) (
 
 func (r MediaRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Media, error) {
 	var media entity.Media
-	result := database.DB.WithContext(ctx).First(&media, id)
+	result := database.DB.WithContext(ctx).Preload("Variants").First(&media, id)
 	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
 		return nil, result.Error
 	}
 	return &media, nil
 }
+
+func (r MediaRepositoryImpl) FindByIDs(ctx context.Context, ids []int64) ([]*entity.Media, error) {
+	var medias []*entity.Media
+	result := database.DB.WithContext(ctx).Where("id IN ?", ids).Find(&medias)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return medias, nil
+}
+
+// SumSizeByUser returns the total number of bytes uploaded by a user.
+func (r MediaRepositoryImpl) SumSizeByUser(ctx context.Context, userID int64) (int64, error) {
+	var total int64
+	result := database.DB.WithContext(ctx).Model(&entity.Media{}).Where("user_id = ?", userID).Select("COALESCE(SUM(size), 0)").Scan(&total)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return total, nil
+}
+
+// Delete removes a media record and releases its blobs.
+func (r MediaRepositoryImpl) Delete(ctx context.Context, id int64) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return deleteMedia(tx, tx.Where("id = ?", id))
+	})
+}
+
+// DeleteOrphans removes up to limit media uploaded before cutoff that were
+// never attached to a tweet, a message or a draft, and returns how many were
+// removed.
+func (r MediaRepositoryImpl) DeleteOrphans(ctx context.Context, cutoff time.Time, limit int) (int, error) {
+	var ids []int64
+	result := database.DB.WithContext(ctx).
+		Model(&entity.Media{}).
+		Where("tweet_id IS NULL AND created_at < ?", cutoff).
+		Where("NOT EXISTS (SELECT 1 FROM message_media WHERE message_media.media_id = media.id)").
+		Where("NOT EXISTS (SELECT 1 FROM draft_media WHERE draft_media.media_id = media.id)").
+		Order("id ASC").
+		Limit(limit).
+		Pluck("id", &ids)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// re-check inside the transaction in case an upload got attached meanwhile
+		return deleteMedia(tx, tx.Where("id IN ? AND tweet_id IS NULL", ids).
+			Where("NOT EXISTS (SELECT 1 FROM message_media WHERE message_media.media_id = media.id)").
+			Where("NOT EXISTS (SELECT 1 FROM draft_media WHERE draft_media.media_id = media.id)"))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
+
+// deleteMedia removes the media matched by query together with their
+// variants and releases the blobs they reference.
+func deleteMedia(tx *gorm.DB, query *gorm.DB) error {
+	var medias []*entity.Media
+	if err := query.Preload("Variants").Clauses(clause.Locking{Strength: "UPDATE"}).Find(&medias).Error; err != nil {
+		return err
+	}
+	if len(medias) == 0 {
+		return nil
+	}
+
+	ids := make([]int64, 0, len(medias))
+	deltas := make(map[string]int64)
+	for _, m := range medias {
+		ids = append(ids, m.ID)
+		deltas[m.BlobHash]--
+		for _, v := range m.Variants {
+			deltas[v.BlobHash]--
+		}
+	}
+
+	if err := tx.Where("media_id IN ?", ids).Delete(&entity.MediaVariant{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("id IN ?", ids).Delete(&entity.Media{}).Error; err != nil {
+		return err
+	}
+	return adjustBlobRefs(tx, deltas)
+}
+
+// FindByTweetIDs returns the media attached to any of the given tweets.
+func (r MediaRepositoryImpl) FindByTweetIDs(ctx context.Context, tweetIDs []int64) ([]*entity.Media, error) {
+	var medias []*entity.Media
+	result := database.DB.WithContext(ctx).Preload("Variants").Where("tweet_id IN ?", tweetIDs).Order("id ASC").Find(&medias)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return medias, nil
+}
+
+// FindIDsByStatus returns the ids of all media in the given processing state.
+func (r MediaRepositoryImpl) FindIDsByStatus(ctx context.Context, status string) ([]int64, error) {
+	var ids []int64
+	result := database.DB.WithContext(ctx).Model(&entity.Media{}).Where("status = ?", status).Order("id ASC").Pluck("id", &ids)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return ids, nil
+}
+
+// MarkReady stores the outcome of processing an image: its sanitised blob,
+// final size and dimensions, its placeholder and its resized variants. The
+// reference on the blob of the original upload, rawBlobHash, is released.
+// Nothing is saved when the media was deleted or replaced while it was
+// being processed; the blobs saved for it are then left unreferenced for
+// the garbage collector.
+func (r MediaRepositoryImpl) MarkReady(ctx context.Context, media *entity.Media, rawBlobHash string, variants []*entity.MediaVariant) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.Media{}).
+			Where("id = ? AND status = ? AND blob_hash = ?", media.ID, entity.MediaStatusProcessing, rawBlobHash).
+			Updates(map[string]any{
+				"status":      entity.MediaStatusReady,
+				"blob_hash":   media.BlobHash,
+				"storage_key": media.StorageKey,
+				"url":         media.URL,
+				"size":        media.Size,
+				"width":       media.Width,
+				"height":      media.Height,
+				"blurhash":    media.Blurhash,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		media.Status = entity.MediaStatusReady
+
+		var old []*entity.MediaVariant
+		if err := tx.Where("media_id = ?", media.ID).Find(&old).Error; err != nil {
+			return err
+		}
+		deltas := map[string]int64{rawBlobHash: -1}
+		deltas[media.BlobHash]++
+		for _, v := range old {
+			deltas[v.BlobHash]--
+		}
+		for _, v := range variants {
+			deltas[v.BlobHash]++
+		}
+
+		if err := tx.Where("media_id = ?", media.ID).Delete(&entity.MediaVariant{}).Error; err != nil {
+			return err
+		}
+		for _, v := range variants {
+			v.MediaID = media.ID
+		}
+		if len(variants) > 0 {
+			if err := tx.Create(&variants).Error; err != nil {
+				return err
+			}
+		}
+		return adjustBlobRefs(tx, deltas)
+	})
+}
+
+// MarkFailed flags media whose processing could not complete.
+func (r MediaRepositoryImpl) MarkFailed(ctx context.Context, id int64) error {
+	return database.DB.WithContext(ctx).Model(&entity.Media{}).Where("id = ?", id).Update("status", entity.MediaStatusFailed).Error
+}
+
+// UpdateMetadata saves the alt text and sensitivity flags of media.
+func (r MediaRepositoryImpl) UpdateMetadata(ctx context.Context, media *entity.Media) error {
+	return database.DB.WithContext(ctx).Model(&entity.Media{}).Where("id = ?", media.ID).Updates(map[string]any{
+		"alt_text":        media.AltText,
+		"sensitive":       media.Sensitive,
+		"content_warning": media.ContentWarning,
+	}).Error
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type MessageRepositoryImpl struct{}
+
+// Create inserts a message with its media references, bumps the
+// conversation's last activity time and advances the sender's own read
+// position in a single transaction.
+func (r MessageRepositoryImpl) Create(ctx context.Context, msg *entity.Message, mediaIDs []int64) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(msg).Error; err != nil {
+			return err
+		}
+
+		if len(mediaIDs) > 0 {
+			mm := make([]*entity.MessageMedia, 0, len(mediaIDs))
+			for _, id := range mediaIDs {
+				mm = append(mm, &entity.MessageMedia{MessageID: msg.ID, MediaID: id})
+			}
+			if err := tx.Create(&mm).Error; err != nil {
+				return err
+			}
+		}
+
+		return touchConversation(tx, msg)
+	})
+}
+
+// CreateEncrypted inserts an end-to-end encrypted message together with the
+// ciphertext envelope of every recipient device.
+func (r MessageRepositoryImpl) CreateEncrypted(ctx context.Context, msg *entity.Message, envelopes []*entity.MessageEnvelope) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(msg).Error; err != nil {
+			return err
+		}
+
+		for _, env := range envelopes {
+			env.MessageID = msg.ID
+		}
+		if err := tx.Create(&envelopes).Error; err != nil {
+			return err
+		}
+
+		return touchConversation(tx, msg)
+	})
+}
+
+// touchConversation bumps the conversation's last activity time and advances
+// the sender's own read position to the new message.
+func touchConversation(tx *gorm.DB, msg *entity.Message) error {
+	if err := tx.Model(&entity.Conversation{}).Where("id = ?", msg.ConversationID).Update("last_message_at", msg.CreatedAt).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&entity.ConversationMember{}).
+		Where("conversation_id = ? AND user_id = ?", msg.ConversationID, msg.SenderID).
+		Updates(map[string]any{"last_read_message_id": msg.ID, "last_delivered_message_id": msg.ID}).Error
+}
+
+// FindByID finds a message by id.
+func (r MessageRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Message, error) {
+	var msg entity.Message
+	result := database.DB.WithContext(ctx).First(&msg, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, result.Error
+	}
+	return &msg, nil
+}
+
+// FindByConversation returns up to limit messages of a conversation, newest
+// first. When beforeID is non-zero only messages older than it are returned.
+func (r MessageRepositoryImpl) FindByConversation(ctx context.Context, conversationID, beforeID int64, limit int) ([]*entity.Message, error) {
+	var msgs []*entity.Message
+	query := database.DB.WithContext(ctx).Where("conversation_id = ?", conversationID)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+	result := query.Order("id DESC").Limit(limit).Find(&msgs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return msgs, nil
+}
+
+// FindMediaIDs returns the media attached to each of the given messages.
+func (r MessageRepositoryImpl) FindMediaIDs(ctx context.Context, messageIDs []int64) (map[int64][]int64, error) {
+	var mm []*entity.MessageMedia
+	result := database.DB.WithContext(ctx).Where("message_id IN ?", messageIDs).Find(&mm)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	media := make(map[int64][]int64, len(messageIDs))
+	for _, m := range mm {
+		media[m.MessageID] = append(media[m.MessageID], m.MediaID)
+	}
+	return media, nil
+}
+
+// MediaVisibleTo reports whether media was sent in a conversation the user is
+// still a member of.
+func (r MessageRepositoryImpl) MediaVisibleTo(ctx context.Context, mediaID, userID int64) (bool, error) {
+	var count int64
+	result := database.DB.WithContext(ctx).
+		Model(&entity.MessageMedia{}).
+		Joins("JOIN messages ON messages.id = message_media.message_id").
+		Joins("JOIN conversation_members ON conversation_members.conversation_id = messages.conversation_id").
+		Where("message_media.media_id = ? AND conversation_members.user_id = ? AND conversation_members.left_at IS NULL", mediaID, userID).
+		Limit(1).
+		Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
+// FindEnvelopes returns the envelopes addressed to one device of a user for
+// each of the given messages.
+func (r MessageRepositoryImpl) FindEnvelopes(ctx context.Context, messageIDs []int64, userID int64, deviceID string) (map[int64]*entity.MessageEnvelope, error) {
+	var envs []*entity.MessageEnvelope
+	result := database.DB.WithContext(ctx).
+		Where("message_id IN ? AND recipient_user_id = ? AND recipient_device_id = ?", messageIDs, userID, deviceID).
+		Find(&envs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	byMessage := make(map[int64]*entity.MessageEnvelope, len(envs))
+	for _, env := range envs {
+		byMessage[env.MessageID] = env
+	}
+	return byMessage, nil
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"context"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+
+	"gorm.io/gorm/clause"
+)
+
+type MuteRepositoryImpl struct{}
+
+// Create mutes a user. Muting someone already muted is a no-op.
+func (r MuteRepositoryImpl) Create(ctx context.Context, mute *entity.Mute) error {
+	return database.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(mute).Error
+}
+
+func (r MuteRepositoryImpl) Delete(ctx context.Context, muterID, mutedID int64) error {
+	return database.DB.WithContext(ctx).Where("muter_id = ? AND muted_id = ?", muterID, mutedID).Delete(&entity.Mute{}).Error
+}
+
+// FindByMuter returns the users muted by muterID, most recent first.
+func (r MuteRepositoryImpl) FindByMuter(ctx context.Context, muterID int64) ([]*entity.Mute, error) {
+	var mutes []*entity.Mute
+	result := database.DB.WithContext(ctx).Where("muter_id = ?", muterID).Order("created_at DESC").Find(&mutes)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return mutes, nil
+}
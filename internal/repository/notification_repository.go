@@ -12,6 +12,13 @@ func (r NotificationRepositoryImpl) Create(ctx context.Context, notif *entity.No
 	return database.DB.WithContext(ctx).Create(notif).Error
 }
 
+func (r NotificationRepositoryImpl) CreateMany(ctx context.Context, notifs []*entity.Notification) error {
+	if len(notifs) == 0 {
+		return nil
+	}
+	return database.DB.WithContext(ctx).Create(&notifs).Error
+}
+
 func (r NotificationRepositoryImpl) FindByRecipientID(ctx context.Context, recipientID int64) ([]*entity.Notification, error) {
 	var notifs []*entity.Notification
 	result := database.DB.WithContext(ctx).Where("recipient_id = ?", recipientID).Find(&notifs)
@@ -0,0 +1,140 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrPollClosed is returned when voting on a poll that has ended.
+var ErrPollClosed = errors.New("poll closed")
+
+type PollRepositoryImpl struct{}
+
+// FindByTweetIDs returns the polls of the given tweets with their options in
+// order.
+func (r PollRepositoryImpl) FindByTweetIDs(ctx context.Context, tweetIDs []int64) ([]*entity.Poll, error) {
+	var polls []*entity.Poll
+	if len(tweetIDs) == 0 {
+		return polls, nil
+	}
+	result := database.DB.WithContext(ctx).
+		Preload("Options", func(db *gorm.DB) *gorm.DB { return db.Order("position") }).
+		Where("tweet_id IN ?", tweetIDs).
+		Find(&polls)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return polls, nil
+}
+
+// FindByTweetID finds the poll of a tweet.
+func (r PollRepositoryImpl) FindByTweetID(ctx context.Context, tweetID int64) (*entity.Poll, error) {
+	polls, err := r.FindByTweetIDs(ctx, []int64{tweetID})
+	if err != nil {
+		return nil, err
+	}
+	if len(polls) == 0 {
+		return nil, ErrRecordNotFound
+	}
+	return polls[0], nil
+}
+
+// FindVotes returns the option userID voted for in each of the given polls
+// they voted in.
+func (r PollRepositoryImpl) FindVotes(ctx context.Context, pollIDs []int64, userID int64) (map[int64]int64, error) {
+	votes := make(map[int64]int64, len(pollIDs))
+	if len(pollIDs) == 0 || userID == 0 {
+		return votes, nil
+	}
+
+	var rows []*entity.PollVote
+	result := database.DB.WithContext(ctx).Where("poll_id IN ? AND user_id = ?", pollIDs, userID).Find(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for _, v := range rows {
+		votes[v.PollID] = v.OptionID
+	}
+	return votes, nil
+}
+
+// Vote records userID's vote for an option and counts it. It returns
+// ErrDuplicate when the user already voted, ErrRecordNotFound when the
+// option is not one of the poll's and ErrPollClosed when the poll has ended.
+func (r PollRepositoryImpl) Vote(ctx context.Context, pollID, optionID, userID int64) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&entity.PollVote{PollID: pollID, UserID: userID, OptionID: optionID}).Error; err != nil {
+			errMsg := err.Error()
+			if strings.Contains(errMsg, "duplicate key") || strings.Contains(errMsg, "unique constraint") {
+				return ErrDuplicate
+			}
+			return err
+		}
+
+		result := tx.Model(&entity.PollOption{}).
+			Where("id = ? AND poll_id = ?", optionID, pollID).
+			Update("votes", gorm.Expr("votes + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRecordNotFound
+		}
+
+		result = tx.Model(&entity.Poll{}).
+			Where("id = ? AND ends_at > ?", pollID, time.Now()).
+			Update("total_votes", gorm.Expr("total_votes + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrPollClosed
+		}
+		return nil
+	})
+}
+
+// CloseDue closes up to limit polls that ended before now, notifying the
+// author and every voter of each, and returns how many were closed. Polls
+// being closed by another worker are skipped.
+func (r PollRepositoryImpl) CloseDue(ctx context.Context, now time.Time, limit int) (int, error) {
+	var ids []int64
+	err := database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&entity.Poll{}).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("closed_at IS NULL AND ends_at <= ?", now).
+			Order("ends_at").
+			Limit(limit).
+			Pluck("id", &ids)
+		if result.Error != nil || len(ids) == 0 {
+			return result.Error
+		}
+
+		// the author is told once even if they voted
+		err := tx.Exec(`
+			INSERT INTO notifications (recipient_id, type, tweet_id, created_at)
+			SELECT r.user_id, ?, p.tweet_id, ? FROM polls p
+			JOIN LATERAL (
+				SELECT t.user_id FROM tweets t WHERE t.id = p.tweet_id
+				UNION
+				SELECT v.user_id FROM poll_votes v WHERE v.poll_id = p.id
+			) r ON TRUE
+			WHERE p.id IN ?`, entity.NotificationTypePollClosed, now, ids).Error
+		if err != nil {
+			return err
+		}
+		return tx.Model(&entity.Poll{}).Where("id IN ?", ids).Update("closed_at", now).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(ids), nil
+}
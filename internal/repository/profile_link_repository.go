@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type ProfileLinkRepositoryImpl struct{}
+
+// FindByUser returns a user's profile links in order.
+func (r ProfileLinkRepositoryImpl) FindByUser(ctx context.Context, userID int64) ([]*entity.ProfileLink, error) {
+	var links []*entity.ProfileLink
+	result := database.DB.WithContext(ctx).Where("user_id = ?", userID).Order("position").Find(&links)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return links, nil
+}
+
+// Replace sets a user's profile links, in order. Links whose URL did not
+// change keep their verification; the others are verified anew.
+func (r ProfileLinkRepositoryImpl) Replace(ctx context.Context, userID int64, links []*entity.ProfileLink) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current []*entity.ProfileLink
+		if err := tx.Where("user_id = ?", userID).Find(&current).Error; err != nil {
+			return err
+		}
+		byURL := make(map[string]*entity.ProfileLink, len(current))
+		for _, l := range current {
+			byURL[l.URL] = l
+		}
+
+		if err := tx.Where("user_id = ?", userID).Delete(&entity.ProfileLink{}).Error; err != nil {
+			return err
+		}
+		if len(links) == 0 {
+			return nil
+		}
+		for i, l := range links {
+			l.ID = 0
+			l.UserID = userID
+			l.Position = i
+			l.VerifiedAt, l.CheckedAt = nil, nil
+			if prev, ok := byURL[l.URL]; ok {
+				l.VerifiedAt, l.CheckedAt = prev.VerifiedAt, prev.CheckedAt
+			}
+		}
+		return tx.Create(&links).Error
+	})
+}
+
+// FindDue returns links that were never checked or were last checked before
+// the given time, never checked first.
+func (r ProfileLinkRepositoryImpl) FindDue(ctx context.Context, checkedBefore time.Time, limit int) ([]*entity.ProfileLink, error) {
+	var links []*entity.ProfileLink
+	result := database.DB.WithContext(ctx).
+		Where("checked_at IS NULL OR checked_at < ?", checkedBefore).
+		Order("checked_at NULLS FIRST, id").
+		Limit(limit).
+		Find(&links)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return links, nil
+}
+
+// MarkChecked records the outcome of checking a link. A link that was
+// replaced in the meantime is left alone.
+func (r ProfileLinkRepositoryImpl) MarkChecked(ctx context.Context, link *entity.ProfileLink, verified bool, at time.Time) error {
+	updates := map[string]any{"checked_at": at, "verified_at": nil}
+	if verified {
+		updates["verified_at"] = at
+		if link.VerifiedAt != nil {
+			updates["verified_at"] = *link.VerifiedAt
+		}
+	}
+	return database.DB.WithContext(ctx).Model(&entity.ProfileLink{}).
+		Where("id = ? AND url = ?", link.ID, link.URL).
+		Updates(updates).Error
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+)
+
+type ReportRepositoryImpl struct{}
+
+// Create queues a report for moderation.
+func (r ReportRepositoryImpl) Create(ctx context.Context, report *entity.Report) error {
+	if report.Status == "" {
+		report.Status = entity.ReportStatusOpen
+	}
+	return database.DB.WithContext(ctx).Create(report).Error
+}
+
+// FindByStatus returns up to limit reports with the given status, oldest
+// first, continuing after afterID when it is not zero.
+func (r ReportRepositoryImpl) FindByStatus(ctx context.Context, status string, afterID int64, limit int) ([]*entity.Report, error) {
+	query := database.DB.WithContext(ctx).Where("status = ?", status)
+	if afterID > 0 {
+		query = query.Where("id > ?", afterID)
+	}
+	var reports []*entity.Report
+	if err := query.Order("id ASC").Limit(limit).Find(&reports).Error; err != nil {
+		return nil, err
+	}
+	return reports, nil
+}
+
+// Resolve closes an open report. It returns ErrRecordNotFound when there is
+// no open report with the id.
+func (r ReportRepositoryImpl) Resolve(ctx context.Context, id int64) error {
+	result := database.DB.WithContext(ctx).Model(&entity.Report{}).
+		Where("id = ? AND status = ?", id, entity.ReportStatusOpen).
+		Update("status", entity.ReportStatusResolved)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+type SavedSearchRepositoryImpl struct{}
+
+// Create saves a search. Saving the same query twice returns ErrDuplicate.
+func (r SavedSearchRepositoryImpl) Create(ctx context.Context, search *entity.SavedSearch) error {
+	result := database.DB.WithContext(ctx).Create(search)
+	if result.Error != nil {
+		errMsg := result.Error.Error()
+		if strings.Contains(errMsg, "duplicate key") || strings.Contains(errMsg, "unique constraint") {
+			return ErrDuplicate
+		}
+		return result.Error
+	}
+	return nil
+}
+
+func (r SavedSearchRepositoryImpl) CountByUser(ctx context.Context, userID int64) (int64, error) {
+	var count int64
+	result := database.DB.WithContext(ctx).Model(&entity.SavedSearch{}).Where("user_id = ?", userID).Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
+
+// FindByUser returns a user's saved searches, oldest first.
+func (r SavedSearchRepositoryImpl) FindByUser(ctx context.Context, userID int64) ([]*entity.SavedSearch, error) {
+	var searches []*entity.SavedSearch
+	result := database.DB.WithContext(ctx).Where("user_id = ?", userID).Order("id").Find(&searches)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return searches, nil
+}
+
+// FindByID finds a saved search of the given user.
+func (r SavedSearchRepositoryImpl) FindByID(ctx context.Context, id, userID int64) (*entity.SavedSearch, error) {
+	var search entity.SavedSearch
+	result := database.DB.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).First(&search)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, result.Error
+	}
+	return &search, nil
+}
+
+// Update saves the name and subscription of a search. Subscribing moves the
+// search's high-water mark to the given tweet so that only tweets posted
+// afterwards raise alerts.
+func (r SavedSearchRepositoryImpl) Update(ctx context.Context, search *entity.SavedSearch) error {
+	return database.DB.WithContext(ctx).Model(search).
+		Select("name", "subscribed", "last_matched_tweet_id", "updated_at").
+		Updates(search).Error
+}
+
+func (r SavedSearchRepositoryImpl) Delete(ctx context.Context, id, userID int64) error {
+	result := database.DB.WithContext(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(&entity.SavedSearch{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// FindDue returns up to limit subscribed searches with an id above afterID
+// that have not alerted since alertedBefore.
+func (r SavedSearchRepositoryImpl) FindDue(ctx context.Context, alertedBefore time.Time, afterID int64, limit int) ([]*entity.SavedSearch, error) {
+	var searches []*entity.SavedSearch
+	result := database.DB.WithContext(ctx).
+		Where("subscribed AND id > ? AND (last_alerted_at IS NULL OR last_alerted_at < ?)", afterID, alertedBefore).
+		Order("id").
+		Limit(limit).
+		Find(&searches)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return searches, nil
+}
+
+// RecordAlert stores a search alert and moves the search's high-water mark to
+// the newest tweet it covers. Nothing is stored if the search was changed or
+// already alerted about these tweets in the meantime.
+func (r SavedSearchRepositoryImpl) RecordAlert(ctx context.Context, search *entity.SavedSearch, notif *entity.Notification) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&entity.SavedSearch{}).
+			Where("id = ? AND subscribed AND last_matched_tweet_id = ?", search.ID, search.LastMatchedTweetID).
+			Updates(map[string]any{"last_matched_tweet_id": *notif.TweetID, "last_alerted_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return tx.Create(notif).Error
+	})
+}
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type SuggestionRepositoryImpl struct{}
+
+// SuggestionCandidate is an account followed by accounts a user follows,
+// with what makes it relevant to the user.
+type SuggestionCandidate struct {
+	SuggestedID    int64
+	MutualCount    int64
+	SharedHashtags int64
+	LastTweetAt    *time.Time
+}
+
+// SuggestedUser is a suggested account with why it was suggested.
+type SuggestedUser struct {
+	entity.User
+	MutualCount    int64
+	SharedHashtags int64
+}
+
+// FindCandidates walks the follow graph two steps out from a user and
+// returns up to limit of the accounts reached through the most accounts the
+// user follows. SharedHashtags counts the hashtags both the user and the
+// account tweeted since interestsSince. Accounts the user follows, asked to
+// follow, blocks, is blocked by, mutes or dismissed are left out.
+func (r SuggestionRepositoryImpl) FindCandidates(ctx context.Context, userID int64, interestsSince time.Time, limit int) ([]*SuggestionCandidate, error) {
+	db := database.DB.WithContext(ctx)
+	mutual := db.Table("follows f1").
+		Select("f2.following_id AS suggested_id, COUNT(*) AS mutual_count").
+		Joins("JOIN follows f2 ON f2.follower_id = f1.following_id").
+		Where("f1.follower_id = ? AND f2.following_id <> ?", userID, userID).
+		Group("f2.following_id")
+
+	var candidates []*SuggestionCandidate
+	result := db.Table("(?) AS m", mutual).
+		Select(`m.suggested_id, m.mutual_count,
+			(SELECT COUNT(DISTINCT th.hashtag_id) FROM tweets t JOIN tweet_hashtags th ON th.tweet_id = t.id
+			WHERE t.user_id = m.suggested_id AND t.created_at >= ? AND th.hashtag_id IN (
+				SELECT mth.hashtag_id FROM tweets mt JOIN tweet_hashtags mth ON mth.tweet_id = mt.id
+				WHERE mt.user_id = ? AND mt.created_at >= ?)) AS shared_hashtags,
+			(SELECT MAX(t.created_at) FROM tweets t WHERE t.user_id = m.suggested_id AND t.deleted_at IS NULL) AS last_tweet_at`,
+			interestsSince, userID, interestsSince).
+		Scopes(suggestable(userID, "m.suggested_id")).
+		Order("m.mutual_count DESC, m.suggested_id").
+		Limit(limit).
+		Scan(&candidates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return candidates, nil
+}
+
+// Replace sets the precomputed suggestions of a user.
+func (r SuggestionRepositoryImpl) Replace(ctx context.Context, userID int64, suggestions []*entity.Suggestion) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&entity.Suggestion{}).Error; err != nil {
+			return err
+		}
+		if len(suggestions) == 0 {
+			return nil
+		}
+		return tx.Create(&suggestions).Error
+	})
+}
+
+// FindByUser returns up to limit of a user's precomputed suggestions, best
+// first. Suggestions that stopped applying since they were computed, because
+// the user followed, blocked, muted or dismissed the account in the
+// meantime, are left out.
+func (r SuggestionRepositoryImpl) FindByUser(ctx context.Context, userID int64, limit int) ([]*SuggestedUser, error) {
+	var suggested []*SuggestedUser
+	result := database.DB.WithContext(ctx).
+		Table("suggestions s").
+		Select("u.*, s.mutual_count, s.shared_hashtags").
+		Joins("JOIN users u ON u.id = s.suggested_id").
+		Where("s.user_id = ?", userID).
+		Scopes(suggestable(userID, "s.suggested_id")).
+		Order("s.score DESC, s.suggested_id").
+		Limit(limit).
+		Scan(&suggested)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return suggested, nil
+}
+
+// FindUserIDsAfter returns, in order, up to limit ids greater than afterID
+// of users who follow someone and so can get suggestions.
+func (r SuggestionRepositoryImpl) FindUserIDsAfter(ctx context.Context, afterID int64, limit int) ([]int64, error) {
+	var ids []int64
+	result := database.DB.WithContext(ctx).Model(&entity.Follow{}).
+		Distinct("follower_id").
+		Where("follower_id > ?", afterID).
+		Order("follower_id").
+		Limit(limit).
+		Pluck("follower_id", &ids)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return ids, nil
+}
+
+// Dismiss stops suggesting an account to a user. Dismissing an account
+// twice is a no-op.
+func (r SuggestionRepositoryImpl) Dismiss(ctx context.Context, userID, dismissedID int64) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		dismissal := entity.SuggestionDismissal{UserID: userID, DismissedID: dismissedID}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&dismissal).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ? AND suggested_id = ?", userID, dismissedID).Delete(&entity.Suggestion{}).Error
+	})
+}
+
+// suggestable leaves out the accounts in column that should not be
+// suggested to the user.
+func suggestable(userID int64, column string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Where("NOT EXISTS (SELECT 1 FROM follows f WHERE f.follower_id = ? AND f.following_id = "+column+")", userID).
+			Where("NOT EXISTS (SELECT 1 FROM follow_requests fr WHERE fr.requester_id = ? AND fr.target_id = "+column+")", userID).
+			Where("NOT EXISTS (SELECT 1 FROM blocks b WHERE (b.blocker_id = ? AND b.blocked_id = "+column+") OR (b.blocker_id = "+column+" AND b.blocked_id = ?))", userID, userID).
+			Where("NOT EXISTS (SELECT 1 FROM mutes mu WHERE mu.muter_id = ? AND mu.muted_id = "+column+")", userID).
+			Where("NOT EXISTS (SELECT 1 FROM suggestion_dismissals d WHERE d.user_id = ? AND d.dismissed_id = "+column+")", userID)
+	}
+}
@@ -0,0 +1,187 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TimelineRepositoryImpl struct{}
+
+// TimelineCandidate is a tweet that may go into a user's ranked timeline,
+// with the features it is ranked by. InNetwork is set for tweets of the
+// user and the accounts they follow; the others reached the user through
+// the SocialProof accounts they follow liking or retweeting them.
+type TimelineCandidate struct {
+	entity.Tweet
+	InNetwork    bool
+	LikeCount    int64
+	ReplyCount   int64
+	RetweetCount int64
+	SocialProof  int64
+	HasMedia     bool
+}
+
+// AuthorSignals is what a user's past behaviour says about an author:
+// Interactions counts the likes and replies between the two in either
+// direction, NotInterested the author's tweets the user was not interested
+// in.
+type AuthorSignals struct {
+	AuthorID      int64
+	Interactions  int64
+	NotInterested int64
+}
+
+// FindHome returns up to limit of the latest tweets and retweets of a user
+// and the accounts they follow that the user can see, older than beforeID
+// when it is not zero. Tweets the user was not interested in are left out.
+func (r TimelineRepositoryImpl) FindHome(ctx context.Context, userID, beforeID int64, limit int) ([]*entity.Tweet, error) {
+	query := database.DB.WithContext(ctx).
+		Table("tweets t").
+		Select("t.*").
+		Where("t.user_id = ? OR t.user_id IN (?)", userID, followees(userID)).
+		Where("t.deleted_at IS NULL").
+		Scopes(visibleTweets(userID), interesting(userID))
+	if beforeID > 0 {
+		query = query.Where("t.id < ?", beforeID)
+	}
+
+	var tweets []*entity.Tweet
+	if err := query.Order("t.id DESC").Limit(limit).Find(&tweets).Error; err != nil {
+		return nil, err
+	}
+	return tweets, nil
+}
+
+// FindCandidates returns up to limit of the newest tweets posted between
+// since and until that can go into a user's ranked timeline: the tweets of the user
+// and the accounts they follow, and one hop beyond, the tweets those
+// accounts liked or retweeted. Retweets themselves are not candidates, the
+// tweets they retweet are. Likes and retweets by muted accounts are not
+// counted, and tweets the user was not interested in are left out.
+func (r TimelineRepositoryImpl) FindCandidates(ctx context.Context, userID int64, since, until time.Time, limit int) ([]*TimelineCandidate, error) {
+	engagers := database.DB.Table("follows ef").
+		Select("ef.following_id").
+		Where("ef.follower_id = ?", userID).
+		Where("NOT EXISTS (SELECT 1 FROM mutes em WHERE em.muter_id = ef.follower_id AND em.muted_id = ef.following_id)")
+	liked := database.DB.Table("likes l").Select("l.tweet_id").Where("l.user_id IN (?)", engagers)
+	retweeted := database.DB.Table("tweets rt").
+		Select("rt.retweeted_tweet_id").
+		Where("rt.user_id IN (?) AND rt.deleted_at IS NULL", engagers)
+
+	var candidates []*TimelineCandidate
+	result := database.DB.WithContext(ctx).
+		Table("tweets t").
+		Select(`t.*,
+			(t.user_id = ? OR t.user_id IN (?)) AS in_network,
+			(SELECT COUNT(*) FROM likes l WHERE l.tweet_id = t.id) AS like_count,
+			(SELECT COUNT(*) FROM tweets r WHERE r.reply_to_tweet_id = t.id AND r.deleted_at IS NULL) AS reply_count,
+			(SELECT COUNT(*) FROM tweets r WHERE r.retweeted_tweet_id = t.id AND r.deleted_at IS NULL) AS retweet_count,
+			(SELECT COUNT(*) FROM likes l WHERE l.tweet_id = t.id AND l.user_id IN (?)) +
+				(SELECT COUNT(*) FROM tweets r WHERE r.retweeted_tweet_id = t.id AND r.deleted_at IS NULL AND r.user_id IN (?)) AS social_proof,
+			EXISTS (SELECT 1 FROM media m WHERE m.tweet_id = t.id) AS has_media`,
+			userID, followees(userID), engagers, engagers).
+		Where("t.user_id = ? OR t.user_id IN (?) OR t.id IN (?) OR t.id IN (?)", userID, followees(userID), liked, retweeted).
+		Where("t.retweeted_tweet_id IS NULL AND t.deleted_at IS NULL AND t.created_at BETWEEN ? AND ?", since, until).
+		Scopes(visibleTweets(userID), interesting(userID)).
+		Order("t.id DESC").
+		Limit(limit).
+		Scan(&candidates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return candidates, nil
+}
+
+// FindAuthorSignals returns what a user's likes, replies and "not
+// interested" feedback since since say about each of the given authors, by
+// author id. Authors without any are left out.
+func (r TimelineRepositoryImpl) FindAuthorSignals(ctx context.Context, userID int64, authorIDs []int64, since time.Time) (map[int64]*AuthorSignals, error) {
+	byAuthor := make(map[int64]*AuthorSignals, len(authorIDs))
+	if len(authorIDs) == 0 {
+		return byAuthor, nil
+	}
+
+	var signals []*AuthorSignals
+	result := database.DB.WithContext(ctx).Raw(`
+		SELECT author_id, SUM(interactions) AS interactions, SUM(not_interested) AS not_interested
+		FROM (
+			SELECT t.user_id AS author_id, COUNT(*) AS interactions, 0 AS not_interested
+			FROM likes l JOIN tweets t ON t.id = l.tweet_id
+			WHERE l.user_id = ? AND l.created_at >= ? AND t.user_id IN ?
+			GROUP BY t.user_id
+			UNION ALL
+			SELECT p.user_id, COUNT(*), 0
+			FROM tweets r JOIN tweets p ON p.id = r.reply_to_tweet_id
+			WHERE r.user_id = ? AND r.created_at >= ? AND p.user_id IN ?
+			GROUP BY p.user_id
+			UNION ALL
+			SELECT l.user_id, COUNT(*), 0
+			FROM likes l JOIN tweets t ON t.id = l.tweet_id
+			WHERE t.user_id = ? AND l.created_at >= ? AND l.user_id IN ?
+			GROUP BY l.user_id
+			UNION ALL
+			SELECT r.user_id, COUNT(*), 0
+			FROM tweets r JOIN tweets p ON p.id = r.reply_to_tweet_id
+			WHERE p.user_id = ? AND r.created_at >= ? AND r.user_id IN ?
+			GROUP BY r.user_id
+			UNION ALL
+			SELECT d.author_id, 0, COUNT(*)
+			FROM tweet_dismissals d
+			WHERE d.user_id = ? AND d.created_at >= ? AND d.author_id IN ?
+			GROUP BY d.author_id
+		) s
+		WHERE author_id <> ?
+		GROUP BY author_id`,
+		userID, since, authorIDs,
+		userID, since, authorIDs,
+		userID, since, authorIDs,
+		userID, since, authorIDs,
+		userID, since, authorIDs,
+		userID).
+		Scan(&signals)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for _, s := range signals {
+		byAuthor[s.AuthorID] = s
+	}
+	return byAuthor, nil
+}
+
+// Dismiss records that a user is not interested in a tweet. Dismissing a
+// tweet twice is a no-op.
+func (r TimelineRepositoryImpl) Dismiss(ctx context.Context, dismissal *entity.TweetDismissal) error {
+	return database.DB.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(dismissal).Error
+}
+
+// Undismiss takes back that a user is not interested in a tweet. It returns
+// ErrRecordNotFound when they never said so.
+func (r TimelineRepositoryImpl) Undismiss(ctx context.Context, userID, tweetID int64) error {
+	result := database.DB.WithContext(ctx).Where("user_id = ? AND tweet_id = ?", userID, tweetID).Delete(&entity.TweetDismissal{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// followees selects the ids of the accounts a user follows.
+func followees(userID int64) *gorm.DB {
+	return database.DB.Model(&entity.Follow{}).Select("following_id").Where("follower_id = ?", userID)
+}
+
+// interesting leaves out the tweets t the user was not interested in, and
+// retweets of them.
+func interesting(userID int64) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("NOT EXISTS (SELECT 1 FROM tweet_dismissals d WHERE d.user_id = ? AND d.tweet_id = COALESCE(t.retweeted_tweet_id, t.id))", userID)
+	}
+}
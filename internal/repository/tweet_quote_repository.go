@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+
+	"gorm.io/gorm"
+)
+
+// FindRetweet finds userID's retweet of a tweet.
+func (r TweetRepositoryImpl) FindRetweet(ctx context.Context, userID, tweetID int64) (*entity.Tweet, error) {
+	var tweet entity.Tweet
+	result := database.DB.WithContext(ctx).
+		Where("user_id = ? AND retweeted_tweet_id = ? AND deleted_at IS NULL", userID, tweetID).
+		First(&tweet)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, result.Error
+	}
+	return &tweet, nil
+}
+
+// FindOriginal finds a live tweet, or the original if it is a retweet.
+func (r TweetRepositoryImpl) FindOriginal(ctx context.Context, id int64) (*entity.Tweet, error) {
+	tweet, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tweet.RetweetedTweetID == nil {
+		return tweet, nil
+	}
+	return r.FindByID(ctx, *tweet.RetweetedTweetID)
+}
+
+// FindVisibleOriginal is FindOriginal for tweets viewerID may see; others
+// are reported as ErrRecordNotFound.
+func (r TweetRepositoryImpl) FindVisibleOriginal(ctx context.Context, id, viewerID int64) (*entity.Tweet, error) {
+	tweet, err := r.FindOriginal(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	visible, err := r.FindVisibleIDs(ctx, []int64{tweet.ID}, viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if !visible[tweet.ID] {
+		return nil, ErrRecordNotFound
+	}
+	return tweet, nil
+}
+
+// FindRetweets returns up to limit of the retweets of a tweet that viewerID
+// may see, newest first. When beforeID is non-zero only retweets older than
+// it are returned.
+func (r TweetRepositoryImpl) FindRetweets(ctx context.Context, tweetID, viewerID, beforeID int64, limit int) ([]*entity.Tweet, error) {
+	return r.findReferencing(ctx, "t.retweeted_tweet_id = ?", tweetID, viewerID, beforeID, limit)
+}
+
+// FindQuotes returns up to limit of the quote tweets of a tweet that
+// viewerID may see, newest first. When beforeID is non-zero only quotes
+// older than it are returned.
+func (r TweetRepositoryImpl) FindQuotes(ctx context.Context, tweetID, viewerID, beforeID int64, limit int) ([]*entity.Tweet, error) {
+	return r.findReferencing(ctx, "t.quoted_tweet_id = ?", tweetID, viewerID, beforeID, limit)
+}
+
+func (r TweetRepositoryImpl) findReferencing(ctx context.Context, cond string, tweetID, viewerID, beforeID int64, limit int) ([]*entity.Tweet, error) {
+	query := database.DB.WithContext(ctx).
+		Table("tweets t").
+		Select("t.*").
+		Where(cond, tweetID).
+		Where("t.deleted_at IS NULL").
+		Scopes(visibleTweets(viewerID))
+	if beforeID > 0 {
+		query = query.Where("t.id < ?", beforeID)
+	}
+
+	var tweets []*entity.Tweet
+	if err := query.Order("t.id DESC").Limit(limit).Find(&tweets).Error; err != nil {
+		return nil, err
+	}
+	return tweets, nil
+}
@@ -4,9 +4,21 @@ import (
 	"TwClone/internal/database"
 	"TwClone/internal/entity"
 	"context"
+	"errors"
 	"strings"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+// ErrMediaUnavailable is returned when media attached to a new tweet does not
+// exist, belongs to someone else or is already attached to another tweet.
+var ErrMediaUnavailable = errors.New("media unavailable")
+
+// ErrTweetChanged is returned when a tweet being edited was edited or
+// deleted in the meantime.
+var ErrTweetChanged = errors.New("tweet changed")
+
 type TweetRepositoryImpl struct{}
 
 func (r TweetRepositoryImpl) Create(ctx context.Context, tweet *entity.Tweet) error {
@@ -30,3 +42,147 @@ func (r TweetRepositoryImpl) FindAll(ctx context.Context) ([]*entity.Tweet, erro
 	}
 	return tweets, nil
 }
+
+// FindByID finds a tweet by id. Tombstones of deleted tweets are not found.
+func (r TweetRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Tweet, error) {
+	var tweet entity.Tweet
+	result := database.DB.WithContext(ctx).Where("deleted_at IS NULL").First(&tweet, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrRecordNotFound
+		}
+		return nil, result.Error
+	}
+	return &tweet, nil
+}
+
+// FindByIDs finds the tweets with the given ids. Missing and deleted tweets
+// are skipped and the order of the result is unspecified.
+func (r TweetRepositoryImpl) FindByIDs(ctx context.Context, ids []int64) ([]*entity.Tweet, error) {
+	var tweets []*entity.Tweet
+	if len(ids) == 0 {
+		return tweets, nil
+	}
+	result := database.DB.WithContext(ctx).Where("id IN ? AND deleted_at IS NULL", ids).Find(&tweets)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return tweets, nil
+}
+
+// FindPublicIDsByHashtag returns the ids of up to limit of the latest tweets
+// with a hashtag posted since the given time by users who are not
+// protected.
+func (r TweetRepositoryImpl) FindPublicIDsByHashtag(ctx context.Context, hashtagID int64, since time.Time, limit int) ([]int64, error) {
+	var ids []int64
+	result := database.DB.WithContext(ctx).
+		Table("tweets t").
+		Joins("JOIN tweet_hashtags th ON th.tweet_id = t.id").
+		Joins("JOIN users u ON u.id = t.user_id").
+		Where("th.hashtag_id = ? AND t.created_at >= ? AND NOT u.protected", hashtagID, since).
+		Order("t.id DESC").
+		Limit(limit).
+		Pluck("t.id", &ids)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return ids, nil
+}
+
+// LatestID returns the id of the newest tweet, or zero when there are none.
+func (r TweetRepositoryImpl) LatestID(ctx context.Context) (int64, error) {
+	var id int64
+	result := database.DB.WithContext(ctx).Model(&entity.Tweet{}).Select("COALESCE(MAX(id), 0)").Scan(&id)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return id, nil
+}
+
+// FindPage returns up to limit tweets that viewerID may see, newest first.
+// When beforeID is non-zero only tweets older than it are returned.
+func (r TweetRepositoryImpl) FindPage(ctx context.Context, viewerID, beforeID int64, limit int) ([]*entity.Tweet, error) {
+	var tweets []*entity.Tweet
+	query := database.DB.WithContext(ctx).
+		Table("tweets t").
+		Select("t.*").
+		Where("t.deleted_at IS NULL").
+		Scopes(visibleTweets(viewerID))
+	if beforeID > 0 {
+		query = query.Where("t.id < ?", beforeID)
+	}
+	result := query.Order("t.id DESC").Limit(limit).Find(&tweets)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return tweets, nil
+}
+
+// Delete removes a tweet together with its media, poll, hashtags, mentions,
+// likes, bookmarks, previous versions and retweets, and unpins it. The
+// media's blobs are released and left for the garbage collector to delete.
+// A tweet with replies is kept as an empty tombstone so that its thread
+// stays connected.
+func (r TweetRepositoryImpl) Delete(ctx context.Context, id int64) error {
+	return database.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// uncount the tweet and its retweets while they are still there
+		if err := tx.Exec(`
+			UPDATE users SET tweets_count = tweets_count - t.n
+			FROM (
+				SELECT user_id, COUNT(*) AS n FROM tweets
+				WHERE (id = ? OR retweeted_tweet_id = ?) AND deleted_at IS NULL
+				GROUP BY user_id
+			) t
+			WHERE users.id = t.user_id`, id, id).Error; err != nil {
+			return err
+		}
+		if err := deleteMedia(tx, tx.Where("tweet_id = ?", id)); err != nil {
+			return err
+		}
+		polls := tx.Model(&entity.Poll{}).Select("id").Where("tweet_id = ?", id)
+		for _, model := range []any{&entity.PollVote{}, &entity.PollOption{}} {
+			if err := tx.Where("poll_id IN (?)", polls).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Where("tweet_id = ?", id).Delete(&entity.Poll{}).Error; err != nil {
+			return err
+		}
+		retweets := tx.Model(&entity.Tweet{}).Select("id").Where("retweeted_tweet_id = ?", id)
+		if err := tx.Where("tweet_id IN (?)", retweets).Delete(&entity.Like{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("retweeted_tweet_id = ?", id).Delete(&entity.Tweet{}).Error; err != nil {
+			return err
+		}
+		for _, model := range []any{&entity.TweetHashtag{}, &entity.Mention{}, &entity.Like{}, &entity.Bookmark{}, &entity.TweetVersion{}} {
+			if err := tx.Where("tweet_id = ?", id).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&entity.User{}).Where("pinned_tweet_id = ?", id).Update("pinned_tweet_id", nil).Error; err != nil {
+			return err
+		}
+
+		var replies int64
+		if err := tx.Model(&entity.Tweet{}).Where("reply_to_tweet_id = ?", id).Count(&replies).Error; err != nil {
+			return err
+		}
+		if replies == 0 {
+			return tx.Delete(&entity.Tweet{}, id).Error
+		}
+		return tx.Model(&entity.Tweet{}).Where("id = ?", id).
+			Updates(map[string]any{"content": "", "deleted_at": time.Now()}).Error
+	})
+}
+
+// FindVersions returns the previous versions of a tweet, oldest first.
+func (r TweetRepositoryImpl) FindVersions(ctx context.Context, tweetID int64) ([]*entity.TweetVersion, error) {
+	var versions []*entity.TweetVersion
+	result := database.DB.WithContext(ctx).Where("tweet_id = ?", tweetID).Order("version").Find(&versions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return versions, nil
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+	"TwClone/internal/pkg/utils/searchutils"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TweetSearchOptions controls the order and page of a tweet search. Results
+// sorted by relevance are paged by Offset, those sorted by recency by
+// BeforeID like every other tweet listing. AfterID and ExcludeViewer narrow
+// the search down to new tweets by others, as search alerts need.
+type TweetSearchOptions struct {
+	ViewerID      int64
+	ByRelevance   bool
+	BeforeID      int64
+	AfterID       int64
+	ExcludeViewer bool
+	Offset        int
+	Limit         int
+}
+
+// Search finds the tweets matching q that the viewer may see. Retweets are
+// left out; the original is found instead.
+func (r TweetRepositoryImpl) Search(ctx context.Context, q *searchutils.Query, opts TweetSearchOptions) ([]*entity.Tweet, error) {
+	query := database.DB.WithContext(ctx).
+		Table("tweets t").
+		Select("t.*").
+		Where("t.deleted_at IS NULL AND t.retweeted_tweet_id IS NULL").
+		Scopes(visibleTweets(opts.ViewerID))
+
+	if opts.AfterID > 0 {
+		query = query.Where("t.id > ?", opts.AfterID)
+	}
+	if opts.ExcludeViewer {
+		query = query.Where("t.user_id <> ?", opts.ViewerID)
+	}
+	if q.Text != "" {
+		query = query.Where("t.search_vector @@ websearch_to_tsquery('simple', ?)", q.Text)
+	}
+	if len(q.From) > 0 {
+		query = query.Where("t.user_id IN (SELECT id FROM users WHERE LOWER(username) IN ?)", q.From)
+	}
+	if len(q.NotFrom) > 0 {
+		query = query.Where("t.user_id NOT IN (SELECT id FROM users WHERE LOWER(username) IN ?)", q.NotFrom)
+	}
+	if len(q.To) > 0 {
+		query = query.Where("t.reply_to_tweet_id IN (SELECT p.id FROM tweets p JOIN users u ON u.id = p.user_id WHERE LOWER(u.username) IN ?)", q.To)
+	}
+	if len(q.NotTo) > 0 {
+		query = query.Where("t.reply_to_tweet_id IS NULL OR t.reply_to_tweet_id NOT IN (SELECT p.id FROM tweets p JOIN users u ON u.id = p.user_id WHERE LOWER(u.username) IN ?)", q.NotTo)
+	}
+	for _, tag := range q.Hashtags {
+		query = query.Where("EXISTS (SELECT 1 FROM tweet_hashtags th JOIN hashtags h ON h.id = th.hashtag_id WHERE th.tweet_id = t.id AND h.tag_name = ?)", tag)
+	}
+	if len(q.NotHashtags) > 0 {
+		query = query.Where("NOT EXISTS (SELECT 1 FROM tweet_hashtags th JOIN hashtags h ON h.id = th.hashtag_id WHERE th.tweet_id = t.id AND h.tag_name IN ?)", q.NotHashtags)
+	}
+	if q.Since != nil {
+		query = query.Where("t.created_at >= ?", *q.Since)
+	}
+	if q.Until != nil {
+		query = query.Where("t.created_at < ?", *q.Until)
+	}
+	if q.HasMedia != nil {
+		if *q.HasMedia {
+			query = query.Where("EXISTS (SELECT 1 FROM media m WHERE m.tweet_id = t.id)")
+		} else {
+			query = query.Where("NOT EXISTS (SELECT 1 FROM media m WHERE m.tweet_id = t.id)")
+		}
+	}
+	if q.Replies != nil {
+		if *q.Replies {
+			query = query.Where("t.reply_to_tweet_id IS NOT NULL")
+		} else {
+			query = query.Where("t.reply_to_tweet_id IS NULL")
+		}
+	}
+	if q.MinLikes > 0 {
+		query = query.Where("(SELECT COUNT(*) FROM likes l WHERE l.tweet_id = t.id) >= ?", q.MinLikes)
+	}
+
+	if opts.ByRelevance && q.Text != "" {
+		query = query.Order(clause.OrderBy{Expression: clause.Expr{
+			SQL:  "ts_rank(t.search_vector, websearch_to_tsquery('simple', ?)) DESC, t.id DESC",
+			Vars: []any{q.Text},
+		}}).Offset(opts.Offset)
+	} else if opts.ByRelevance {
+		query = query.Order("t.id DESC").Offset(opts.Offset)
+	} else {
+		if opts.BeforeID > 0 {
+			query = query.Where("t.id < ?", opts.BeforeID)
+		}
+		query = query.Order("t.id DESC")
+	}
+
+	var tweets []*entity.Tweet
+	if err := query.Limit(opts.Limit).Find(&tweets).Error; err != nil {
+		return nil, err
+	}
+	return tweets, nil
+}
+
+// visibleTweets limits a query on "tweets t" to what viewerID may see:
+// tweets of protected users only for their followers and themselves, and
+// nothing from users the viewer blocked or muted or who blocked the viewer.
+// A zero viewerID is an anonymous viewer.
+func visibleTweets(viewerID int64) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		db = db.Where("NOT EXISTS (SELECT 1 FROM users u WHERE u.id = t.user_id AND u.protected) OR t.user_id = ? OR EXISTS (SELECT 1 FROM follows f WHERE f.follower_id = ? AND f.following_id = t.user_id)", viewerID, viewerID)
+		if viewerID == 0 {
+			return db
+		}
+		return db.
+			Where("NOT EXISTS (SELECT 1 FROM blocks b WHERE (b.blocker_id = ? AND b.blocked_id = t.user_id) OR (b.blocker_id = t.user_id AND b.blocked_id = ?))", viewerID, viewerID).
+			Where("NOT EXISTS (SELECT 1 FROM mutes mu WHERE mu.muter_id = ? AND mu.muted_id = t.user_id)", viewerID)
+	}
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"context"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+)
+
+// FindAncestors returns the tweets a tweet replies to, walking up from its
+// parent, root first. Tombstones are included. The walk stops after limit
+// tweets or at a parent that no longer exists at all.
+func (r TweetRepositoryImpl) FindAncestors(ctx context.Context, tweet *entity.Tweet, limit int) ([]*entity.Tweet, error) {
+	var tweets []*entity.Tweet
+	if tweet.ReplyToTweetID == nil {
+		return tweets, nil
+	}
+
+	result := database.DB.WithContext(ctx).Raw(`
+		WITH RECURSIVE up AS (
+			SELECT t.*, 1 AS depth FROM tweets t WHERE t.id = ?
+			UNION ALL
+			SELECT t.*, up.depth + 1 FROM tweets t JOIN up ON t.id = up.reply_to_tweet_id WHERE up.depth < ?
+		)
+		SELECT * FROM up ORDER BY depth DESC`, *tweet.ReplyToTweetID, limit).
+		Scan(&tweets)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return tweets, nil
+}
+
+// FindReplies returns, for each of the given tweets, its first perParent
+// direct replies with an id above afterID, oldest first. Tombstones are
+// included. One reply more than perParent is returned for a tweet that has
+// more, so callers can tell whether a page is the last one.
+func (r TweetRepositoryImpl) FindReplies(ctx context.Context, parentIDs []int64, afterID int64, perParent int) ([]*entity.Tweet, error) {
+	var tweets []*entity.Tweet
+	if len(parentIDs) == 0 {
+		return tweets, nil
+	}
+
+	result := database.DB.WithContext(ctx).Raw(`
+		SELECT * FROM (
+			SELECT t.*, ROW_NUMBER() OVER (PARTITION BY t.reply_to_tweet_id ORDER BY t.id) AS rn
+			FROM tweets t WHERE t.reply_to_tweet_id IN ? AND t.id > ?
+		) r WHERE r.rn <= ? ORDER BY r.reply_to_tweet_id, r.id`, parentIDs, afterID, perParent+1).
+		Scan(&tweets)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return tweets, nil
+}
+
+// CountReplies counts the direct replies, tombstones included, of each of
+// the given tweets.
+func (r TweetRepositoryImpl) CountReplies(ctx context.Context, ids []int64) (map[int64]int64, error) {
+	counts := make(map[int64]int64, len(ids))
+	if len(ids) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		ReplyToTweetID int64
+		Replies        int64
+	}
+	result := database.DB.WithContext(ctx).
+		Model(&entity.Tweet{}).
+		Select("reply_to_tweet_id, COUNT(*) AS replies").
+		Where("reply_to_tweet_id IN ?", ids).
+		Group("reply_to_tweet_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for _, row := range rows {
+		counts[row.ReplyToTweetID] = row.Replies
+	}
+	return counts, nil
+}
+
+// FindVisibleIDs returns which of the given tweets viewerID may see, as
+// decided for search: protected authors only for their followers, nothing
+// from blocked or muted users.
+func (r TweetRepositoryImpl) FindVisibleIDs(ctx context.Context, ids []int64, viewerID int64) (map[int64]bool, error) {
+	visible := make(map[int64]bool, len(ids))
+	if len(ids) == 0 {
+		return visible, nil
+	}
+
+	var found []int64
+	result := database.DB.WithContext(ctx).
+		Table("tweets t").
+		Where("t.id IN ?", ids).
+		Scopes(visibleTweets(viewerID)).
+		Pluck("t.id", &found)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for _, id := range found {
+		visible[id] = true
+	}
+	return visible, nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+
+	"TwClone/internal/database"
+)
+
+// Relationship describes how a viewer relates to another user.
+type Relationship struct {
+	UserID               int64
+	FollowedBy           bool
+	Following            bool
+	Blocking             bool
+	Muting               bool
+	FollowRequestPending bool
+}
+
+// FindRelationships returns how the viewer relates to each of the given
+// users, by user id. Unknown users are left out.
+func (r UserRepositoryImpl) FindRelationships(ctx context.Context, viewerID int64, userIDs []int64) (map[int64]*Relationship, error) {
+	byUser := make(map[int64]*Relationship, len(userIDs))
+	if len(userIDs) == 0 {
+		return byUser, nil
+	}
+
+	var rels []*Relationship
+	result := database.DB.WithContext(ctx).Raw(`
+		SELECT u.id AS user_id,
+			EXISTS (SELECT 1 FROM follows f WHERE f.follower_id = u.id AND f.following_id = ?) AS followed_by,
+			EXISTS (SELECT 1 FROM follows f WHERE f.follower_id = ? AND f.following_id = u.id) AS following,
+			EXISTS (SELECT 1 FROM blocks b WHERE b.blocker_id = ? AND b.blocked_id = u.id) AS blocking,
+			EXISTS (SELECT 1 FROM mutes m WHERE m.muter_id = ? AND m.muted_id = u.id) AS muting,
+			EXISTS (SELECT 1 FROM follow_requests fr WHERE fr.requester_id = ? AND fr.target_id = u.id) AS follow_request_pending
+		FROM users u
+		WHERE u.id IN ?`,
+		viewerID, viewerID, viewerID, viewerID, viewerID, userIDs).
+		Scan(&rels)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	for _, rel := range rels {
+		byUser[rel.UserID] = rel
+	}
+	return byUser, nil
+}
@@ -85,7 +85,49 @@ func (r UserRepositoryImpl) FindByID(ctx context.Context, id int64) (*entity.Use
 	return &user, nil
 }
 
-// Update modifies an existing user.
+// Update modifies an existing user. The pinned tweet and the counts are
+// maintained separately and left as they are.
 func (r UserRepositoryImpl) Update(ctx context.Context, user *entity.User) error {
-	return database.DB.WithContext(ctx).Save(user).Error
+	return database.DB.WithContext(ctx).
+		Omit("pinned_tweet_id", "followers_count", "following_count", "tweets_count").
+		Save(user).Error
+}
+
+// FindByIDs finds the users with the given ids.
+func (r UserRepositoryImpl) FindByIDs(ctx context.Context, ids []int64) ([]*entity.User, error) {
+	var users []*entity.User
+	result := database.DB.WithContext(ctx).Where("id IN ?", ids).Find(&users)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return users, nil
+}
+
+// PinTweet pins a tweet to the top of a user's profile, replacing any
+// previously pinned tweet.
+func (r UserRepositoryImpl) PinTweet(ctx context.Context, userID, tweetID int64) error {
+	return database.DB.WithContext(ctx).Model(&entity.User{}).
+		Where("id = ?", userID).
+		Update("pinned_tweet_id", tweetID).Error
+}
+
+// UnpinTweet unpins a tweet from a user's profile. It returns
+// ErrRecordNotFound when the tweet is not the one pinned.
+func (r UserRepositoryImpl) UnpinTweet(ctx context.Context, userID, tweetID int64) error {
+	result := database.DB.WithContext(ctx).Model(&entity.User{}).
+		Where("id = ? AND pinned_tweet_id = ?", userID, tweetID).
+		Update("pinned_tweet_id", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrRecordNotFound
+	}
+	return nil
+}
+
+// ReconcileCounts repairs drift in the maintained follower, following and
+// tweet counts, and returns how many users were off.
+func (r UserRepositoryImpl) ReconcileCounts(ctx context.Context) (int64, error) {
+	return database.ReconcileUserCounts(database.DB.WithContext(ctx))
 }
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"strings"
+
+	"TwClone/internal/database"
+	"TwClone/internal/entity"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UserSearchResult is a user found by a search together with how they
+// relate to the viewer.
+type UserSearchResult struct {
+	entity.User
+	Following   bool
+	MutualCount int64
+}
+
+// Search finds users whose username or name starts with or resembles query,
+// using trigram similarity for the fuzzy part. Exact username matches come
+// first, then users ranked by match quality, whether the viewer follows them,
+// how many people the viewer follows follow them, and their follower count.
+// Users blocking or blocked by the viewer are left out.
+func (r UserRepositoryImpl) Search(ctx context.Context, query string, viewerID int64, offset, limit int) ([]*UserSearchResult, error) {
+	q := strings.ToLower(query)
+	prefix := escapeLike(q) + "%"
+
+	db := database.DB.WithContext(ctx)
+	candidates := db.
+		Table("users u").
+		Select(`u.*,
+			EXISTS (SELECT 1 FROM follows f WHERE f.follower_id = ? AND f.following_id = u.id) AS following,
+			(SELECT COUNT(*) FROM follows f JOIN follows vf ON vf.following_id = f.follower_id AND vf.follower_id = ?
+				WHERE f.following_id = u.id) AS mutual_count`, viewerID, viewerID).
+		Where("LOWER(u.username) LIKE ? OR LOWER(u.name) LIKE ? OR LOWER(u.username) % ? OR LOWER(u.name) % ?", prefix, prefix, q, q).
+		Scopes(searchableUsers(viewerID))
+
+	// ranking needs the computed columns, which ORDER BY expressions can
+	// only see from an outer query
+	var results []*UserSearchResult
+	result := db.
+		Table("(?) AS c", candidates).
+		Order(clause.OrderBy{Expression: clause.Expr{
+			SQL: `LOWER(c.username) = ? DESC,
+				CASE WHEN LOWER(c.username) LIKE ? OR LOWER(c.name) LIKE ? THEN 1
+					ELSE GREATEST(similarity(LOWER(c.username), ?), similarity(LOWER(c.name), ?)) END
+				+ CASE WHEN c.following THEN 1 ELSE 0 END
+				+ 0.5 * LN(1 + c.mutual_count)
+				+ 0.25 * LN(1 + c.followers_count) DESC,
+				c.id`,
+			Vars: []any{q, prefix, prefix, q, q},
+		}}).
+		Offset(offset).
+		Limit(limit).
+		Scan(&results)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return results, nil
+}
+
+// Typeahead returns the users whose username or name starts with prefix,
+// for @mention autocomplete. It only uses the prefix indexes and skips the
+// costlier mutual count so that it stays fast on every keystroke: people
+// the viewer follows come first, then the most followed.
+func (r UserRepositoryImpl) Typeahead(ctx context.Context, prefix string, viewerID int64, limit int) ([]*UserSearchResult, error) {
+	pattern := escapeLike(strings.ToLower(prefix)) + "%"
+
+	var results []*UserSearchResult
+	result := database.DB.WithContext(ctx).
+		Table("users u").
+		Select(`u.*,
+			EXISTS (SELECT 1 FROM follows f WHERE f.follower_id = ? AND f.following_id = u.id) AS following`, viewerID).
+		Where("LOWER(u.username) LIKE ? OR LOWER(u.name) LIKE ?", pattern, pattern).
+		Scopes(searchableUsers(viewerID)).
+		Order("following DESC, u.followers_count DESC, u.username").
+		Limit(limit).
+		Scan(&results)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return results, nil
+}
+
+// searchableUsers limits a query on "users u" to users other than the viewer
+// that neither block nor are blocked by them.
+func searchableUsers(viewerID int64) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.
+			Where("u.id <> ?", viewerID).
+			Where("NOT EXISTS (SELECT 1 FROM blocks b WHERE (b.blocker_id = ? AND b.blocked_id = u.id) OR (b.blocker_id = u.id AND b.blocked_id = ?))", viewerID, viewerID)
+	}
+}
+
+// escapeLike escapes the LIKE wildcards in s so that it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
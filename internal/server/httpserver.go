@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"errors"
+	"expvar"
 	"fmt"
 	"net/http"
 	"time"
@@ -22,6 +23,10 @@ import (
 type HttpServer struct {
 	cfg    *config.Config
 	server *http.Server
+	// debug serves runtime and job counters (media gc, ...) in expvar's
+	// JSON format on an internal address, away from the public API. It is
+	// nil when no debug address is configured.
+	debug *http.Server
 }
 
 type EchoValidator struct {
@@ -45,16 +50,30 @@ func NewHttpServer(cfg *config.Config) *HttpServer {
 
 	provider.BootstrapHttp(cfg, router)
 
-	return &HttpServer{
+	srv := &HttpServer{
 		cfg: cfg,
 		server: &http.Server{
 			Addr:    fmt.Sprintf("%s:%d", cfg.HttpServer.Host, cfg.HttpServer.Port),
 			Handler: router,
 		},
 	}
+	if cfg.HttpServer.DebugAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/debug/vars", expvar.Handler())
+		srv.debug = &http.Server{Addr: cfg.HttpServer.DebugAddr, Handler: mux}
+	}
+	return srv
 }
 
 func (s *HttpServer) Start() {
+	if s.debug != nil {
+		go func() {
+			logger.Log.Info("Running debug HTTP server on:", s.debug.Addr)
+			if err := s.debug.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Log.Error("Error while debug HTTP server listening:", err)
+			}
+		}()
+	}
 	logger.Log.Info("Running HTTP server on port:", s.cfg.HttpServer.Port)
 	if err := s.server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		logger.Log.Fatal("Error while HTTP server listening:", err)
@@ -66,6 +85,9 @@ func (s *HttpServer) Shutdown() {
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.cfg.HttpServer.GracePeriod)*time.Second)
 	defer cancel()
 
+	if s.debug != nil {
+		_ = s.debug.Shutdown(ctx)
+	}
 	logger.Log.Info("Attempting to shut down the HTTP server...")
 	if err := s.server.Shutdown(ctx); err != nil {
 		logger.Log.Fatal("Error shutting down HTTP server:", err)
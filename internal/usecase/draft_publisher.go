@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"expvar"
+	"time"
+
+	"TwClone/internal/config"
+	"TwClone/internal/entity"
+	"TwClone/internal/pkg/logger"
+	"TwClone/internal/repository"
+)
+
+var (
+	scheduledTweetsPublished = expvar.NewInt("scheduled_tweets_published")
+	scheduledTweetsFailed    = expvar.NewInt("scheduled_tweets_failed")
+)
+
+// DraftPublisher publishes scheduled tweets once they are due. Each tweet is
+// claimed with a row lock that other publishers skip and is published in the
+// same transaction that removes its draft, so any number of workers can run
+// side by side and every tweet is published exactly once. A tweet that can
+// no longer be published is returned to its author's drafts with the
+// reason, and the author is notified.
+type DraftPublisher struct {
+	store     repository.DataStore
+	tweetRepo repository.TweetRepositoryImpl
+	interval  time.Duration
+}
+
+func NewDraftPublisher(store repository.DataStore, cfg *config.DraftConfig) *DraftPublisher {
+	return &DraftPublisher{
+		store:     store,
+		tweetRepo: repository.TweetRepositoryImpl{},
+		interval:  time.Duration(cfg.PublishIntervalSeconds) * time.Second,
+	}
+}
+
+// Run publishes due tweets periodically until ctx is cancelled.
+func (p *DraftPublisher) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		if err := p.PublishDue(ctx); err != nil && ctx.Err() == nil {
+			logger.Log.Errorf("failed to publish scheduled tweets: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PublishDue publishes every scheduled tweet that is due.
+func (p *DraftPublisher) PublishDue(ctx context.Context) error {
+	for ctx.Err() == nil {
+		claimed := false
+		err := p.store.Atomic(ctx, func(s repository.DataStore) error {
+			draft, err := s.ClaimDueDraft(ctx, time.Now())
+			if err != nil {
+				if errors.Is(err, repository.ErrRecordNotFound) {
+					return nil
+				}
+				return err
+			}
+			claimed = true
+			return p.publish(ctx, s, draft)
+		})
+		if err != nil || !claimed {
+			return err
+		}
+	}
+	return ctx.Err()
+}
+
+// publish turns a claimed draft into a tweet, or records why it cannot be.
+func (p *DraftPublisher) publish(ctx context.Context, s repository.DataStore, draft *entity.Draft) error {
+	tweet, reason, err := p.tweetFor(ctx, draft)
+	if err != nil {
+		return err
+	}
+	if reason == "" {
+		err := s.CreateTweet(ctx, tweet, draft.MediaIDs)
+		if err == nil {
+			err = LinkTweetEntities(ctx, s, tweet)
+		}
+		if errors.Is(err, repository.ErrMediaUnavailable) {
+			reason = "its media is no longer available"
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if reason != "" {
+		scheduledTweetsFailed.Add(1)
+		logger.Log.Infof("scheduled tweet %d of user %d not published: %s", draft.ID, draft.UserID, reason)
+		return s.FailDraft(ctx, draft, reason)
+	}
+	scheduledTweetsPublished.Add(1)
+	return s.DeleteDraft(ctx, draft.ID)
+}
+
+// tweetFor builds the tweet a draft publishes. When the draft can no longer
+// be published the reason is returned instead.
+func (p *DraftPublisher) tweetFor(ctx context.Context, draft *entity.Draft) (*entity.Tweet, string, error) {
+	if draft.Content == "" && len(draft.MediaIDs) == 0 {
+		return nil, "the tweet has no content or media", nil
+	}
+
+	tweet := &entity.Tweet{UserID: draft.UserID, Content: draft.Content}
+	if draft.ReplyToTweetID != nil {
+		parent, err := p.tweetRepo.FindVisibleOriginal(ctx, *draft.ReplyToTweetID, draft.UserID)
+		if err != nil {
+			if errors.Is(err, repository.ErrRecordNotFound) {
+				return nil, "the tweet it replies to is no longer available", nil
+			}
+			return nil, "", err
+		}
+		tweet.ReplyToTweetID = &parent.ID
+		tweet.ConversationID = parent.ConversationID
+	}
+	if draft.QuotedTweetID != nil {
+		quoted, err := p.tweetRepo.FindVisibleOriginal(ctx, *draft.QuotedTweetID, draft.UserID)
+		if err != nil {
+			if errors.Is(err, repository.ErrRecordNotFound) {
+				return nil, "the quoted tweet is no longer available", nil
+			}
+			return nil, "", err
+		}
+		tweet.QuotedTweetID = &quoted.ID
+	}
+	return tweet, "", nil
+}
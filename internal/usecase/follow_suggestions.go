@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"TwClone/internal/config"
+	"TwClone/internal/entity"
+	"TwClone/internal/pkg/logger"
+	"TwClone/internal/repository"
+)
+
+const (
+	suggestionUserBatchSize = 100
+	// suggestionCandidateFactor is how many candidates per kept suggestion
+	// are scored, the best connected first.
+	suggestionCandidateFactor = 10
+
+	suggestionMutualWeight   = 1.0
+	suggestionHashtagWeight  = 0.5
+	suggestionActivityWeight = 0.5
+
+	// suggestionWarmTimeout bounds the on-demand computation of a user's
+	// suggestions.
+	suggestionWarmTimeout = 30 * time.Second
+)
+
+// FollowSuggester precomputes who-to-follow suggestions. Candidates are the
+// accounts followed by the accounts a user follows. Each is scored by how
+// many of those follow it, how many hashtags it shares with the user and
+// how recently it tweeted, with diminishing returns on the first two so
+// that a single signal cannot dominate.
+type FollowSuggester struct {
+	cfg  *config.SuggestionsConfig
+	repo repository.SuggestionRepositoryImpl
+	// warmed holds the users whose suggestions were computed on demand.
+	warmed sync.Map
+}
+
+func NewFollowSuggester(cfg *config.SuggestionsConfig) *FollowSuggester {
+	return &FollowSuggester{
+		cfg:  cfg,
+		repo: repository.SuggestionRepositoryImpl{},
+	}
+}
+
+// Run recomputes everyone's suggestions periodically until ctx is
+// cancelled.
+func (s *FollowSuggester) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.cfg.IntervalMinutes) * time.Minute)
+	defer ticker.Stop()
+	for {
+		if err := s.RefreshAll(ctx); err != nil && ctx.Err() == nil {
+			logger.Log.Errorf("failed to compute follow suggestions: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RefreshAll recomputes the suggestions of every user who follows someone.
+func (s *FollowSuggester) RefreshAll(ctx context.Context) error {
+	var afterID int64
+	for {
+		ids, err := s.repo.FindUserIDsAfter(ctx, afterID, suggestionUserBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			if err := s.Refresh(ctx, id); err != nil {
+				return err
+			}
+		}
+		if len(ids) < suggestionUserBatchSize {
+			return nil
+		}
+		afterID = ids[len(ids)-1]
+	}
+}
+
+// Warm computes the suggestions of a user in the background, for users who
+// have none yet because they are new. It only does so once per user; later
+// changes are picked up by Run.
+func (s *FollowSuggester) Warm(userID int64) {
+	if _, done := s.warmed.LoadOrStore(userID, struct{}{}); done {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), suggestionWarmTimeout)
+		defer cancel()
+		if err := s.Refresh(ctx, userID); err != nil {
+			// let a later request try again
+			s.warmed.Delete(userID)
+			logger.Log.Errorf("failed to compute follow suggestions of user %d: %v", userID, err)
+		}
+	}()
+}
+
+// Refresh recomputes the suggestions of a user.
+func (s *FollowSuggester) Refresh(ctx context.Context, userID int64) error {
+	now := time.Now()
+	interestsSince := now.AddDate(0, 0, -s.cfg.InterestDays)
+	candidates, err := s.repo.FindCandidates(ctx, userID, interestsSince, s.cfg.PerUser*suggestionCandidateFactor)
+	if err != nil {
+		return err
+	}
+
+	suggestions := make([]*entity.Suggestion, 0, len(candidates))
+	for _, c := range candidates {
+		suggestions = append(suggestions, &entity.Suggestion{
+			UserID:         userID,
+			SuggestedID:    c.SuggestedID,
+			Score:          s.score(c, now),
+			MutualCount:    c.MutualCount,
+			SharedHashtags: c.SharedHashtags,
+		})
+	}
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Score > suggestions[j].Score
+	})
+	if len(suggestions) > s.cfg.PerUser {
+		suggestions = suggestions[:s.cfg.PerUser]
+	}
+	return s.repo.Replace(ctx, userID, suggestions)
+}
+
+// score weighs a candidate. Activity falls linearly from 1 for an account
+// that just tweeted to 0 for one that has not tweeted for ActivityDays.
+func (s *FollowSuggester) score(c *repository.SuggestionCandidate, now time.Time) float64 {
+	activity := 0.0
+	if c.LastTweetAt != nil {
+		window := time.Duration(s.cfg.ActivityDays) * 24 * time.Hour
+		activity = math.Max(0, 1-float64(now.Sub(*c.LastTweetAt))/float64(window))
+	}
+	return suggestionMutualWeight*math.Log1p(float64(c.MutualCount)) +
+		suggestionHashtagWeight*math.Log1p(float64(c.SharedHashtags)) +
+		suggestionActivityWeight*activity
+}
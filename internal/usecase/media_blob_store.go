@@ -0,0 +1,77 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+
+	"TwClone/internal/entity"
+	"TwClone/internal/pkg/storage"
+	"TwClone/internal/repository"
+)
+
+// MediaBlobStore stores media files by the SHA-256 of their content so that
+// identical files are kept once. Saving a blob does not reference it; the
+// media or variant pointing at it takes the reference when it is saved.
+type MediaBlobStore struct {
+	store storage.Storage
+	repo  repository.MediaBlobRepositoryImpl
+}
+
+func NewMediaBlobStore(store storage.Storage) *MediaBlobStore {
+	return &MediaBlobStore{store: store, repo: repository.MediaBlobRepositoryImpl{}}
+}
+
+// Save stores body unless a blob with the same content already exists.
+func (s *MediaBlobStore) Save(ctx context.Context, body io.ReadSeeker, size int64, mimeType string) (*entity.MediaBlob, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, body); err != nil {
+		return nil, err
+	}
+	if _, err := body.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	hash := hex.EncodeToString(h.Sum(nil))
+
+	// touching first protects an existing blob from the garbage collector
+	// for another grace period before it gets referenced
+	exists, err := s.repo.Touch(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return s.repo.FindByHash(ctx, hash)
+	}
+
+	blob := &entity.MediaBlob{
+		Hash:       hash,
+		StorageKey: blobKey(hash),
+		MimeType:   mimeType,
+		Size:       size,
+	}
+	if err := s.store.Put(ctx, blob.StorageKey, body, size, mimeType); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Ensure(ctx, blob); err != nil {
+		return nil, err
+	}
+	return blob, nil
+}
+
+// SaveBytes is Save for content already held in memory.
+func (s *MediaBlobStore) SaveBytes(ctx context.Context, data []byte, mimeType string) (*entity.MediaBlob, error) {
+	return s.Save(ctx, bytes.NewReader(data), int64(len(data)), mimeType)
+}
+
+// URL returns the storage URL of a blob.
+func (s *MediaBlobStore) URL(blob *entity.MediaBlob) string {
+	return s.store.URL(blob.StorageKey)
+}
+
+// blobKey spreads blobs over directories named after the first two hex
+// digits of their hash.
+func blobKey(hash string) string {
+	return "blobs/" + hash[:2] + "/" + hash
+}
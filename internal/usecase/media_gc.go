@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"expvar"
+	"time"
+
+	"TwClone/internal/config"
+	"TwClone/internal/pkg/logger"
+	"TwClone/internal/pkg/storage"
+	"TwClone/internal/repository"
+)
+
+const mediaGCBatchSize = 500
+
+var (
+	mediaGCRuns           = expvar.NewInt("media_gc_runs")
+	mediaGCDeletedMedia   = expvar.NewInt("media_gc_deleted_media")
+	mediaGCDeletedBlobs   = expvar.NewInt("media_gc_deleted_blobs")
+	mediaGCReclaimedBytes = expvar.NewInt("media_gc_reclaimed_bytes")
+)
+
+// MediaGCResult summarises one garbage collection pass.
+type MediaGCResult struct {
+	DeletedMedia   int
+	DeletedBlobs   int
+	ReclaimedBytes int64
+}
+
+// MediaGC removes uploads that were never attached to a tweet or a message
+// and deletes blobs no media references anymore, such as the files of
+// deleted tweets. Both only happen after a grace period.
+type MediaGC struct {
+	store    storage.Storage
+	repo     repository.MediaRepositoryImpl
+	blobRepo repository.MediaBlobRepositoryImpl
+	grace    time.Duration
+	interval time.Duration
+}
+
+func NewMediaGC(store storage.Storage, cfg *config.MediaConfig) *MediaGC {
+	return &MediaGC{
+		store:    store,
+		repo:     repository.MediaRepositoryImpl{},
+		blobRepo: repository.MediaBlobRepositoryImpl{},
+		grace:    time.Duration(cfg.GCGraceHours) * time.Hour,
+		interval: time.Duration(cfg.GCIntervalMin) * time.Minute,
+	}
+}
+
+// Run collects garbage periodically until ctx is cancelled.
+func (g *MediaGC) Run(ctx context.Context) {
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+	for {
+		if _, err := g.Collect(ctx); err != nil && ctx.Err() == nil {
+			logger.Log.Errorf("media gc failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Collect runs a single garbage collection pass.
+func (g *MediaGC) Collect(ctx context.Context) (MediaGCResult, error) {
+	var res MediaGCResult
+	cutoff := time.Now().Add(-g.grace)
+	defer func() {
+		mediaGCRuns.Add(1)
+		mediaGCDeletedMedia.Add(int64(res.DeletedMedia))
+		mediaGCDeletedBlobs.Add(int64(res.DeletedBlobs))
+		mediaGCReclaimedBytes.Add(res.ReclaimedBytes)
+		logger.Log.Infof("media gc: deleted %d orphaned media and %d blobs, reclaimed %d bytes",
+			res.DeletedMedia, res.DeletedBlobs, res.ReclaimedBytes)
+	}()
+
+	for {
+		n, err := g.repo.DeleteOrphans(ctx, cutoff, mediaGCBatchSize)
+		if err != nil {
+			return res, err
+		}
+		res.DeletedMedia += n
+		if n < mediaGCBatchSize {
+			break
+		}
+	}
+
+	// blobs released by the orphans above restart their grace period, so
+	// they are only collected by a later pass
+	for {
+		blobs, err := g.blobRepo.FindUnreferenced(ctx, cutoff, mediaGCBatchSize)
+		if err != nil {
+			return res, err
+		}
+
+		for _, blob := range blobs {
+			deleted, err := g.blobRepo.DeleteUnreferenced(ctx, blob.Hash, cutoff, func() error {
+				return g.store.Delete(ctx, blob.StorageKey)
+			})
+			if err != nil {
+				return res, err
+			}
+			if deleted {
+				res.DeletedBlobs++
+				res.ReclaimedBytes += blob.Size
+			}
+		}
+		if len(blobs) < mediaGCBatchSize {
+			return res, nil
+		}
+	}
+}
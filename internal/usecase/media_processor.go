@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"sync"
+	"time"
+
+	"TwClone/internal/entity"
+	"TwClone/internal/pkg/logger"
+	"TwClone/internal/pkg/utils/imageutils"
+	"TwClone/internal/repository"
+)
+
+const (
+	mediaQueueSize     = 1024
+	mediaSweepInterval = time.Minute
+	jpegQuality        = 85
+	blurhashSize       = 32
+	blurhashXComp      = 4
+	blurhashYComp      = 3
+)
+
+// mediaVariantSizes is the bounding box, in pixels, of each resized variant.
+var mediaVariantSizes = []struct {
+	name    string
+	maxSize int
+}{
+	{entity.MediaVariantThumbnail, 150},
+	{entity.MediaVariantSmall, 680},
+	{entity.MediaVariantLarge, 1200},
+}
+
+var (
+	errUnsupportedImage = errors.New("unsupported image format")
+	errImageTooLarge    = errors.New("image dimensions too large")
+)
+
+// MediaProcessor turns uploaded images into sanitised, resized variants in
+// the background. Uploads are queued with Enqueue; media left in processing
+// state (for instance by a restart) are picked up again by a periodic sweep.
+type MediaProcessor struct {
+	blobs     *MediaBlobStore
+	repo      repository.MediaRepositoryImpl
+	workers   int
+	maxPixels int
+	queue     chan int64
+
+	mu      sync.Mutex
+	pending map[int64]struct{}
+}
+
+// NewMediaProcessor creates a processor that rejects images of more than
+// maxPixels pixels before decoding them.
+func NewMediaProcessor(blobs *MediaBlobStore, workers, maxPixels int) *MediaProcessor {
+	return &MediaProcessor{
+		blobs:     blobs,
+		repo:      repository.MediaRepositoryImpl{},
+		workers:   max(1, workers),
+		maxPixels: maxPixels,
+		queue:     make(chan int64, mediaQueueSize),
+		pending:   make(map[int64]struct{}),
+	}
+}
+
+// Enqueue schedules media for processing. When the queue is full the media
+// stays in processing state and is retried by the next sweep.
+func (p *MediaProcessor) Enqueue(mediaID int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.pending[mediaID]; ok {
+		return
+	}
+	select {
+	case p.queue <- mediaID:
+		p.pending[mediaID] = struct{}{}
+	default:
+		logger.Log.Warnf("media queue full, media %d deferred to next sweep", mediaID)
+	}
+}
+
+// Run processes queued media until ctx is cancelled.
+func (p *MediaProcessor) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p.work(ctx)
+		}()
+	}
+
+	p.sweep(ctx)
+	ticker := time.NewTicker(mediaSweepInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+			p.sweep(ctx)
+		}
+	}
+}
+
+func (p *MediaProcessor) sweep(ctx context.Context) {
+	ids, err := p.repo.FindIDsByStatus(ctx, entity.MediaStatusProcessing)
+	if err != nil {
+		logger.Log.Errorf("failed to find unprocessed media: %v", err)
+		return
+	}
+	for _, id := range ids {
+		p.Enqueue(id)
+	}
+}
+
+func (p *MediaProcessor) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-p.queue:
+			if err := p.process(ctx, id); err != nil && ctx.Err() == nil {
+				logger.Log.Errorf("failed to process media %d: %v", id, err)
+				if err := p.repo.MarkFailed(ctx, id); err != nil {
+					logger.Log.Errorf("failed to mark media %d as failed: %v", id, err)
+				}
+			}
+
+			p.mu.Lock()
+			delete(p.pending, id)
+			p.mu.Unlock()
+		}
+	}
+}
+
+func (p *MediaProcessor) process(ctx context.Context, id int64) error {
+	media, err := p.repo.FindByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if media.Status != entity.MediaStatusProcessing {
+		return nil
+	}
+
+	rc, err := p.blobs.store.Get(ctx, media.StorageKey)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(rc)
+	rc.Close()
+	if err != nil {
+		return err
+	}
+
+	img, err := decodeImage(media.MimeType, data, p.maxPixels)
+	if err != nil {
+		return err
+	}
+	bounds := img.Bounds()
+	media.Width, media.Height = bounds.Dx(), bounds.Dy()
+
+	// Re-encoding drops EXIF, GPS and any other metadata of the original.
+	// GIFs carry no EXIF and are kept as uploaded so animations survive.
+	// The sanitised file is a new blob; the original's blob is released.
+	rawBlobHash := media.BlobHash
+	if media.MimeType != "image/gif" {
+		clean, err := encodeImage(media.MimeType, img)
+		if err != nil {
+			return err
+		}
+		blob, err := p.blobs.SaveBytes(ctx, clean, media.MimeType)
+		if err != nil {
+			return err
+		}
+		media.BlobHash = blob.Hash
+		media.StorageKey = blob.StorageKey
+		media.URL = p.blobs.URL(blob)
+		media.Size = blob.Size
+	}
+
+	variantType := media.MimeType
+	if variantType == "image/gif" {
+		variantType = "image/jpeg"
+	}
+	variants := make([]*entity.MediaVariant, 0, len(mediaVariantSizes))
+	for _, vs := range mediaVariantSizes {
+		w, h := imageutils.Fit(media.Width, media.Height, vs.maxSize)
+		out, err := encodeImage(variantType, imageutils.Resize(img, w, h))
+		if err != nil {
+			return err
+		}
+		blob, err := p.blobs.SaveBytes(ctx, out, variantType)
+		if err != nil {
+			return err
+		}
+		variants = append(variants, &entity.MediaVariant{
+			Name:       vs.name,
+			Width:      w,
+			Height:     h,
+			MimeType:   variantType,
+			Size:       blob.Size,
+			BlobHash:   blob.Hash,
+			StorageKey: blob.StorageKey,
+			URL:        p.blobs.URL(blob),
+		})
+	}
+
+	w, h := imageutils.Fit(media.Width, media.Height, blurhashSize)
+	media.Blurhash = imageutils.Blurhash(imageutils.Resize(img, w, h), blurhashXComp, blurhashYComp)
+
+	return p.repo.MarkReady(ctx, media, rawBlobHash, variants)
+}
+
+// decodeImage decodes an upload and, for JPEGs, rotates it upright according
+// to its EXIF orientation since that tag is lost on re-encoding. The
+// dimensions are read from the header first, and images of more than
+// maxPixels pixels are rejected without being decoded.
+func decodeImage(mimeType string, data []byte, maxPixels int) (image.Image, error) {
+	var decodeConfig func(io.Reader) (image.Config, error)
+	switch mimeType {
+	case "image/jpeg":
+		decodeConfig = jpeg.DecodeConfig
+	case "image/png":
+		decodeConfig = png.DecodeConfig
+	case "image/gif":
+		decodeConfig = gif.DecodeConfig
+	default:
+		return nil, errUnsupportedImage
+	}
+	cfg, err := decodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > int64(maxPixels) {
+		return nil, errImageTooLarge
+	}
+
+	switch mimeType {
+	case "image/jpeg":
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return imageutils.ApplyOrientation(img, imageutils.JPEGOrientation(data)), nil
+	case "image/png":
+		return png.Decode(bytes.NewReader(data))
+	case "image/gif":
+		// the first frame is enough for variants and the placeholder
+		return gif.Decode(bytes.NewReader(data))
+	default:
+		return nil, errUnsupportedImage
+	}
+}
+
+func encodeImage(mimeType string, img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	var err error
+	switch mimeType {
+	case "image/jpeg":
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality})
+	case "image/png":
+		err = png.Encode(&buf, img)
+	default:
+		err = errUnsupportedImage
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
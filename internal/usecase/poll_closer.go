@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"TwClone/internal/config"
+	"TwClone/internal/pkg/logger"
+	"TwClone/internal/repository"
+)
+
+const pollCloseBatchSize = 100
+
+// PollCloser closes polls once they end and tells their author and voters
+// the results are in. Polls are claimed with row locks, so several workers
+// can run at once without notifying anyone twice.
+type PollCloser struct {
+	repo     repository.PollRepositoryImpl
+	interval time.Duration
+}
+
+func NewPollCloser(cfg *config.PollConfig) *PollCloser {
+	return &PollCloser{
+		repo:     repository.PollRepositoryImpl{},
+		interval: time.Duration(cfg.CloseIntervalSeconds) * time.Second,
+	}
+}
+
+// Run closes ended polls periodically until ctx is cancelled.
+func (c *PollCloser) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		if err := c.CloseDue(ctx); err != nil && ctx.Err() == nil {
+			logger.Log.Errorf("failed to close polls: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CloseDue closes every poll that has ended.
+func (c *PollCloser) CloseDue(ctx context.Context) error {
+	for {
+		n, err := c.repo.CloseDue(ctx, time.Now(), pollCloseBatchSize)
+		if err != nil {
+			return err
+		}
+		if n < pollCloseBatchSize {
+			return nil
+		}
+	}
+}
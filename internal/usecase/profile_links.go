@@ -0,0 +1,248 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"TwClone/internal/config"
+	"TwClone/internal/entity"
+	"TwClone/internal/pkg/logger"
+	"TwClone/internal/repository"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	profileLinkBatchSize = 50
+	maxProfilePageBytes  = 1 << 20
+)
+
+var errNonPublicAddress = errors.New("refusing to connect to a non-public address")
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, which
+// net.IP does not count as private but is not reachable from the internet.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// ProfileLinkVerifier verifies the custom links on users' profiles. A link
+// is verified when the page it points to links back to the user's profile
+// with rel="me". Links are checked soon after they are added and again
+// periodically, so a link whose page stops linking back loses its
+// verification.
+type ProfileLinkVerifier struct {
+	repo     repository.ProfileLinkRepositoryImpl
+	userRepo repository.UserRepositoryImpl
+	client   *http.Client
+	baseURL  *url.URL
+	interval time.Duration
+	recheck  time.Duration
+}
+
+// NewProfileLinkVerifier creates a verifier that fetches pages with client.
+// When client is nil, pages are fetched with a client that refuses to
+// connect to loopback, private and link-local addresses, so that profile
+// links cannot be used to probe the internal network.
+func NewProfileLinkVerifier(cfg *config.ProfileConfig, client *http.Client) *ProfileLinkVerifier {
+	baseURL, err := url.Parse(cfg.BaseURL)
+	if err != nil {
+		logger.Log.Fatalf("invalid profile base url %q: %v", cfg.BaseURL, err)
+	}
+	if client == nil {
+		client = newPublicHTTPClient(time.Duration(cfg.FetchTimeoutSeconds) * time.Second)
+	}
+	return &ProfileLinkVerifier{
+		repo:     repository.ProfileLinkRepositoryImpl{},
+		userRepo: repository.UserRepositoryImpl{},
+		client:   client,
+		baseURL:  baseURL,
+		interval: time.Duration(cfg.VerifyIntervalMinutes) * time.Minute,
+		recheck:  time.Duration(cfg.RecheckHours) * time.Hour,
+	}
+}
+
+// Run verifies due links periodically until ctx is cancelled.
+func (v *ProfileLinkVerifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(v.interval)
+	defer ticker.Stop()
+	for {
+		if err := v.VerifyDue(ctx); err != nil && ctx.Err() == nil {
+			logger.Log.Errorf("failed to verify profile links: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// VerifyDue checks every link that was never checked or is due for a
+// recheck.
+func (v *ProfileLinkVerifier) VerifyDue(ctx context.Context) error {
+	for {
+		links, err := v.repo.FindDue(ctx, time.Now().Add(-v.recheck), profileLinkBatchSize)
+		if err != nil {
+			return err
+		}
+		usernames, err := v.usernames(ctx, links)
+		if err != nil {
+			return err
+		}
+
+		for _, link := range links {
+			verified := false
+			if username, ok := usernames[link.UserID]; ok {
+				verified, err = v.Verify(ctx, link.URL, username)
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				if err != nil {
+					logger.Log.Debugf("failed to verify profile link %d: %v", link.ID, err)
+				}
+			}
+			if err := v.repo.MarkChecked(ctx, link, verified, time.Now()); err != nil {
+				return err
+			}
+		}
+		if len(links) < profileLinkBatchSize {
+			return nil
+		}
+	}
+}
+
+// Verify reports whether the HTML page at pageURL links back to the profile
+// of the given user with rel="me". Redirects are followed, and relative
+// links are resolved against the page they end on.
+func (v *ProfileLinkVerifier) Verify(ctx context.Context, pageURL, username string) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, nil
+	}
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return false, nil
+	}
+
+	z := html.NewTokenizer(io.LimitReader(resp.Body, maxProfilePageBytes))
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if errors.Is(z.Err(), io.EOF) {
+				return false, nil
+			}
+			return false, z.Err()
+		case html.StartTagToken, html.SelfClosingTagToken:
+			tag := z.Token()
+			if tag.Data != "a" && tag.Data != "link" {
+				continue
+			}
+			rel, href := "", ""
+			for _, attr := range tag.Attr {
+				switch attr.Key {
+				case "rel":
+					rel = attr.Val
+				case "href":
+					href = attr.Val
+				}
+			}
+			if !hasRelMe(rel) {
+				continue
+			}
+			target, err := resp.Request.URL.Parse(strings.TrimSpace(href))
+			if err == nil && v.isProfileURL(target, username) {
+				return true, nil
+			}
+		}
+	}
+}
+
+// isProfileURL reports whether u is the profile of the given user, either
+// as BaseURL/username or BaseURL/@username.
+func (v *ProfileLinkVerifier) isProfileURL(u *url.URL, username string) bool {
+	if !strings.EqualFold(u.Scheme, v.baseURL.Scheme) || !strings.EqualFold(u.Host, v.baseURL.Host) {
+		return false
+	}
+	prefix := strings.TrimRight(v.baseURL.Path, "/") + "/"
+	path, ok := strings.CutPrefix(strings.TrimRight(u.Path, "/"), prefix)
+	if !ok {
+		return false
+	}
+	return strings.EqualFold(strings.TrimPrefix(path, "@"), username)
+}
+
+// usernames returns the usernames of the owners of links by user id.
+func (v *ProfileLinkVerifier) usernames(ctx context.Context, links []*entity.ProfileLink) (map[int64]string, error) {
+	usernames := make(map[int64]string)
+	if len(links) == 0 {
+		return usernames, nil
+	}
+	ids := make([]int64, 0, len(links))
+	for _, l := range links {
+		ids = append(ids, l.UserID)
+	}
+	users, err := v.userRepo.FindByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		usernames[u.ID] = u.Username
+	}
+	return usernames, nil
+}
+
+func hasRelMe(rel string) bool {
+	for _, value := range strings.Fields(rel) {
+		if strings.EqualFold(value, "me") {
+			return true
+		}
+	}
+	return false
+}
+
+// newPublicHTTPClient returns a client that only connects to public
+// addresses. The check happens when connecting, after name resolution, so
+// it also covers redirects and host names that resolve to internal
+// addresses.
+func newPublicHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if !isPublicIP(net.ParseIP(host)) {
+				return errNonPublicAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// isPublicIP reports whether ip is a global unicast address outside the
+// private and shared address ranges.
+func isPublicIP(ip net.IP) bool {
+	return ip != nil && ip.IsGlobalUnicast() && !ip.IsPrivate() && !sharedAddressSpace.Contains(ip)
+}
@@ -0,0 +1,110 @@
+package usecase
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"TwClone/internal/config"
+)
+
+func TestProfileLinkVerifierVerify(t *testing.T) {
+	mux := http.NewServeMux()
+	page := func(contentType, body string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", contentType)
+			w.Write([]byte(body))
+		}
+	}
+	mux.HandleFunc("/match", page("text/html; charset=utf-8",
+		`<html><head><link rel="me" href="https://tw.example/@alice"></head><body></body></html>`))
+	mux.HandleFunc("/anchor", page("text/html",
+		`<p>Find me <a class="x" rel="nofollow me" href="https://tw.example/Alice/">here</a></p>`))
+	mux.HandleFunc("/no-rel-me", page("text/html",
+		`<a href="https://tw.example/alice">alice</a>`))
+	mux.HandleFunc("/other-user", page("text/html",
+		`<a rel="me" href="https://tw.example/bob">bob</a>`))
+	mux.HandleFunc("/other-host", page("text/html",
+		`<a rel="me" href="https://elsewhere.example/alice">alice</a>`))
+	mux.HandleFunc("/plain", page("text/plain",
+		`<a rel="me" href="https://tw.example/alice">alice</a>`))
+	mux.HandleFunc("/json", page("application/json",
+		`{"html": "<a rel='me' href='https://tw.example/alice'>alice</a>"}`))
+	mux.HandleFunc("/missing", http.NotFound)
+	mux.HandleFunc("/redirect", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/match", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := &config.ProfileConfig{BaseURL: "https://tw.example", FetchTimeoutSeconds: 5}
+	v := NewProfileLinkVerifier(cfg, srv.Client())
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"/match", true},
+		{"/anchor", true},
+		{"/redirect", true},
+		{"/no-rel-me", false},
+		{"/other-user", false},
+		{"/other-host", false},
+		{"/plain", false},
+		{"/json", false},
+		{"/missing", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := v.Verify(context.Background(), srv.URL+tt.path, "alice")
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileLinkVerifierRejectsLocalServerByDefault(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request reached the local server")
+	}))
+	defer srv.Close()
+
+	cfg := &config.ProfileConfig{BaseURL: "https://tw.example", FetchTimeoutSeconds: 5}
+	v := NewProfileLinkVerifier(cfg, nil)
+	if _, err := v.Verify(context.Background(), srv.URL, "alice"); err == nil {
+		t.Fatal("Verify() error = nil, want a refused connection")
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"100.127.255.254", false},
+		{"100.128.0.1", true},
+		{"::1", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"::ffff:100.64.0.1", false},
+	}
+	for _, tt := range tests {
+		if got := isPublicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("isPublicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"TwClone/internal/config"
+	"TwClone/internal/entity"
+	"TwClone/internal/pkg/logger"
+	"TwClone/internal/pkg/utils/searchutils"
+	"TwClone/internal/repository"
+)
+
+const (
+	searchAlertBatchSize = 200
+	// searchAlertMaxMatches caps how many new tweets one alert counts, which
+	// also bounds the cost of evaluating a busy search.
+	searchAlertMaxMatches = 100
+)
+
+// SearchAlerter evaluates subscribed saved searches against new tweets and
+// notifies their owners. A search alerts at most once per cooldown; matches
+// found in between are gathered into that single alert, so a busy search
+// produces a digest instead of flooding its owner's notifications.
+type SearchAlerter struct {
+	cfg       *config.SavedSearchConfig
+	repo      repository.SavedSearchRepositoryImpl
+	tweetRepo repository.TweetRepositoryImpl
+}
+
+func NewSearchAlerter(cfg *config.SavedSearchConfig) *SearchAlerter {
+	return &SearchAlerter{
+		cfg:       cfg,
+		repo:      repository.SavedSearchRepositoryImpl{},
+		tweetRepo: repository.TweetRepositoryImpl{},
+	}
+}
+
+// Run evaluates saved searches periodically until ctx is cancelled.
+func (a *SearchAlerter) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(a.cfg.IntervalMinutes) * time.Minute)
+	defer ticker.Stop()
+	for {
+		if err := a.Evaluate(ctx); err != nil && ctx.Err() == nil {
+			logger.Log.Errorf("failed to evaluate saved searches: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Evaluate checks every subscribed search that is out of its cooldown once.
+func (a *SearchAlerter) Evaluate(ctx context.Context) error {
+	alertedBefore := time.Now().Add(-time.Duration(a.cfg.CooldownMinutes) * time.Minute)
+	var afterID int64
+	for {
+		searches, err := a.repo.FindDue(ctx, alertedBefore, afterID, searchAlertBatchSize)
+		if err != nil {
+			return err
+		}
+		for _, s := range searches {
+			if err := a.evaluate(ctx, s); err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				logger.Log.Errorf("failed to evaluate saved search %d: %v", s.ID, err)
+			}
+		}
+		if len(searches) < searchAlertBatchSize {
+			return nil
+		}
+		afterID = searches[len(searches)-1].ID
+	}
+}
+
+func (a *SearchAlerter) evaluate(ctx context.Context, search *entity.SavedSearch) error {
+	q, err := searchutils.Parse(search.Query)
+	if err != nil {
+		// queries are validated when saved; skip any that no longer parse
+		return nil
+	}
+
+	tweets, err := a.tweetRepo.Search(ctx, q, repository.TweetSearchOptions{
+		ViewerID:      search.UserID,
+		AfterID:       search.LastMatchedTweetID,
+		ExcludeViewer: true,
+		Limit:         searchAlertMaxMatches,
+	})
+	if err != nil || len(tweets) == 0 {
+		return err
+	}
+
+	// results are newest first
+	newest := tweets[0].ID
+	searchID := search.ID
+	return a.repo.RecordAlert(ctx, search, &entity.Notification{
+		RecipientID:   search.UserID,
+		Type:          entity.NotificationTypeSearchAlert,
+		TweetID:       &newest,
+		SavedSearchID: &searchID,
+		MatchCount:    len(tweets),
+	})
+}
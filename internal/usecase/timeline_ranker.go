@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"TwClone/internal/config"
+	"TwClone/internal/entity"
+	"TwClone/internal/repository"
+)
+
+const (
+	timelineInNetworkWeight     = 1.0
+	timelineAffinityWeight      = 1.0
+	timelineVelocityWeight      = 0.5
+	timelineSocialProofWeight   = 0.5
+	timelineMediaWeight         = 0.3
+	timelineNotInterestedWeight = 1.0
+
+	// timelineVelocitySmoothingHours is added to the age of a tweet when
+	// computing its engagement velocity, so that a brand-new tweet with one
+	// like does not look like it is taking off.
+	timelineVelocitySmoothingHours = 2.0
+)
+
+// RankedTweet is a tweet of a ranked timeline with how it was scored.
+type RankedTweet struct {
+	Tweet *entity.Tweet
+	Score TimelineScore
+}
+
+// TimelineScore explains the score of a ranked tweet. Each feature is
+// given with the count it is derived from. Score is the sum of the
+// weighted features, never below zero, times Recency.
+type TimelineScore struct {
+	Score float64
+	// InNetwork is 1 for tweets of the viewer and the accounts they follow.
+	InNetwork float64
+	// Affinity grows with the likes and replies between the viewer and
+	// the author.
+	Affinity     float64
+	Interactions int64
+	// Velocity grows with the likes, replies and retweets per hour since
+	// the tweet was posted.
+	Velocity    float64
+	Engagements int64
+	// SocialProof grows with the followed accounts that liked or retweeted
+	// the tweet.
+	SocialProof      float64
+	SocialProofCount int64
+	// Media is 1 for tweets with media attached.
+	Media float64
+	// NotInterested grows with the author's tweets the viewer was not
+	// interested in, and counts against the tweet.
+	NotInterested      float64
+	NotInterestedCount int64
+	// Recency halves every HalfLifeMinutes of the tweet's age.
+	Recency    float64
+	AgeMinutes float64
+}
+
+// TimelineRanker ranks the "For You" home timeline. Candidates are the
+// recent tweets of the viewer's network and the tweets their network liked
+// or retweeted. Each is scored on the viewer's affinity with its author,
+// how fast it is gathering engagement, how many followed accounts engaged
+// with it and whether it has media, penalised for authors the viewer was
+// not interested in, and decayed with age. Muted and blocked accounts never
+// make it in.
+type TimelineRanker struct {
+	cfg  *config.TimelineConfig
+	repo repository.TimelineRepositoryImpl
+}
+
+func NewTimelineRanker(cfg *config.TimelineConfig) *TimelineRanker {
+	return &TimelineRanker{
+		cfg:  cfg,
+		repo: repository.TimelineRepositoryImpl{},
+	}
+}
+
+// Rank scores the candidate tweets of a user's timeline as of now and
+// returns up to limit of them, best first. Scoring every page as of the
+// time the first one was ranked keeps the order stable while paging; a
+// page continues below the tweet afterID scored afterScore when afterID is
+// not zero.
+func (r *TimelineRanker) Rank(ctx context.Context, userID int64, now time.Time, afterScore float64, afterID int64, limit int) ([]*RankedTweet, error) {
+	since := now.Add(-time.Duration(r.cfg.CandidateHours) * time.Hour)
+	candidates, err := r.repo.FindCandidates(ctx, userID, since, now, r.cfg.Candidates)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return []*RankedTweet{}, nil
+	}
+
+	authorIDs := make([]int64, 0, len(candidates))
+	seen := make(map[int64]bool, len(candidates))
+	for _, c := range candidates {
+		if !seen[c.UserID] {
+			seen[c.UserID] = true
+			authorIDs = append(authorIDs, c.UserID)
+		}
+	}
+	signals, err := r.repo.FindAuthorSignals(ctx, userID, authorIDs, now.AddDate(0, 0, -r.cfg.AffinityDays))
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := make([]*RankedTweet, 0, len(candidates))
+	for _, c := range candidates {
+		t := &RankedTweet{Tweet: &c.Tweet, Score: r.score(c, signals[c.UserID], now)}
+		if afterID == 0 || rankedBelow(t, afterScore, afterID) {
+			ranked = append(ranked, t)
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		return rankedBelow(ranked[j], ranked[i].Score.Score, ranked[i].Tweet.ID)
+	})
+	return ranked[:min(limit, len(ranked))], nil
+}
+
+// rankedBelow reports whether a ranked tweet comes after the tweet id
+// scored score: it scored lower, or the same and is older.
+func rankedBelow(t *RankedTweet, score float64, id int64) bool {
+	return t.Score.Score < score || (t.Score.Score == score && t.Tweet.ID < id)
+}
+
+// score weighs a candidate with what is known about its author, which may
+// be nil. Counts go through log1p so that no single feature can dominate.
+func (r *TimelineRanker) score(c *repository.TimelineCandidate, author *repository.AuthorSignals, now time.Time) TimelineScore {
+	age := max(now.Sub(c.CreatedAt), 0)
+	s := TimelineScore{
+		Engagements:      c.LikeCount + c.ReplyCount + c.RetweetCount,
+		SocialProofCount: c.SocialProof,
+		AgeMinutes:       age.Minutes(),
+	}
+	if author != nil {
+		s.Interactions = author.Interactions
+		s.NotInterestedCount = author.NotInterested
+	}
+	if c.InNetwork {
+		s.InNetwork = 1
+	}
+	if c.HasMedia {
+		s.Media = 1
+	}
+	s.Affinity = math.Log1p(float64(s.Interactions))
+	s.Velocity = math.Log1p(float64(s.Engagements) / (age.Hours() + timelineVelocitySmoothingHours))
+	s.SocialProof = math.Log1p(float64(s.SocialProofCount))
+	s.NotInterested = math.Log1p(float64(s.NotInterestedCount))
+	s.Recency = decay(age, time.Duration(r.cfg.HalfLifeMinutes)*time.Minute)
+
+	relevance := timelineInNetworkWeight*s.InNetwork +
+		timelineAffinityWeight*s.Affinity +
+		timelineVelocityWeight*s.Velocity +
+		timelineSocialProofWeight*s.SocialProof +
+		timelineMediaWeight*s.Media -
+		timelineNotInterestedWeight*s.NotInterested
+	s.Score = math.Max(0, relevance) * s.Recency
+	return s
+}
@@ -0,0 +1,154 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"TwClone/internal/config"
+	"TwClone/internal/pkg/logger"
+	"TwClone/internal/repository"
+)
+
+// Trend is a hashtag whose use is rising faster than usual.
+type Trend struct {
+	HashtagID      int64
+	Tag            string
+	Score          float64
+	TweetCount     int64
+	AuthorCount    int64
+	SampleTweetIDs []int64
+}
+
+// TrendService periodically ranks hashtags by how much more they were used
+// in the recent window than their baseline suggests. Each author counts once
+// per hashtag, weighted by how recently they last used it, so a single
+// account posting a tag repeatedly cannot make it trend.
+type TrendService struct {
+	cfg       *config.TrendsConfig
+	repo      repository.HashtagRepositoryImpl
+	tweetRepo repository.TweetRepositoryImpl
+
+	mu         sync.RWMutex
+	trends     []Trend
+	computedAt time.Time
+}
+
+func NewTrendService(cfg *config.TrendsConfig) *TrendService {
+	return &TrendService{
+		cfg:       cfg,
+		repo:      repository.HashtagRepositoryImpl{},
+		tweetRepo: repository.TweetRepositoryImpl{},
+	}
+}
+
+// Trends returns the latest ranking, best first, and when it was computed.
+// The ranking is empty until the first computation has finished.
+func (s *TrendService) Trends() ([]Trend, time.Time) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.trends, s.computedAt
+}
+
+// Run recomputes the trends periodically until ctx is cancelled.
+func (s *TrendService) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(s.cfg.IntervalMinutes) * time.Minute)
+	defer ticker.Stop()
+	for {
+		if err := s.Compute(ctx); err != nil && ctx.Err() == nil {
+			logger.Log.Errorf("failed to compute trends: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Compute ranks hashtags used within the trend window and replaces the
+// current ranking.
+func (s *TrendService) Compute(ctx context.Context) error {
+	now := time.Now()
+	window := time.Duration(s.cfg.WindowHours) * time.Hour
+	baseline := time.Duration(s.cfg.BaselineDays) * 24 * time.Hour
+	halfLife := time.Duration(s.cfg.HalfLifeMinutes) * time.Minute
+	windowStart := now.Add(-window)
+
+	usage, err := s.repo.FindAuthorUsageSince(ctx, windowStart)
+	if err != nil {
+		return err
+	}
+
+	candidates := make(map[int64]*Trend)
+	decayed := make(map[int64]float64)
+	for _, u := range usage {
+		if _, blocked := s.cfg.Blocklist[u.TagName]; blocked {
+			continue
+		}
+		t, ok := candidates[u.HashtagID]
+		if !ok {
+			t = &Trend{HashtagID: u.HashtagID, Tag: u.TagName}
+			candidates[u.HashtagID] = t
+		}
+		t.TweetCount += u.TweetCount
+		t.AuthorCount++
+		decayed[u.HashtagID] += decay(now.Sub(u.LastUsedAt), halfLife)
+	}
+
+	ids := make([]int64, 0, len(candidates))
+	for id, t := range candidates {
+		if t.AuthorCount >= int64(s.cfg.MinAuthors) {
+			ids = append(ids, id)
+		}
+	}
+	baselineAuthors, err := s.repo.CountAuthorsBetween(ctx, ids, windowStart.Add(-baseline), windowStart)
+	if err != nil {
+		return err
+	}
+
+	// Authors spread evenly over a window would be worth this much each
+	// after decay, which turns the baseline into the decayed score a tag
+	// with its usual activity would get.
+	meanDecay := halfLife.Hours() / (math.Ln2 * window.Hours()) * (1 - decay(window, halfLife))
+	trends := make([]Trend, 0, len(ids))
+	for _, id := range ids {
+		expected := float64(baselineAuthors[id]) * window.Hours() / baseline.Hours() * meanDecay
+		score := (decayed[id] - expected) / math.Sqrt(expected+1)
+		if score <= 0 {
+			continue
+		}
+		t := candidates[id]
+		t.Score = score
+		trends = append(trends, *t)
+	}
+	sort.Slice(trends, func(i, j int) bool {
+		if trends[i].Score != trends[j].Score {
+			return trends[i].Score > trends[j].Score
+		}
+		return trends[i].Tag < trends[j].Tag
+	})
+	if len(trends) > s.cfg.Limit {
+		trends = trends[:s.cfg.Limit]
+	}
+
+	for i := range trends {
+		trends[i].SampleTweetIDs, err = s.tweetRepo.FindPublicIDsByHashtag(ctx, trends[i].HashtagID, windowStart, s.cfg.SampleTweets)
+		if err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	s.trends, s.computedAt = trends, now
+	s.mu.Unlock()
+	return nil
+}
+
+// decay is the weight of an event that happened age ago.
+func decay(age, halfLife time.Duration) float64 {
+	return math.Exp2(-age.Hours() / halfLife.Hours())
+}
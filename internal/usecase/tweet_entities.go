@@ -0,0 +1,18 @@
+package usecase
+
+import (
+	"context"
+
+	"TwClone/internal/entity"
+	"TwClone/internal/pkg/utils/textutils"
+	"TwClone/internal/repository"
+)
+
+// LinkTweetEntities links a tweet to the hashtags and users its content
+// mentions, replacing any links from a previous version of the content.
+func LinkTweetEntities(ctx context.Context, s repository.DataStore, tweet *entity.Tweet) error {
+	if err := s.SetTweetHashtags(ctx, tweet.ID, textutils.Hashtags(tweet.Content)); err != nil {
+		return err
+	}
+	return s.SetTweetMentions(ctx, tweet.ID, textutils.Mentions(tweet.Content))
+}